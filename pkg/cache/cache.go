@@ -0,0 +1,18 @@
+// Package cache provides byte-oriented key/value caches with per-entry TTLs,
+// backed by Redis for shared caching or an in-process LRU for single instances.
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrMiss is returned by Get when the key is absent or has expired.
+var ErrMiss = errors.New("cache miss")
+
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
@@ -0,0 +1,445 @@
+// Package booking is a typed Go client for the booking service REST API.
+//
+//	c := booking.New("http://booking:8080", booking.WithActor("service:payment"))
+//	b, err := c.GetBooking(ctx, 42)
+//	if errors.Is(err, booking.ErrNotFound) { ... }
+package booking
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	mrand "math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+)
+
+const (
+	defaultTimeout     = 10 * time.Second
+	defaultMaxAttempts = 3
+	defaultBaseDelay   = 100 * time.Millisecond
+	defaultMaxDelay    = 2 * time.Second
+)
+
+// Client calls the booking service. It is safe for concurrent use.
+type Client struct {
+	baseURL     string
+	httpClient  *http.Client
+	timeout     time.Duration
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	actor       string
+	tenant      string
+	token       string
+	tracer      trace.Tracer
+}
+
+type Option func(*Client)
+
+// WithHTTPClient replaces the underlying HTTP client.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout bounds each call, including retries. Zero disables the timeout
+// and relies on the caller's context.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRetry configures retries on 5xx, 429 and network errors. Delays grow
+// exponentially from baseDelay up to maxDelay with full jitter. Only calls
+// that are safe to repeat retry every such failure: reads, and creates, whose
+// Idempotency-Key is the same on every attempt. Cancellations and updates
+// retry only failures the server never acted on, a refused connection or a
+// 429.
+func WithRetry(maxAttempts int, baseDelay, maxDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = maxAttempts
+		c.baseDelay = baseDelay
+		c.maxDelay = maxDelay
+	}
+}
+
+// WithActor sets the X-Actor header recorded in the booking audit trail.
+func WithActor(actor string) Option {
+	return func(c *Client) {
+		c.actor = actor
+	}
+}
+
+// WithTenant sets the X-Tenant-ID header naming the tenant requests act for,
+// unless their context names one with reqctx.WithTenant. The booking service
+// only trusts it from admins, so it needs WithToken too, except for the
+// default tenant.
+func WithTenant(tenant string) Option {
+	return func(c *Client) {
+		c.tenant = tenant
+	}
+}
+
+// WithToken sends token as the Bearer credential: an admin token, or a tenant
+// token scoping requests to its tenant.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		httpClient:  http.DefaultClient,
+		timeout:     defaultTimeout,
+		maxAttempts: defaultMaxAttempts,
+		baseDelay:   defaultBaseDelay,
+		maxDelay:    defaultMaxDelay,
+		tracer:      otel.Tracer("github.com/ibnuzaman/porta-pay/pkg/client/booking"),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.maxAttempts < 1 {
+		c.maxAttempts = 1
+	}
+
+	return c
+}
+
+func (c *Client) CreateBooking(ctx context.Context, req CreateBookingRequest) (*Booking, error) {
+	key := req.IdempotencyKey
+	if key == "" {
+		key = newIdempotencyKey()
+	}
+
+	var booking Booking
+	headers := http.Header{"Idempotency-Key": []string{key}}
+	if err := c.do(ctx, "CreateBooking", http.MethodPost, "/api/v1/bookings", nil, headers, req, &booking); err != nil {
+		return nil, err
+	}
+
+	return &booking, nil
+}
+
+func (c *Client) GetBooking(ctx context.Context, id int64) (*Booking, error) {
+	var booking Booking
+	if err := c.do(ctx, "GetBooking", http.MethodGet, bookingPath(id), nil, nil, nil, &booking); err != nil {
+		return nil, err
+	}
+
+	return &booking, nil
+}
+
+func (c *Client) UpdateBooking(ctx context.Context, id int64, req UpdateBookingRequest) (*Booking, error) {
+	var booking Booking
+	if err := c.do(ctx, "UpdateBooking", http.MethodPut, bookingPath(id), nil, nil, req, &booking); err != nil {
+		return nil, err
+	}
+
+	return &booking, nil
+}
+
+// CancelBooking cancels the booking; an empty reason means CUSTOMER_REQUEST.
+func (c *Client) CancelBooking(ctx context.Context, id int64, reason CancelReason) (*Cancellation, error) {
+	var cancellation Cancellation
+	body := map[string]CancelReason{"reason": reason}
+	if err := c.do(ctx, "CancelBooking", http.MethodDelete, bookingPath(id), nil, nil, body, &cancellation); err != nil {
+		return nil, err
+	}
+
+	return &cancellation, nil
+}
+
+// CancelPassenger takes one passenger off the booking; cancelling the last
+// one cancels the booking.
+func (c *Client) CancelPassenger(ctx context.Context, bookingID, passengerID int64, reason CancelReason) (*Cancellation, error) {
+	var cancellation Cancellation
+	body := map[string]CancelReason{"reason": reason}
+	path := bookingPath(bookingID) + "/passengers/" + strconv.FormatInt(passengerID, 10)
+	if err := c.do(ctx, "CancelPassenger", http.MethodDelete, path, nil, nil, body, &cancellation); err != nil {
+		return nil, err
+	}
+
+	return &cancellation, nil
+}
+
+func (c *Client) ListBookings(ctx context.Context, opts ListOptions) ([]Booking, error) {
+	query := url.Values{}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		query.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	if opts.UserID != 0 {
+		query.Set("user_id", strconv.FormatInt(opts.UserID, 10))
+	}
+	if opts.RouteID != 0 {
+		query.Set("route_id", strconv.FormatInt(opts.RouteID, 10))
+	}
+	if opts.Status != "" {
+		query.Set("status", string(opts.Status))
+	}
+	if !opts.CreatedFrom.IsZero() {
+		query.Set("created_from", opts.CreatedFrom.Format(time.RFC3339))
+	}
+	if !opts.CreatedTo.IsZero() {
+		query.Set("created_to", opts.CreatedTo.Format(time.RFC3339))
+	}
+
+	var bookings []Booking
+	if err := c.do(ctx, "ListBookings", http.MethodGet, "/api/v1/bookings", query, nil, nil, &bookings); err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+func (c *Client) GetBookingHistory(ctx context.Context, id int64) ([]HistoryEntry, error) {
+	var history []HistoryEntry
+	if err := c.do(ctx, "GetBookingHistory", http.MethodGet, bookingPath(id)+"/history", nil, nil, nil, &history); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func bookingPath(id int64) string {
+	return "/api/v1/bookings/" + strconv.FormatInt(id, 10)
+}
+
+// do sends the request, retrying retryable failures, and decodes the data
+// field of the response envelope into out.
+func (c *Client) do(ctx context.Context, operation, method, path string, query url.Values, headers http.Header, in, out interface{}) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	ctx, span := c.tracer.Start(ctx, "booking."+operation, trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("booking: encode request: %w", err)
+		}
+	}
+
+	endpoint := c.baseURL + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	// The server replays a create by its key, so resending one is as safe as
+	// resending a read
+	idempotent := method == http.MethodGet || method == http.MethodHead || headers.Get("Idempotency-Key") != ""
+
+	var lastErr error
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		retryAfter, err := c.attempt(ctx, method, endpoint, headers, body, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		span.SetAttributes(attribute.Int("booking.attempts", attempt))
+
+		if attempt == c.maxAttempts || !retryable(err, idempotent) {
+			break
+		}
+		if err := sleep(ctx, c.backoff(attempt, retryAfter)); err != nil {
+			lastErr = err
+			break
+		}
+	}
+
+	span.RecordError(lastErr)
+	span.SetStatus(codes.Error, lastErr.Error())
+	return lastErr
+}
+
+// attempt performs one HTTP round trip. It returns the server's Retry-After
+// delay, if any, alongside the error.
+func (c *Client) attempt(ctx context.Context, method, endpoint string, headers http.Header, body []byte, out interface{}) (time.Duration, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return 0, fmt.Errorf("booking: build request: %w", err)
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.actor != "" {
+		req.Header.Set("X-Actor", c.actor)
+	}
+	if tenant := reqctx.Tenant(ctx); tenant != "" && tenant != reqctx.AllTenants {
+		req.Header.Set("X-Tenant-ID", tenant)
+	} else if c.tenant != "" {
+		req.Header.Set("X-Tenant-ID", c.tenant)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, &transportError{err: err}
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		response.APIResponse
+		Data json.RawMessage `json:"data,omitempty"`
+	}
+	decodeErr := json.NewDecoder(resp.Body).Decode(&envelope)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			Code:       http.StatusText(resp.StatusCode),
+			Message:    http.StatusText(resp.StatusCode),
+			RequestID:  resp.Header.Get("X-Request-Id"),
+		}
+		if decodeErr == nil && envelope.Error != nil {
+			apiErr.Code = envelope.Error.Code
+			apiErr.Message = envelope.Error.Message
+		}
+		return retryAfter(resp), apiErr
+	}
+
+	if decodeErr != nil {
+		return 0, fmt.Errorf("booking: decode response: %w", decodeErr)
+	}
+	if out != nil && len(envelope.Data) > 0 {
+		if err := json.Unmarshal(envelope.Data, out); err != nil {
+			return 0, fmt.Errorf("booking: decode data: %w", err)
+		}
+	}
+
+	return 0, nil
+}
+
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return "booking: " + e.err.Error()
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
+
+// retryable reports whether err may go away on another attempt. Unless the
+// call is idempotent, only failures before the server acted on the request
+// qualify: a connection that was never made, or a 429.
+func retryable(err error, idempotent bool) bool {
+	// Deadline or cancellation of the caller's context is final.
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var transportErr *transportError
+	if errors.As(err, &transportErr) {
+		var opErr *net.OpError
+		return idempotent || (errors.As(err, &opErr) && opErr.Op == "dial")
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.StatusCode == http.StatusTooManyRequests {
+			return true
+		}
+		return idempotent && apiErr.StatusCode >= 500
+	}
+
+	return false
+}
+
+func (c *Client) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	ceiling := float64(c.baseDelay) * math.Pow(2, float64(attempt-1))
+	if ceiling > float64(c.maxDelay) {
+		ceiling = float64(c.maxDelay)
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return time.Duration(mrand.Int63n(int64(ceiling)) + 1)
+}
+
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func newIdempotencyKey() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,271 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+)
+
+// newTestClient talks to handler with retries that do not slow the tests.
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	opts = append([]Option{WithRetry(3, time.Millisecond, 5*time.Millisecond)}, opts...)
+	return New(server.URL, opts...)
+}
+
+func TestGetBookingDecodesTheEnvelope(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v1/bookings/42" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("X-Actor"); got != "service:payment" {
+			t.Errorf("X-Actor = %q", got)
+		}
+		response.Success(w, http.StatusOK, map[string]interface{}{
+			"id":          42,
+			"user_id":     7,
+			"route_id":    3,
+			"qty":         2,
+			"status":      "PAID",
+			"price_total": map[string]interface{}{"amount": 5000000, "currency": "IDR"},
+			"passengers":  []map[string]interface{}{{"id": 1, "full_name": "Siti Rahma", "date_of_birth": "1990-04-12"}},
+		})
+	}, WithActor("service:payment"))
+
+	b, err := c.GetBooking(context.Background(), 42)
+	if err != nil {
+		t.Fatalf("GetBooking: %v", err)
+	}
+	if b.ID != 42 || b.Status != StatusPaid || b.PriceTotal != money.MustNew(5000000, money.IDR) ||
+		len(b.Passengers) != 1 || b.Passengers[0].FullName != "Siti Rahma" {
+		t.Errorf("booking = %+v", b)
+	}
+}
+
+func TestTenantAndTokenHeaders(t *testing.T) {
+	var tenants []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer admin-secret" {
+			t.Errorf("Authorization = %q", got)
+		}
+		tenants = append(tenants, r.Header.Get("X-Tenant-ID"))
+		response.Success(w, http.StatusOK, map[string]interface{}{"id": 42})
+	}, WithTenant("acme"), WithToken("admin-secret"))
+
+	for _, ctx := range []context.Context{
+		context.Background(),
+		reqctx.WithTenant(context.Background(), "globex"),
+		reqctx.WithTenant(context.Background(), reqctx.AllTenants),
+	} {
+		if _, err := c.GetBooking(ctx, 42); err != nil {
+			t.Fatalf("GetBooking: %v", err)
+		}
+	}
+	if want := []string{"acme", "globex", "acme"}; strings.Join(tenants, ",") != strings.Join(want, ",") {
+		t.Errorf("X-Tenant-ID = %q, want %q", tenants, want)
+	}
+}
+
+func TestErrorsMapToSentinels(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		code   string
+		want   error
+	}{
+		{"bad request", http.StatusBadRequest, "BAD_REQUEST", ErrBadRequest},
+		{"not found", http.StatusNotFound, "NOT_FOUND", ErrNotFound},
+		{"conflict", http.StatusConflict, "CONFLICT", ErrConflict},
+		{"server", http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", ErrServer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-Id", "req-1")
+				response.Error(w, tt.status, tt.code, "booking not found")
+			}, WithRetry(1, 0, 0))
+
+			_, err := c.GetBooking(context.Background(), 42)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("err = %v, want %v", err, tt.want)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status || apiErr.Code != tt.code ||
+				apiErr.Message != "booking not found" || apiErr.RequestID != "req-1" {
+				t.Errorf("APIError = %+v", apiErr)
+			}
+		})
+	}
+
+	t.Run("body without envelope", func(t *testing.T) {
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "upstream down", http.StatusBadGateway)
+		}, WithRetry(1, 0, 0))
+
+		_, err := c.GetBooking(context.Background(), 42)
+		var apiErr *APIError
+		if !errors.Is(err, ErrServer) || !errors.As(err, &apiErr) || apiErr.Message != "Bad Gateway" {
+			t.Errorf("err = %v, want ErrServer with the status text", err)
+		}
+	})
+}
+
+func TestReadsRetryServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			response.Error(w, http.StatusServiceUnavailable, "UNAVAILABLE", "try later")
+			return
+		}
+		response.Success(w, http.StatusOK, []interface{}{})
+	})
+
+	if _, err := c.ListBookings(context.Background(), ListOptions{}); err != nil {
+		t.Fatalf("ListBookings: %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("calls = %d, want 3", calls.Load())
+	}
+}
+
+func TestCreateRetriesWithTheSameIdempotencyKey(t *testing.T) {
+	var keys []string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if len(keys) == 1 {
+			response.InternalServerError(w, "timeout")
+			return
+		}
+		response.Success(w, http.StatusCreated, map[string]interface{}{"id": 42})
+	})
+
+	b, err := c.CreateBooking(context.Background(), CreateBookingRequest{UserID: 7, RouteID: 3, Qty: 1, PriceTotal: money.MustNew(100, money.IDR)})
+	if err != nil || b.ID != 42 {
+		t.Fatalf("CreateBooking = %+v, %v", b, err)
+	}
+	if len(keys) != 2 || keys[0] == "" || keys[0] != keys[1] {
+		t.Errorf("idempotency keys = %q, want one key sent twice", keys)
+	}
+}
+
+func TestCancelIsNotRetriedOnceTheServerActed(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		response.InternalServerError(w, "refund failed")
+	})
+
+	if _, err := c.CancelBooking(context.Background(), 42, CancelReasonCustomerRequest); !errors.Is(err, ErrServer) {
+		t.Fatalf("err = %v, want ErrServer", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestCancelRetriesTooManyRequests(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			response.Error(w, http.StatusTooManyRequests, "RATE_LIMITED", "slow down")
+			return
+		}
+		response.Success(w, http.StatusOK, map[string]interface{}{"booking_id": 42, "reason": "CUSTOMER_REQUEST"})
+	})
+
+	cancellation, err := c.CancelBooking(context.Background(), 42, CancelReasonCustomerRequest)
+	if err != nil || cancellation.BookingID != 42 {
+		t.Fatalf("CancelBooking = %+v, %v", cancellation, err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestTransportErrorsRetryWhenSafe(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+
+	tests := []struct {
+		name  string
+		err   error
+		call  func(*Client) error
+		calls int32
+	}{
+		{"read after a reset", readErr, func(c *Client) error { _, err := c.GetBooking(context.Background(), 42); return err }, 3},
+		{"cancel after a reset", readErr, func(c *Client) error {
+			_, err := c.CancelBooking(context.Background(), 42, "")
+			return err
+		}, 1},
+		{"cancel that never connected", dialErr, func(c *Client) error {
+			_, err := c.CancelBooking(context.Background(), 42, "")
+			return err
+		}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			c := New("http://booking.test", WithRetry(3, time.Millisecond, 5*time.Millisecond),
+				WithHTTPClient(&http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+					calls.Add(1)
+					return nil, tt.err
+				})}))
+
+			if err := tt.call(c); err == nil {
+				t.Fatal("call succeeded")
+			}
+			if calls.Load() != tt.calls {
+				t.Errorf("calls = %d, want %d", calls.Load(), tt.calls)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := New("http://booking.test", WithRetry(5, 100*time.Millisecond, 300*time.Millisecond))
+
+	if got := c.backoff(1, 2*time.Second); got != 2*time.Second {
+		t.Errorf("backoff with Retry-After = %v, want 2s", got)
+	}
+	for attempt, ceiling := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 300 * time.Millisecond} {
+		for i := 0; i < 100; i++ {
+			if got := c.backoff(attempt, 0); got <= 0 || got > ceiling {
+				t.Fatalf("backoff(%d) = %v, want within (0, %v]", attempt, got, ceiling)
+			}
+		}
+	}
+}
+
+func TestRetriesStopWithTheContext(t *testing.T) {
+	var calls atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		cancel()
+		response.Error(w, http.StatusServiceUnavailable, "UNAVAILABLE", "try later")
+	}, WithRetry(3, time.Second, time.Second))
+
+	if _, err := c.GetBooking(ctx, 42); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("calls = %d, want 1", calls.Load())
+	}
+}
@@ -0,0 +1,40 @@
+package booking
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrBadRequest = errors.New("booking: bad request")
+	ErrNotFound   = errors.New("booking: not found")
+	ErrConflict   = errors.New("booking: conflict")
+	ErrServer     = errors.New("booking: server error")
+)
+
+// APIError is returned for non-2xx responses and carries the decoded error
+// envelope. Use errors.Is with ErrNotFound, ErrConflict, ... to branch on it.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("booking API %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.Code == "BAD_REQUEST"
+	case ErrNotFound:
+		return e.Code == "NOT_FOUND"
+	case ErrConflict:
+		return e.Code == "CONFLICT"
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
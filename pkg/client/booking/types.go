@@ -0,0 +1,131 @@
+package booking
+
+import (
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+// Status is the lifecycle state of a booking.
+type Status string
+
+const (
+	StatusCreated   Status = "CREATED"
+	StatusPaid      Status = "PAID"
+	StatusConfirmed Status = "CONFIRMED"
+	StatusExpired   Status = "EXPIRED"
+	StatusCancelled Status = "CANCELLED"
+	StatusRefunded  Status = "REFUNDED"
+)
+
+// CancelReason explains why a booking was cancelled.
+type CancelReason string
+
+const (
+	CancelReasonCustomerRequest CancelReason = "CUSTOMER_REQUEST"
+	CancelReasonScheduleChange  CancelReason = "SCHEDULE_CHANGE"
+	CancelReasonOperator        CancelReason = "OPERATOR"
+	CancelReasonDuplicate       CancelReason = "DUPLICATE"
+	CancelReasonOther           CancelReason = "OTHER"
+)
+
+// DocumentType identifies the travel document a passenger is checked against.
+type DocumentType string
+
+const (
+	DocumentNationalID       DocumentType = "NATIONAL_ID"
+	DocumentPassport         DocumentType = "PASSPORT"
+	DocumentDrivingLicense   DocumentType = "DRIVING_LICENSE"
+	DocumentBirthCertificate DocumentType = "BIRTH_CERTIFICATE"
+	DocumentOther            DocumentType = "OTHER"
+)
+
+type Passenger struct {
+	ID             int64        `json:"id,omitempty"`
+	FullName       string       `json:"full_name"`
+	DocumentType   DocumentType `json:"document_type"`
+	DocumentNumber string       `json:"document_number"`
+	// DateOfBirth is formatted YYYY-MM-DD.
+	DateOfBirth  string       `json:"date_of_birth"`
+	Seat         string       `json:"seat,omitempty"`
+	Status       string       `json:"status,omitempty"`
+	CancelReason CancelReason `json:"cancel_reason,omitempty"`
+	CancelledAt  *time.Time   `json:"cancelled_at,omitempty"`
+	CheckedInAt  *time.Time   `json:"checked_in_at,omitempty"`
+}
+
+type Booking struct {
+	ID           int64        `json:"id"`
+	UserID       int64        `json:"user_id"`
+	RouteID      int64        `json:"route_id"`
+	Qty          int          `json:"qty"`
+	Status       Status       `json:"status"`
+	PriceTotal   money.Money  `json:"price_total"`
+	DepartureAt  *time.Time   `json:"departure_at,omitempty"`
+	CancelReason CancelReason `json:"cancel_reason,omitempty"`
+	CancelledAt  *time.Time   `json:"cancelled_at,omitempty"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	Passengers   []Passenger  `json:"passengers,omitempty"`
+}
+
+type CreateBookingRequest struct {
+	UserID      int64       `json:"user_id"`
+	RouteID     int64       `json:"route_id"`
+	Qty         int         `json:"qty"`
+	PriceTotal  money.Money `json:"price_total"`
+	DepartureAt *time.Time  `json:"departure_at,omitempty"`
+	// Passengers is optional; when set it must hold Qty entries.
+	Passengers []Passenger `json:"passengers,omitempty"`
+
+	// IdempotencyKey deduplicates retries of this create. A random key is
+	// generated when empty; set it to make retries across process restarts safe.
+	IdempotencyKey string `json:"-"`
+}
+
+type UpdateBookingRequest struct {
+	UserID      int64       `json:"user_id"`
+	RouteID     int64       `json:"route_id"`
+	Qty         int         `json:"qty"`
+	Status      Status      `json:"status,omitempty"`
+	PriceTotal  money.Money `json:"price_total"`
+	DepartureAt *time.Time  `json:"departure_at,omitempty"`
+}
+
+type Cancellation struct {
+	BookingID     int64        `json:"booking_id"`
+	PassengerID   *int64       `json:"passenger_id,omitempty"`
+	Reason        CancelReason `json:"reason"`
+	RefundPercent int          `json:"refund_percent"`
+	RefundAmount  money.Money  `json:"refund_amount"`
+	CancelledAt   time.Time    `json:"cancelled_at"`
+}
+
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+type HistoryEntry struct {
+	ID        int64                  `json:"id"`
+	BookingID int64                  `json:"booking_id"`
+	Action    string                 `json:"action"`
+	OldStatus Status                 `json:"old_status,omitempty"`
+	NewStatus Status                 `json:"new_status"`
+	Changes   map[string]FieldChange `json:"changes"`
+	Actor     string                 `json:"actor"`
+	RequestID string                 `json:"request_id,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// ListOptions pages through and filters bookings. Zero values use the server
+// defaults and apply no filter.
+type ListOptions struct {
+	Limit       int
+	Offset      int
+	UserID      int64
+	RouteID     int64
+	Status      Status
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+}
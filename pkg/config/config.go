@@ -16,6 +16,11 @@ type Config struct {
 	PostgresDSN string `env:"POSTGRES_DSN"`
 	DB          DatabaseConfig
 
+	// Redis; empty RedisAddr means Redis is not configured
+	RedisAddr     string `env:"REDIS_ADDR"`
+	RedisPassword string `env:"REDIS_PASSWORD"`
+	RedisDB       int    `env:"REDIS_DB" envDefault:"0"`
+
 	// Observability
 	OTLPEndpoint string `env:"OTEL_EXPORTER_OTLP_ENDPOINT" envDefault:"localhost:4317"`
 	Env          string `env:"ENV" envDefault:"dev"`
@@ -51,3 +56,11 @@ func Load() (Config, error) {
 	err := env.Parse(&cfg)
 	return cfg, err
 }
+
+// LoadWithPrefix loads the config from variables named with prefix, e.g.
+// NOTIFICATION_HTTP_ADDR, so several services can share one environment.
+func LoadWithPrefix(prefix string) (Config, error) {
+	var cfg Config
+	err := env.ParseWithOptions(&cfg, env.Options{Prefix: prefix})
+	return cfg, err
+}
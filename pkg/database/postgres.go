@@ -1,17 +1,38 @@
 package database
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 func Open(dsn string) *sqlx.DB {
-	db := sqlx.MustConnect("postgres", dsn)
+	db, err := Connect(dsn)
+	if err != nil {
+		panic(err)
+	}
 	db.SetMaxOpenConns(20)
 	db.SetMaxIdleConns(10)
 	db.SetConnMaxLifetime(30 * time.Minute)
 
 	return db
 }
+
+// Connect opens and pings a Postgres database whose connections scope every
+// statement to the tenant of its context, see reqctx.WithTenant.
+func Connect(dsn string) (*sqlx.DB, error) {
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db := sqlx.NewDb(sql.OpenDB(tenantConnector{connector}), "postgres")
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
@@ -0,0 +1,120 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+)
+
+// tenantConnector opens connections that scope every statement to the tenant
+// of its context by setting app.tenant_id, which row-level security policies
+// read. Unlike a setting made by WithinTx this also covers statements outside
+// transactions. A context without a tenant sets it empty, which the policies
+// treat as no tenant at all.
+type tenantConnector struct {
+	driver.Connector
+}
+
+// pqConn lists what lib/pq connections implement and database/sql uses.
+type pqConn interface {
+	driver.Conn
+	driver.ConnBeginTx
+	driver.ConnPrepareContext
+	driver.ExecerContext
+	driver.QueryerContext
+	driver.Pinger
+	driver.SessionResetter
+	driver.Validator
+}
+
+func (c tenantConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pc, ok := conn.(pqConn)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("database: %T cannot be scoped to tenants", conn)
+	}
+	return &tenantConn{pqConn: pc}, nil
+}
+
+// tenantConn remembers the tenant its session is scoped to, so a statement
+// costs an extra round trip only when the tenant changes.
+type tenantConn struct {
+	pqConn
+
+	tenant string
+	scoped bool
+	// inTx is set while a transaction runs; it keeps the scope it began with
+	inTx bool
+}
+
+func (c *tenantConn) scope(ctx context.Context) error {
+	tenant := reqctx.Tenant(ctx)
+	if c.inTx || (c.scoped && c.tenant == tenant) {
+		return nil
+	}
+
+	args := []driver.NamedValue{{Ordinal: 1, Value: tenant}}
+	if _, err := c.pqConn.ExecContext(ctx, `SELECT set_config('app.tenant_id', $1, false)`, args); err != nil {
+		c.scoped = false
+		return fmt.Errorf("failed to scope connection to tenant: %w", err)
+	}
+	c.tenant, c.scoped = tenant, true
+	return nil
+}
+
+func (c *tenantConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if err := c.scope(ctx); err != nil {
+		return nil, err
+	}
+	return c.pqConn.ExecContext(ctx, query, args)
+}
+
+func (c *tenantConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := c.scope(ctx); err != nil {
+		return nil, err
+	}
+	return c.pqConn.QueryContext(ctx, query, args)
+}
+
+func (c *tenantConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := c.scope(ctx); err != nil {
+		return nil, err
+	}
+	return c.pqConn.PrepareContext(ctx, query)
+}
+
+func (c *tenantConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if err := c.scope(ctx); err != nil {
+		return nil, err
+	}
+	tx, err := c.pqConn.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	c.inTx = true
+	return &tenantTx{Tx: tx, conn: c}, nil
+}
+
+// tenantTx lets its tenantConn follow the context again once it ends. The
+// scope is set before BEGIN, so a rollback does not undo it.
+type tenantTx struct {
+	driver.Tx
+	conn *tenantConn
+}
+
+func (tx *tenantTx) Commit() error {
+	tx.conn.inTx = false
+	return tx.Tx.Commit()
+}
+
+func (tx *tenantTx) Rollback() error {
+	tx.conn.inTx = false
+	return tx.Tx.Rollback()
+}
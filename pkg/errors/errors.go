@@ -8,10 +8,93 @@ import (
 var (
 	// Domain errors
 	ErrBookingNotFound  = errors.New("booking not found")
+	ErrBookingArchived  = errors.New("booking is archived and can no longer be changed")
 	ErrInvalidBookingID = errors.New("invalid booking id")
 	ErrInvalidQuantity  = errors.New("quantity must be greater than 0")
 	ErrBookingExpired   = errors.New("booking has expired")
 	ErrBookingConfirmed = errors.New("cannot modify confirmed booking")
+	ErrDepartureInPast  = errors.New("departure time must be in the future")
+	ErrInvalidPrice     = errors.New("price_total must have a supported currency and must not be negative")
+	ErrCurrencyChanged  = errors.New("booking currency cannot be changed")
+
+	// Status errors
+	ErrInvalidStatus           = errors.New("invalid booking status")
+	ErrInvalidStatusTransition = errors.New("status transition not allowed")
+
+	// Idempotency errors
+	ErrIdempotencyKeyExists = errors.New("idempotency key already used")
+
+	// Payment webhook errors
+	ErrInvalidPaymentEvent   = errors.New("invalid payment event")
+	ErrDuplicatePaymentEvent = errors.New("payment event already processed")
+	ErrPaymentEventNotFound  = errors.New("payment event not found")
+
+	// Outbound webhook errors
+	ErrWebhookSubscriptionNotFound = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound     = errors.New("webhook delivery not found")
+	ErrInvalidWebhookSubscription  = errors.New("webhook subscription needs a partner, an http(s) url and known event types")
+
+	// Hold errors
+	ErrHoldNotFound        = errors.New("hold not found or expired")
+	ErrHoldAlreadyExtended = errors.New("hold can only be extended once")
+	ErrSeatUnavailable     = errors.New("seat is already held or booked")
+	ErrInvalidSeats        = errors.New("seats must be unique and match qty")
+
+	// Passenger errors
+	ErrInvalidPassengers         = errors.New("passengers must match qty, each with a name, a known document type and number, and a past date of birth")
+	ErrPassengerCountLocked      = errors.New("qty of a booking with passengers only changes by cancelling passengers")
+	ErrPassengerNotFound         = errors.New("passenger not found")
+	ErrPassengerAlreadyCancelled = errors.New("passenger is already cancelled")
+	ErrPassengerOfPaidBooking    = errors.New("passengers of a paid booking are only cancelled with the whole booking")
+
+	// Manifest errors
+	ErrInvalidManifestFilter = errors.New("manifest needs a route_id and a departure_at")
+	ErrInvalidManifestFormat = errors.New("manifest format must be csv, json or text")
+
+	// Export errors
+	ErrInvalidExportFormat = errors.New("export format must be csv or ndjson")
+
+	// Import errors
+	ErrInvalidImportFormat = errors.New("import format must be csv or ndjson")
+	ErrInvalidImportMode   = errors.New("import mode must be atomic or best_effort")
+	ErrInvalidImport       = errors.New("import file is empty, malformed or lacks a user_id, route_id, price_total or currency column")
+	ErrImportTooLarge      = errors.New("import holds more bookings than IMPORT_MAX_ROWS allows")
+
+	// Ticket errors
+	ErrTicketNotFound = errors.New("ticket not found")
+	ErrTicketRevoked  = errors.New("ticket has been revoked")
+	ErrInvalidCheckIn = errors.New("check-in needs a route_id and a departure_at")
+
+	// Tenant errors
+	ErrTenantNotFound = errors.New("tenant not found")
+	ErrTenantExists   = errors.New("tenant already exists")
+	ErrInvalidTenant  = errors.New("tenant needs an id of 2 to 63 lower-case letters, digits or -, a name, and settings with non-negative hours, refund percents between 0 and 100 and a positive hold_ttl_seconds")
+	ErrForeignRoute   = errors.New("route is operated by another tenant")
+
+	// Promotion errors
+	ErrPromotionNotFound   = errors.New("promotion not found")
+	ErrPromotionCodeExists = errors.New("promotion code already exists")
+	ErrInvalidPromotion    = errors.New("promotion needs a 3 to 32 character code of letters, digits, - or _, a percent between 1 and 100 or a positive fixed amount, a min_qty of at least 1, positive limits, and an end after its start")
+	ErrInvalidPromoCode    = errors.New("promo code is unknown, inactive or does not apply to this booking")
+	ErrPromoCodeExhausted  = errors.New("promo code has reached its usage limit")
+
+	// Reconciliation errors
+	ErrInvalidSettlementFile    = errors.New("settlement file is empty, malformed or lacks a reference, amount or currency column")
+	ErrInvalidDiscrepancyFilter = errors.New("discrepancy filter needs a known kind and a status of open, resolved or all")
+
+	// Report errors
+	ErrInvalidReportQuery = errors.New("report needs from on or before to, a day, week or month interval, and a time zone with a whole-hour offset")
+
+	// Notification errors
+	ErrNotificationNotFound = errors.New("notification not found")
+	ErrPreferenceNotFound   = errors.New("notification preferences not found")
+	ErrInvalidPreference    = errors.New("notification preferences need a supported locale, an email or E.164 phone for each enabled channel, and known muted events")
+	ErrInvalidEvent         = errors.New("booking event needs an id, a type, a booking_id and its owner")
+
+	// Cancellation errors
+	ErrBookingNotCancellable    = errors.New("booking cannot be cancelled in its current status")
+	ErrCancellationWindowClosed = errors.New("cancellation window has closed")
+	ErrInvalidCancelReason      = errors.New("invalid cancellation reason")
 
 	// Infrastructure errors
 	ErrDatabaseConnection = errors.New("database connection failed")
@@ -0,0 +1,145 @@
+// Package ledger keeps double-entry books of money movements.
+//
+// An Entry records one movement as postings to accounts. Amounts are signed:
+// debits are positive and credits negative, so the postings of a balanced
+// entry sum to zero in every currency. Entries are append-only; a mistake is
+// corrected by posting its Reversal.
+package ledger
+
+import (
+	"errors"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+var (
+	ErrInvalidEntry   = errors.New("ledger entry needs a key, a kind and at least two postings to named accounts with non-zero amounts")
+	ErrUnbalanced     = errors.New("ledger entry postings do not sum to zero in every currency")
+	ErrDuplicateEntry = errors.New("ledger entry with this key already posted")
+	ErrUnknownAccount = errors.New("ledger entry posts to an account that is not open")
+)
+
+type AccountType string
+
+const (
+	Asset     AccountType = "ASSET"
+	Liability AccountType = "LIABILITY"
+	Equity    AccountType = "EQUITY"
+	Revenue   AccountType = "REVENUE"
+	Expense   AccountType = "EXPENSE"
+)
+
+// DebitNormal reports whether debits increase accounts of type t.
+func (t AccountType) DebitNormal() bool {
+	return t == Asset || t == Expense
+}
+
+// Account is a named bucket of the books, e.g. "assets:bank".
+type Account struct {
+	Code string      `json:"code"`
+	Type AccountType `json:"type"`
+	Name string      `json:"name"`
+}
+
+// Posting moves Amount into (debit, positive) or out of (credit, negative)
+// an account.
+type Posting struct {
+	Account string      `json:"account"`
+	Amount  money.Money `json:"amount"`
+}
+
+// Debit returns a posting debiting amount to account.
+func Debit(account string, amount money.Money) Posting {
+	return Posting{Account: account, Amount: amount}
+}
+
+// Credit returns a posting crediting amount to account.
+func Credit(account string, amount money.Money) Posting {
+	negated, err := amount.Negate()
+	if err != nil {
+		// Only the smallest int64 has no negation; Validate rejects the zero
+		negated = money.Zero(amount.Currency())
+	}
+	return Posting{Account: account, Amount: negated}
+}
+
+// Entry is one balanced money movement. Key identifies it across retries:
+// posting an entry whose key was posted before fails with ErrDuplicateEntry.
+// BookingID is zero for entries that concern no single booking.
+type Entry struct {
+	ID          int64     `json:"id"`
+	Key         string    `json:"key"`
+	Kind        string    `json:"kind"`
+	BookingID   int64     `json:"booking_id,omitempty"`
+	Description string    `json:"description,omitempty"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	PostedAt    time.Time `json:"posted_at"`
+	Postings    []Posting `json:"postings"`
+}
+
+// Validate checks that the entry is complete and balanced.
+func (e *Entry) Validate() error {
+	if e.Key == "" || e.Kind == "" || len(e.Postings) < 2 {
+		return ErrInvalidEntry
+	}
+
+	sums := map[money.Currency]money.Money{}
+	for _, p := range e.Postings {
+		if p.Account == "" || p.Amount.IsZero() || !p.Amount.Currency().Valid() {
+			return ErrInvalidEntry
+		}
+		sum, ok := sums[p.Amount.Currency()]
+		if !ok {
+			sum = money.Zero(p.Amount.Currency())
+		}
+		sum, err := sum.Add(p.Amount)
+		if err != nil {
+			return ErrUnbalanced
+		}
+		sums[p.Amount.Currency()] = sum
+	}
+	for _, sum := range sums {
+		if !sum.IsZero() {
+			return ErrUnbalanced
+		}
+	}
+
+	return nil
+}
+
+// Reversal returns an entry under key that undoes e, posting every amount
+// back to where it came from.
+func (e *Entry) Reversal(key string, at time.Time) *Entry {
+	reversal := &Entry{
+		Key:         key,
+		Kind:        e.Kind,
+		BookingID:   e.BookingID,
+		Description: "reversal of " + e.Key,
+		OccurredAt:  at,
+		Postings:    make([]Posting, len(e.Postings)),
+	}
+	for i, p := range e.Postings {
+		reversal.Postings[i] = Credit(p.Account, p.Amount)
+	}
+	return reversal
+}
+
+// Balance is what an account holds in one currency. Debits and Credits are
+// totals; Balance is their difference in the account's normal direction, so
+// it is positive for an asset that holds money and a liability that owes it.
+type Balance struct {
+	Account string      `json:"account"`
+	Type    AccountType `json:"type"`
+	Debits  money.Money `json:"debits"`
+	Credits money.Money `json:"credits"`
+	Balance money.Money `json:"balance"`
+}
+
+// Filter narrows entries to those of one booking or posting to one account,
+// and balances to one booking's entries or one account. Zero-valued fields
+// are ignored.
+type Filter struct {
+	BookingID int64
+	Account   string
+}
@@ -0,0 +1,72 @@
+package ledger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+func TestEntryValidate(t *testing.T) {
+	idr := func(n int64) money.Money { return money.MustNew(n, "IDR") }
+	usd := func(n int64) money.Money { return money.MustNew(n, "USD") }
+
+	tests := []struct {
+		name     string
+		postings []Posting
+		want     error
+	}{
+		{"balanced", []Posting{Debit("assets:bank", idr(100)), Credit("revenue:tickets", idr(100))}, nil},
+		{"split", []Posting{Debit("assets:bank", idr(90)), Debit("expenses:fees", idr(10)), Credit("assets:provider", idr(100))}, nil},
+		{"per currency", []Posting{
+			Debit("assets:bank", idr(100)), Credit("revenue:tickets", idr(100)),
+			Debit("assets:bank", usd(5)), Credit("revenue:tickets", usd(5)),
+		}, nil},
+		{"unbalanced", []Posting{Debit("assets:bank", idr(100)), Credit("revenue:tickets", idr(99))}, ErrUnbalanced},
+		{"across currencies", []Posting{Debit("assets:bank", idr(100)), Credit("revenue:tickets", usd(100))}, ErrUnbalanced},
+		{"single posting", []Posting{Debit("assets:bank", idr(0))}, ErrInvalidEntry},
+		{"zero amount", []Posting{Debit("assets:bank", idr(0)), Credit("revenue:tickets", idr(0))}, ErrInvalidEntry},
+		{"no account", []Posting{Debit("", idr(1)), Credit("revenue:tickets", idr(1))}, ErrInvalidEntry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &Entry{Key: "k", Kind: "payment", Postings: tt.postings}
+			if err := entry.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if err := (&Entry{Kind: "payment", Postings: tests[0].postings}).Validate(); !errors.Is(err, ErrInvalidEntry) {
+		t.Errorf("entry without key: got %v", err)
+	}
+}
+
+func TestEntryReversal(t *testing.T) {
+	entry := &Entry{
+		Key:       "payment:1",
+		Kind:      "payment",
+		BookingID: 7,
+		Postings: []Posting{
+			Debit("assets:provider", money.MustNew(100, "IDR")),
+			Credit("revenue:tickets", money.MustNew(100, "IDR")),
+		},
+	}
+	at := time.Date(2030, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	reversal := entry.Reversal("payment:1:reversal", at)
+	if err := reversal.Validate(); err != nil {
+		t.Fatalf("reversal invalid: %v", err)
+	}
+	if reversal.BookingID != 7 || !reversal.OccurredAt.Equal(at) || reversal.Description != "reversal of payment:1" {
+		t.Errorf("reversal: %+v", reversal)
+	}
+	if p := reversal.Postings[0]; p.Account != "assets:provider" || p.Amount.Amount() != -100 {
+		t.Errorf("first posting: %+v", p)
+	}
+	if p := reversal.Postings[1]; p.Account != "revenue:tickets" || p.Amount.Amount() != 100 {
+		t.Errorf("second posting: %+v", p)
+	}
+}
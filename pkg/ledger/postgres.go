@@ -0,0 +1,233 @@
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+const foreignKeyViolation = "23503"
+
+// Store keeps the books in the ledger_accounts, ledger_entries and
+// ledger_postings tables, which refuse changes to posted rows and check at
+// commit that every entry balances. It joins the transaction carried by the
+// context, so entries commit together with the change they record.
+type Store struct {
+	db         *sqlx.DB
+	transactor *database.Transactor
+}
+
+func NewStore(db *sqlx.DB) *Store {
+	return &Store{
+		db:         db,
+		transactor: database.NewTransactor(db),
+	}
+}
+
+// Open creates the accounts that do not exist yet. Existing accounts keep
+// their type and name.
+func (s *Store) Open(ctx context.Context, accounts ...Account) error {
+	query := `
+		INSERT INTO ledger_accounts (code, type, name)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (code) DO NOTHING`
+
+	conn := database.Conn(ctx, s.db)
+	for _, a := range accounts {
+		if _, err := conn.ExecContext(ctx, query, a.Code, a.Type, a.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Post validates and records entry, filling in its ID and PostedAt.
+func (s *Store) Post(ctx context.Context, entry *Entry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+
+	return s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		conn := database.Conn(ctx, s.db)
+
+		query := `
+			INSERT INTO ledger_entries (key, kind, booking_id, description, occurred_at)
+			VALUES ($1, $2, NULLIF($3::bigint, 0), $4, $5)
+			ON CONFLICT (key) DO NOTHING
+			RETURNING id, posted_at`
+		err := conn.QueryRowContext(ctx, query, entry.Key, entry.Kind, entry.BookingID, entry.Description, entry.OccurredAt).
+			Scan(&entry.ID, &entry.PostedAt)
+		// Conflicts are skipped rather than raised, which would abort the
+		// caller's transaction
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrDuplicateEntry
+		}
+		if err != nil {
+			return err
+		}
+
+		query = `
+			INSERT INTO ledger_postings (entry_id, account, amount, currency)
+			VALUES ($1, $2, $3, $4)`
+		for _, p := range entry.Postings {
+			_, err := conn.ExecContext(ctx, query, entry.ID, p.Account, p.Amount.Amount(), string(p.Amount.Currency()))
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+				return ErrUnknownAccount
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Entries lists entries matching filter with their postings, newest first.
+func (s *Store) Entries(ctx context.Context, filter Filter, limit, offset int) ([]*Entry, error) {
+	where, args := entryFilterClause(filter)
+	query := fmt.Sprintf(`
+		SELECT e.id, e.key, e.kind, COALESCE(e.booking_id, 0), e.description, e.occurred_at, e.posted_at
+		FROM ledger_entries e
+		WHERE %s
+		ORDER BY e.id DESC
+		LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	conn := database.Conn(ctx, s.db)
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*Entry{}
+	byID := map[int64]*Entry{}
+	var ids []int64
+	for rows.Next() {
+		e := &Entry{}
+		if err := rows.Scan(&e.ID, &e.Key, &e.Kind, &e.BookingID, &e.Description, &e.OccurredAt, &e.PostedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+		byID[e.ID] = e
+		ids = append(ids, e.ID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return entries, nil
+	}
+
+	query = `
+		SELECT entry_id, account, amount, currency
+		FROM ledger_postings
+		WHERE entry_id = ANY($1)
+		ORDER BY id`
+	postings, err := conn.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer postings.Close()
+
+	for postings.Next() {
+		var entryID, amount int64
+		var account, currency string
+		if err := postings.Scan(&entryID, &account, &amount, &currency); err != nil {
+			return nil, err
+		}
+		m, err := money.New(amount, money.Currency(currency))
+		if err != nil {
+			return nil, err
+		}
+		e := byID[entryID]
+		e.Postings = append(e.Postings, Posting{Account: account, Amount: m})
+	}
+
+	return entries, postings.Err()
+}
+
+// Balances returns what accounts hold per currency, ordered by account: the
+// filtered account, or every account, counting only the entries of the
+// filtered booking, if any.
+func (s *Store) Balances(ctx context.Context, filter Filter) ([]*Balance, error) {
+	where, args := entryFilterClause(Filter{BookingID: filter.BookingID})
+	if filter.Account != "" {
+		args = append(args, filter.Account)
+		where += fmt.Sprintf(" AND p.account = $%d", len(args))
+	}
+	query := fmt.Sprintf(`
+		SELECT p.account, a.type, p.currency,
+			COALESCE(sum(p.amount) FILTER (WHERE p.amount > 0), 0),
+			COALESCE(-sum(p.amount) FILTER (WHERE p.amount < 0), 0)
+		FROM ledger_postings p
+		JOIN ledger_accounts a ON a.code = p.account
+		JOIN ledger_entries e ON e.id = p.entry_id
+		WHERE %s
+		GROUP BY p.account, a.type, p.currency
+		ORDER BY p.account, p.currency`, where)
+
+	rows, err := database.Conn(ctx, s.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []*Balance{}
+	for rows.Next() {
+		b := &Balance{}
+		var currency string
+		var debits, credits int64
+		if err := rows.Scan(&b.Account, &b.Type, &currency, &debits, &credits); err != nil {
+			return nil, err
+		}
+		if b.Debits, err = money.New(debits, money.Currency(currency)); err != nil {
+			return nil, err
+		}
+		if b.Credits, err = money.New(credits, money.Currency(currency)); err != nil {
+			return nil, err
+		}
+		if b.Type.DebitNormal() {
+			b.Balance, err = b.Debits.Sub(b.Credits)
+		} else {
+			b.Balance, err = b.Credits.Sub(b.Debits)
+		}
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, b)
+	}
+
+	return balances, rows.Err()
+}
+
+// entryFilterClause matches entries, aliased e, against filter.
+func entryFilterClause(filter Filter) (string, []interface{}) {
+	var conds []string
+	var args []interface{}
+	add := func(cond string, value interface{}) {
+		args = append(args, value)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.BookingID != 0 {
+		add("e.booking_id = $%d", filter.BookingID)
+	}
+	if filter.Account != "" {
+		add("EXISTS (SELECT 1 FROM ledger_postings f WHERE f.entry_id = e.id AND f.account = $%d)", filter.Account)
+	}
+
+	if len(conds) == 0 {
+		return "TRUE", args
+	}
+	return strings.Join(conds, " AND "), args
+}
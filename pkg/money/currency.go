@@ -0,0 +1,89 @@
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Currency is an ISO 4217 alphabetic currency code.
+type Currency string
+
+const (
+	IDR Currency = "IDR"
+	SGD Currency = "SGD"
+	USD Currency = "USD"
+)
+
+// minorUnits maps supported currencies to their ISO 4217 exponent, i.e. the
+// number of decimal places between the major and the minor unit.
+var minorUnits = map[Currency]int{
+	"AUD": 2,
+	"BHD": 3,
+	"CNY": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"HKD": 2,
+	"IDR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"MYR": 2,
+	"PHP": 2,
+	"SGD": 2,
+	"THB": 2,
+	"USD": 2,
+	"VND": 0,
+}
+
+// ParseCurrency normalises code and checks that it is a supported currency.
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if !c.Valid() {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return c, nil
+}
+
+// Valid reports whether c is a supported ISO 4217 code.
+func (c Currency) Valid() bool {
+	_, ok := minorUnits[c]
+	return ok
+}
+
+// MinorUnits returns the number of decimal places of the currency.
+func (c Currency) MinorUnits() int {
+	return minorUnits[c]
+}
+
+func (c Currency) String() string {
+	return string(c)
+}
+
+// Value implements driver.Valuer.
+func (c Currency) Value() (driver.Value, error) {
+	if !c.Valid() {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCurrency, string(c))
+	}
+	return string(c), nil
+}
+
+// Scan implements sql.Scanner.
+func (c *Currency) Scan(src interface{}) error {
+	var code string
+	switch v := src.(type) {
+	case string:
+		code = v
+	case []byte:
+		code = string(v)
+	default:
+		return fmt.Errorf("money: cannot scan %T into Currency", src)
+	}
+
+	parsed, err := ParseCurrency(code)
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
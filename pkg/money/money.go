@@ -0,0 +1,328 @@
+// Package money provides an immutable monetary amount stored in minor units
+// (e.g. cents) together with its ISO 4217 currency. Arithmetic never mixes
+// currencies and reports overflow instead of wrapping.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("money amount overflow")
+	ErrInvalidAmount    = errors.New("invalid money amount")
+)
+
+// RoundingMode selects how fractional minor units are resolved.
+type RoundingMode int
+
+const (
+	// RoundHalfUp rounds halves away from zero.
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven rounds halves to the nearest even number (banker's rounding).
+	RoundHalfEven
+	// RoundDown truncates toward zero.
+	RoundDown
+	// RoundUp rounds away from zero.
+	RoundUp
+)
+
+// Money is an amount in minor units of a currency.
+type Money struct {
+	amount   int64
+	currency Currency
+}
+
+// New returns amount minor units of currency.
+func New(amount int64, currency Currency) (Money, error) {
+	if !currency.Valid() {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, string(currency))
+	}
+	return Money{amount: amount, currency: currency}, nil
+}
+
+// MustNew is like New but panics on an unknown currency. Use it for constants.
+func MustNew(amount int64, currency Currency) Money {
+	m, err := New(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Zero returns a zero amount of currency.
+func Zero(currency Currency) Money {
+	return Money{currency: currency}
+}
+
+// Parse reads a decimal major-unit string such as "1250.50" into currency.
+// More decimal places than the currency allows are rejected, not rounded.
+func Parse(s string, currency Currency) (Money, error) {
+	if !currency.Valid() {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, string(currency))
+	}
+
+	input := strings.TrimSpace(s)
+	s, negative := strings.CutPrefix(input, "-")
+	if !negative {
+		s = strings.TrimPrefix(s, "+")
+	}
+
+	// ParseInt would accept a second sign, so only digits may follow the first
+	whole, frac, _ := strings.Cut(s, ".")
+	digits := currency.MinorUnits()
+	if !isDigits(whole) || (frac != "" && !isDigits(frac)) || len(frac) > digits {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, input)
+	}
+	frac += strings.Repeat("0", digits-len(frac))
+
+	amount, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return Money{}, ErrOverflow
+		}
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, input)
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return Money{amount: amount, currency: currency}, nil
+}
+
+// Amount returns the value in minor units.
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.amount < 0
+}
+
+// SameCurrency reports whether m and other share a currency.
+func (m Money) SameCurrency(other Money) bool {
+	return m.currency == other.currency
+}
+
+// Equal reports whether both amount and currency match.
+func (m Money) Equal(other Money) bool {
+	return m == other
+}
+
+// Cmp compares m with other: -1 if less, 0 if equal, +1 if greater.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.assertSameCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.amount < other.amount:
+		return -1, nil
+	case m.amount > other.amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.assertSameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	sum := m.amount + other.amount
+	if (other.amount > 0 && sum < m.amount) || (other.amount < 0 && sum > m.amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: sum, currency: m.currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if other.amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(Money{amount: -other.amount, currency: other.currency})
+}
+
+// Negate returns -m.
+func (m Money) Negate() (Money, error) {
+	if m.amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: -m.amount, currency: m.currency}, nil
+}
+
+// Multiply returns m * n.
+func (m Money) Multiply(n int64) (Money, error) {
+	return m.MulRatio(n, 1, RoundDown)
+}
+
+// MulRatio returns m * num / den rounded with mode.
+func (m Money) MulRatio(num, den int64, mode RoundingMode) (Money, error) {
+	if den == 0 {
+		return Money{}, fmt.Errorf("%w: division by zero", ErrInvalidAmount)
+	}
+
+	product := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(num))
+	result := divRound(product, big.NewInt(den), mode)
+	if !result.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+
+	return Money{amount: result.Int64(), currency: m.currency}, nil
+}
+
+// Percent returns percent% of m rounded with mode.
+func (m Money) Percent(percent int, mode RoundingMode) (Money, error) {
+	return m.MulRatio(int64(percent), 100, mode)
+}
+
+// Allocate splits m proportionally to ratios without losing minor units; the
+// remainder is handed out one unit at a time starting with the first share.
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, fmt.Errorf("%w: no ratios", ErrInvalidAmount)
+	}
+
+	var total int64
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, fmt.Errorf("%w: negative ratio", ErrInvalidAmount)
+		}
+		total += int64(ratio)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("%w: ratios sum to zero", ErrInvalidAmount)
+	}
+
+	shares := make([]Money, len(ratios))
+	remainder := m.amount
+	for i, ratio := range ratios {
+		share, err := m.MulRatio(int64(ratio), total, RoundDown)
+		if err != nil {
+			return nil, err
+		}
+		shares[i] = share
+		remainder -= share.amount
+	}
+
+	unit := int64(1)
+	if remainder < 0 {
+		unit = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(shares) {
+		shares[i].amount += unit
+		remainder -= unit
+	}
+
+	return shares, nil
+}
+
+// String formats m in major units, e.g. "IDR 1250.50".
+func (m Money) String() string {
+	digits := m.currency.MinorUnits()
+	sign := ""
+	abs := new(big.Int).Abs(big.NewInt(m.amount)).String()
+	if m.amount < 0 {
+		sign = "-"
+	}
+	if digits == 0 {
+		return fmt.Sprintf("%s %s%s", m.currency, sign, abs)
+	}
+
+	if len(abs) <= digits {
+		abs = strings.Repeat("0", digits-len(abs)+1) + abs
+	}
+	return fmt.Sprintf("%s %s%s.%s", m.currency, sign, abs[:len(abs)-digits], abs[len(abs)-digits:])
+}
+
+func (m Money) assertSameCurrency(other Money) error {
+	if m.currency != other.currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	return nil
+}
+
+type moneyJSON struct {
+	Amount   int64    `json:"amount"`
+	Currency Currency `json:"currency"`
+}
+
+// MarshalJSON encodes m as {"amount": <minor units>, "currency": "<ISO code>"}.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.amount, Currency: m.currency})
+}
+
+// UnmarshalJSON decodes the form produced by MarshalJSON and validates the currency.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var v moneyJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	parsed, err := New(v.Amount, v.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// divRound divides n by d and resolves the remainder according to mode.
+func divRound(n, d *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	// Direction away from zero for the exact quotient.
+	away := int64(1)
+	if (n.Sign() < 0) != (d.Sign() < 0) {
+		away = -1
+	}
+
+	twiceRem := new(big.Int).Abs(r)
+	twiceRem.Lsh(twiceRem, 1)
+	cmpHalf := twiceRem.Cmp(new(big.Int).Abs(d))
+
+	roundAway := false
+	switch mode {
+	case RoundUp:
+		roundAway = true
+	case RoundDown:
+		roundAway = false
+	case RoundHalfUp:
+		roundAway = cmpHalf >= 0
+	case RoundHalfEven:
+		roundAway = cmpHalf > 0 || (cmpHalf == 0 && q.Bit(0) == 1)
+	}
+
+	if roundAway {
+		q.Add(q, big.NewInt(away))
+	}
+	return q
+}
@@ -0,0 +1,212 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		currency Currency
+		want     int64
+		err      error
+	}{
+		{"1250.50", IDR, 125050, nil},
+		{"1250.5", IDR, 125050, nil},
+		{"1250", IDR, 125000, nil},
+		{" 7.25 ", USD, 725, nil},
+		{"+5", USD, 500, nil},
+		{"-5.01", USD, -501, nil},
+		{"0.000", "BHD", 0, nil},
+		{"1500", "JPY", 1500, nil},
+		{"1500.5", "JPY", 0, ErrInvalidAmount},
+		{"1.234", USD, 0, ErrInvalidAmount},
+		{"+-5", USD, 0, ErrInvalidAmount},
+		{"-+5", USD, 0, ErrInvalidAmount},
+		{"--5", USD, 0, ErrInvalidAmount},
+		{"++5", USD, 0, ErrInvalidAmount},
+		{"-", USD, 0, ErrInvalidAmount},
+		{"", USD, 0, ErrInvalidAmount},
+		{".50", USD, 0, ErrInvalidAmount},
+		{"5.", USD, 500, nil},
+		{"5.-1", USD, 0, ErrInvalidAmount},
+		{"1,000", USD, 0, ErrInvalidAmount},
+		{"1e3", USD, 0, ErrInvalidAmount},
+		{"92233720368547758.08", USD, 0, ErrOverflow},
+		{"5", "XXX", 0, ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Parse(tt.input, tt.currency)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if err == nil && (got.Amount() != tt.want || got.Currency() != tt.currency) {
+				t.Errorf("got %s, want %d minor units of %s", got, tt.want, tt.currency)
+			}
+		})
+	}
+}
+
+func TestAddSub(t *testing.T) {
+	usd := func(n int64) Money { return MustNew(n, USD) }
+
+	tests := []struct {
+		name string
+		op   func() (Money, error)
+		want Money
+		err  error
+	}{
+		{"add", func() (Money, error) { return usd(150).Add(usd(-50)) }, usd(100), nil},
+		{"sub", func() (Money, error) { return usd(150).Sub(usd(200)) }, usd(-50), nil},
+		{"add overflow", func() (Money, error) { return usd(math.MaxInt64).Add(usd(1)) }, Money{}, ErrOverflow},
+		{"add underflow", func() (Money, error) { return usd(math.MinInt64).Add(usd(-1)) }, Money{}, ErrOverflow},
+		{"sub overflow", func() (Money, error) { return usd(math.MaxInt64).Sub(usd(-1)) }, Money{}, ErrOverflow},
+		{"sub min", func() (Money, error) { return usd(0).Sub(usd(math.MinInt64)) }, Money{}, ErrOverflow},
+		{"add mismatch", func() (Money, error) { return usd(1).Add(MustNew(1, IDR)) }, Money{}, ErrCurrencyMismatch},
+		{"sub mismatch", func() (Money, error) { return usd(1).Sub(MustNew(1, IDR)) }, Money{}, ErrCurrencyMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.op()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMulRatio(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		num, den int64
+		mode     RoundingMode
+		want     int64
+		err      error
+	}{
+		{"exact", 100, 3, 4, RoundHalfUp, 75, nil},
+		{"half up", 5, 1, 2, RoundHalfUp, 3, nil},
+		{"half up negative", -5, 1, 2, RoundHalfUp, -3, nil},
+		{"half even down", 5, 1, 2, RoundHalfEven, 2, nil},
+		{"half even up", 15, 1, 2, RoundHalfEven, 8, nil},
+		{"half even negative", -5, 1, 2, RoundHalfEven, -2, nil},
+		{"below half", 10, 1, 3, RoundHalfUp, 3, nil},
+		{"down", 99999, 75, 100, RoundDown, 74999, nil},
+		{"down negative", -7, 1, 2, RoundDown, -3, nil},
+		{"up", 10, 1, 3, RoundUp, 4, nil},
+		{"up negative", -10, 1, 3, RoundUp, -4, nil},
+		{"large intermediate", math.MaxInt64, 2, 2, RoundDown, math.MaxInt64, nil},
+		{"overflow", math.MaxInt64, 3, 2, RoundDown, 0, ErrOverflow},
+		{"zero denominator", 100, 1, 0, RoundDown, 0, ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MustNew(tt.amount, USD).MulRatio(tt.num, tt.den, tt.mode)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if err == nil && got.Amount() != tt.want {
+				t.Errorf("got %d, want %d", got.Amount(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		ratios []int
+		want   []int64
+		err    error
+	}{
+		{"even", 90, []int{1, 1, 1}, []int64{30, 30, 30}, nil},
+		{"remainder to first", 100, []int{1, 1, 1}, []int64{34, 33, 33}, nil},
+		{"two remainders", 101, []int{1, 1, 1}, []int64{34, 34, 33}, nil},
+		{"weighted", 100, []int{70, 20, 10}, []int64{70, 20, 10}, nil},
+		{"weighted remainder", 5, []int{3, 7}, []int64{2, 3}, nil},
+		{"zero ratio", 10, []int{1, 0, 1}, []int64{5, 0, 5}, nil},
+		{"negative", -100, []int{1, 1, 1}, []int64{-34, -33, -33}, nil},
+		{"zero amount", 0, []int{1, 2}, []int64{0, 0}, nil},
+		{"no ratios", 100, nil, nil, ErrInvalidAmount},
+		{"negative ratio", 100, []int{2, -1}, nil, ErrInvalidAmount},
+		{"all zero", 100, []int{0, 0}, nil, ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := MustNew(tt.amount, IDR).Allocate(tt.ratios...)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v, want %v", err, tt.err)
+			}
+			if len(shares) != len(tt.want) {
+				t.Fatalf("got %d shares, want %d", len(shares), len(tt.want))
+			}
+			var sum int64
+			for i, share := range shares {
+				if share.Amount() != tt.want[i] || share.Currency() != IDR {
+					t.Errorf("share %d: got %s, want %d", i, share, tt.want[i])
+				}
+				sum += share.Amount()
+			}
+			if err == nil && sum != tt.amount {
+				t.Errorf("shares sum to %d, want %d", sum, tt.amount)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{MustNew(125050, IDR), "IDR 1250.50"},
+		{MustNew(5, USD), "USD 0.05"},
+		{MustNew(-5, USD), "USD -0.05"},
+		{MustNew(1500, "JPY"), "JPY 1500"},
+		{MustNew(1, "BHD"), "BHD 0.001"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("got %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	want := MustNew(-125050, IDR)
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(data) != `{"amount":-125050,"currency":"IDR"}` {
+		t.Errorf("marshal: got %s", data)
+	}
+
+	var got Money
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got != want {
+		t.Errorf("round trip: got %s, want %s", got, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount":1,"currency":"XXX"}`), &got); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("unknown currency: got %v, want ErrUnknownCurrency", err)
+	}
+	if err := json.Unmarshal([]byte(`{"amount":"1","currency":"IDR"}`), &got); err == nil {
+		t.Error("string amount: want error")
+	}
+}
@@ -0,0 +1,97 @@
+// Package reqctx carries request-scoped metadata (who is acting, which request)
+// from the delivery layer down to use cases without depending on HTTP types.
+package reqctx
+
+import (
+	"context"
+	"strings"
+)
+
+// AnonymousActor is reported when no actor was attached to the context.
+const AnonymousActor = "anonymous"
+
+type actorKey struct{}
+type requestIDKey struct{}
+
+// WithActor returns a copy of ctx identifying actor as the caller, e.g.
+// "user:42" or "system:archiver".
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// Actor returns the caller stored in ctx, or AnonymousActor.
+func Actor(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return AnonymousActor
+}
+
+// credentialRoles are the actor roles only a verified credential confers.
+var credentialRoles = []string{"admin:", "gate:", "payment:", "internal:"}
+
+// ClaimedActor returns an actor a caller named without proving it, as in a
+// header, or an empty string when it claims a role of credentialed callers.
+func ClaimedActor(actor string) string {
+	if Credentialed(actor) {
+		return ""
+	}
+	return actor
+}
+
+// Credentialed reports whether actor has a role only a verified credential
+// confers. As ClaimedActor keeps callers from claiming one, such an actor in
+// a context was set by whoever verified the credential.
+func Credentialed(actor string) bool {
+	for _, role := range credentialRoles {
+		if strings.HasPrefix(actor, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID stored in ctx, or an empty string.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+type idempotencyKeyKey struct{}
+
+// WithIdempotencyKey returns a copy of ctx carrying the client-supplied
+// idempotency key for the current request.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+// IdempotencyKey returns the idempotency key stored in ctx, or an empty string.
+func IdempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	return key
+}
+
+type tenantKey struct{}
+
+// AllTenants is the tenant of platform callers acting across tenants, such as
+// background jobs. They must ask for it: a context without a tenant sees no
+// tenant's data.
+const AllTenants = "*"
+
+// WithTenant returns a copy of ctx scoped to the tenant, the operator whose
+// data the request may see.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// Tenant returns the tenant stored in ctx, AllTenants, or an empty string when
+// ctx was never scoped.
+func Tenant(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
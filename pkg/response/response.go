@@ -52,11 +52,26 @@ func BadRequest(w http.ResponseWriter, message string) {
 	Error(w, http.StatusBadRequest, "BAD_REQUEST", message)
 }
 
+// Unauthorized writes an unauthorized error response
+func Unauthorized(w http.ResponseWriter, message string) {
+	Error(w, http.StatusUnauthorized, "UNAUTHORIZED", message)
+}
+
+// Forbidden writes a forbidden error response
+func Forbidden(w http.ResponseWriter, message string) {
+	Error(w, http.StatusForbidden, "FORBIDDEN", message)
+}
+
 // NotFound writes a not found error response
 func NotFound(w http.ResponseWriter, message string) {
 	Error(w, http.StatusNotFound, "NOT_FOUND", message)
 }
 
+// Conflict writes a conflict error response
+func Conflict(w http.ResponseWriter, message string) {
+	Error(w, http.StatusConflict, "CONFLICT", message)
+}
+
 // InternalServerError writes an internal server error response
 func InternalServerError(w http.ResponseWriter, message string) {
 	Error(w, http.StatusInternalServerError, "INTERNAL_SERVER_ERROR", message)
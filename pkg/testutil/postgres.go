@@ -0,0 +1,347 @@
+// Package testutil provides helpers for integration tests that need real
+// infrastructure. The Postgres harness starts a throwaway local server from the
+// installed initdb/postgres binaries, so no Docker or network is required.
+package testutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+)
+
+var (
+	// ErrPostgresNotFound is returned when the initdb/postgres binaries cannot be located.
+	ErrPostgresNotFound = errors.New("postgres binaries not found (set POSTGRES_BIN_DIR)")
+	// ErrRunningAsRoot is returned when the tests run as root, which Postgres
+	// refuses, and there is no unprivileged user to run it as instead.
+	ErrRunningAsRoot = errors.New("postgres refuses to run as root")
+)
+
+// PostgresServer is an ephemeral Postgres cluster living in a temporary directory.
+type PostgresServer struct {
+	dir  string
+	port int
+	cmd  *exec.Cmd
+	done chan error
+}
+
+// StartPostgres initialises a new cluster in a temp dir and starts it on a free
+// loopback port. Postgres refuses to run as root, so as root the server runs as
+// POSTGRES_RUN_AS, or nobody.
+func StartPostgres(ctx context.Context) (*PostgresServer, error) {
+	binDir, err := findPostgresBinDir()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "porta-pay-pg-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	dataDir := filepath.Join(dir, "data")
+
+	cred, err := unprivilegedCredential(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	initdb := exec.CommandContext(ctx, filepath.Join(binDir, "initdb"),
+		"-D", dataDir,
+		"-U", "postgres",
+		"--auth=trust",
+		"--encoding=UTF8",
+		"--no-locale",
+	)
+	runAs(initdb, cred)
+	if out, err := initdb.CombinedOutput(); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("initdb failed: %w: %s", err, out)
+	}
+
+	port, err := freePort()
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	// Durability is irrelevant for throwaway data; turning it off keeps tests fast.
+	cmd := exec.Command(filepath.Join(binDir, "postgres"),
+		"-D", dataDir,
+		"-p", fmt.Sprint(port),
+		"-k", dir,
+		"-h", "127.0.0.1",
+		"-F",
+		"-c", "full_page_writes=off",
+		"-c", "synchronous_commit=off",
+	)
+	runAs(cmd, cred)
+	logFile, err := os.Create(filepath.Join(dir, "postgres.log"))
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to create log file: %w", err)
+	}
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
+		logFile.Close()
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to start postgres: %w", err)
+	}
+
+	s := &PostgresServer{dir: dir, port: port, cmd: cmd, done: make(chan error, 1)}
+	go func() {
+		s.done <- cmd.Wait()
+		logFile.Close()
+	}()
+
+	if err := s.waitReady(ctx); err != nil {
+		s.Stop()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// DSN returns the connection string for the default database.
+func (s *PostgresServer) DSN() string {
+	return fmt.Sprintf("postgres://postgres@127.0.0.1:%d/postgres?sslmode=disable", s.port)
+}
+
+// Stop shuts the server down and removes its data directory.
+func (s *PostgresServer) Stop() error {
+	if s.cmd.Process != nil {
+		// SIGINT requests a "fast" shutdown: abort open sessions and exit.
+		_ = s.cmd.Process.Signal(os.Interrupt)
+		select {
+		case <-s.done:
+		case <-time.After(10 * time.Second):
+			_ = s.cmd.Process.Kill()
+			<-s.done
+		}
+	}
+
+	return os.RemoveAll(s.dir)
+}
+
+func (s *PostgresServer) waitReady(ctx context.Context) error {
+	deadline := time.Now().Add(30 * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-s.done:
+			log, _ := os.ReadFile(filepath.Join(s.dir, "postgres.log"))
+			return fmt.Errorf("postgres exited early: %v: %s", err, log)
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		db, err := sqlx.Open("postgres", s.DSN())
+		if err == nil {
+			err = db.PingContext(ctx)
+			db.Close()
+			if err == nil {
+				return nil
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	return errors.New("timed out waiting for postgres to accept connections")
+}
+
+var (
+	sharedOnce   sync.Once
+	sharedServer *PostgresServer
+	sharedErr    error
+)
+
+// RunWithPostgres runs the package tests and stops the shared server started by
+// NewPostgresSchema afterwards. Call it from TestMain:
+//
+//	func TestMain(m *testing.M) { os.Exit(testutil.RunWithPostgres(m)) }
+func RunWithPostgres(m *testing.M) int {
+	code := m.Run()
+	if sharedServer != nil {
+		sharedServer.Stop()
+	}
+	return code
+}
+
+// NewPostgresSchema returns a connection scoped to a fresh schema on the shared
+// server with all *.up.sql files from migrations applied. The schema is dropped
+// when the test finishes. The test is skipped if Postgres is not installed or
+// cannot be run, unless POSTGRES_REQUIRED is set, in which case it fails.
+func NewPostgresSchema(t testing.TB, migrations fs.FS) *sqlx.DB {
+	t.Helper()
+
+	sharedOnce.Do(func() {
+		sharedServer, sharedErr = StartPostgres(context.Background())
+	})
+	if (errors.Is(sharedErr, ErrPostgresNotFound) || errors.Is(sharedErr, ErrRunningAsRoot)) && os.Getenv("POSTGRES_REQUIRED") == "" {
+		t.Skip(sharedErr.Error())
+	}
+	if sharedErr != nil {
+		t.Fatalf("failed to start postgres: %v", sharedErr)
+	}
+
+	admin, err := sqlx.Connect("postgres", sharedServer.DSN())
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+
+	schema := "test_" + randomSuffix()
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		admin.Close()
+		t.Fatalf("failed to create schema: %v", err)
+	}
+
+	// lib/pq forwards unknown DSN parameters as run-time settings, so every
+	// pooled connection starts with the test schema on its search_path. Like
+	// the services' connections, they follow the tenant of each context.
+	db, err := database.Connect(sharedServer.DSN() + "&search_path=" + schema)
+	if err != nil {
+		admin.Close()
+		t.Fatalf("failed to connect to schema %s: %v", schema, err)
+	}
+
+	t.Cleanup(func() {
+		db.Close()
+		admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		admin.Close()
+	})
+
+	if migrations != nil {
+		if err := ApplyMigrations(context.Background(), db, migrations); err != nil {
+			t.Fatalf("failed to apply migrations: %v", err)
+		}
+	}
+
+	return db
+}
+
+// NewPostgresRole returns a connection to the schema of db as a new role that
+// may read and write its tables. Unlike the superuser of NewPostgresSchema it
+// is subject to row-level security. The role is dropped when the test
+// finishes.
+func NewPostgresRole(t testing.TB, db *sqlx.DB) *sqlx.DB {
+	t.Helper()
+
+	var schema string
+	if err := db.Get(&schema, `SELECT current_schema()`); err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+
+	role := "app_" + randomSuffix()
+	for _, stmt := range []string{
+		`CREATE ROLE ` + role + ` LOGIN`,
+		`GRANT USAGE ON SCHEMA ` + schema + ` TO ` + role,
+		`GRANT SELECT, INSERT, UPDATE, DELETE ON ALL TABLES IN SCHEMA ` + schema + ` TO ` + role,
+		`GRANT USAGE ON ALL SEQUENCES IN SCHEMA ` + schema + ` TO ` + role,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("failed to create role: %v", err)
+		}
+	}
+
+	conn, err := database.Connect(fmt.Sprintf("postgres://%s@127.0.0.1:%d/postgres?sslmode=disable&search_path=%s", role, sharedServer.port, schema))
+	if err != nil {
+		t.Fatalf("failed to connect as %s: %v", role, err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		db.Exec(`DROP OWNED BY ` + role)
+		db.Exec(`DROP ROLE ` + role)
+	})
+
+	return conn
+}
+
+// ApplyMigrations executes every *.up.sql file in fsys in lexical order, which
+// matches golang-migrate's sequential numbering.
+func ApplyMigrations(ctx context.Context, db *sqlx.DB, fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, name := range files {
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		if _, err := db.ExecContext(ctx, string(content)); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func findPostgresBinDir() (string, error) {
+	candidates := []string{os.Getenv("POSTGRES_BIN_DIR")}
+	if path, err := exec.LookPath("initdb"); err == nil {
+		candidates = append(candidates, filepath.Dir(path))
+	}
+	for _, pattern := range []string{
+		"/usr/lib/postgresql/*/bin",
+		"/usr/local/pgsql/bin",
+		"/opt/homebrew/opt/postgresql*/bin",
+		"/usr/local/opt/postgresql*/bin",
+	} {
+		matches, _ := filepath.Glob(pattern)
+		// Prefer the newest installed major version.
+		sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+		candidates = append(candidates, matches...)
+	}
+
+	for _, dir := range candidates {
+		if strings.TrimSpace(dir) == "" {
+			continue
+		}
+		if isExecutable(filepath.Join(dir, "initdb")) && isExecutable(filepath.Join(dir, "postgres")) {
+			return dir, nil
+		}
+	}
+
+	return "", ErrPostgresNotFound
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir() && info.Mode()&0o111 != 0
+}
+
+func freePort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find free port: %w", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+func randomSuffix() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
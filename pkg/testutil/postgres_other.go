@@ -0,0 +1,14 @@
+//go:build !unix
+
+package testutil
+
+import "os/exec"
+
+type credential struct{}
+
+// unprivilegedCredential has nothing to drop where there is no root.
+func unprivilegedCredential(dir string) (*credential, error) {
+	return nil, nil
+}
+
+func runAs(cmd *exec.Cmd, cred *credential) {}
@@ -0,0 +1,51 @@
+//go:build unix
+
+package testutil
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"syscall"
+)
+
+type credential = syscall.Credential
+
+// unprivilegedCredential returns who initdb and postgres run as when the
+// tests run as root, which Postgres refuses, and hands dir over to them. That
+// is POSTGRES_RUN_AS, or nobody. Other users run the server themselves.
+func unprivilegedCredential(dir string) (*credential, error) {
+	if os.Geteuid() != 0 {
+		return nil, nil
+	}
+
+	name := os.Getenv("POSTGRES_RUN_AS")
+	if name == "" {
+		name = "nobody"
+	}
+	u, err := user.Lookup(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrRunningAsRoot, err)
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: uid of %s: %v", ErrRunningAsRoot, name, err)
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("%w: gid of %s: %v", ErrRunningAsRoot, name, err)
+	}
+
+	if err := os.Chown(dir, int(uid), int(gid)); err != nil {
+		return nil, fmt.Errorf("failed to hand %s to %s: %w", dir, name, err)
+	}
+	return &credential{Uid: uint32(uid), Gid: uint32(gid)}, nil
+}
+
+func runAs(cmd *exec.Cmd, cred *credential) {
+	if cred != nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Credential: cred}
+	}
+}
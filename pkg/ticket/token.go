@@ -0,0 +1,120 @@
+// Package ticket signs and verifies ticket tokens with Ed25519.
+//
+// A token has the form "PT1.<payload>.<signature>", both parts unpadded
+// base64url, where the payload is the JSON encoded Claims and the signature
+// covers "PT1.<payload>". Verifying needs only the public key, so gate
+// scanners can check tickets without reaching the booking service.
+package ticket
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+const prefix = "PT1."
+
+var (
+	ErrMalformedToken   = errors.New("ticket token malformed")
+	ErrInvalidSignature = errors.New("ticket token signature invalid")
+	ErrInvalidKey       = errors.New("ticket key must be a base64 Ed25519 seed or private key")
+)
+
+// Claims is what a ticket token vouches for. Times are Unix seconds to keep
+// QR codes small.
+type Claims struct {
+	TicketID    string `json:"tid"`
+	BookingID   int64  `json:"bid"`
+	PassengerID int64  `json:"pid,omitempty"`
+	RouteID     int64  `json:"rid"`
+	DepartureAt int64  `json:"dep,omitempty"`
+	Seat        string `json:"seat,omitempty"`
+	IssuedAt    int64  `json:"iat"`
+}
+
+// Sign returns the token for claims.
+func Sign(key ed25519.PrivateKey, claims *Claims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signed := prefix + base64.RawURLEncoding.EncodeToString(payload)
+	signature := ed25519.Sign(key, []byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Verify checks token against any of keys, so tokens signed before a key
+// rotation stay valid while the old public key is still listed.
+func Verify(token string, keys ...ed25519.PublicKey) (*Claims, error) {
+	if !strings.HasPrefix(token, prefix) {
+		return nil, ErrMalformedToken
+	}
+	dot := strings.LastIndexByte(token, '.')
+	if dot < len(prefix) {
+		return nil, ErrMalformedToken
+	}
+	signed, encodedSignature := token[:dot], token[dot+1:]
+
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil || len(signature) != ed25519.SignatureSize {
+		return nil, ErrMalformedToken
+	}
+	valid := false
+	for _, key := range keys {
+		if ed25519.Verify(key, []byte(signed), signature) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, ErrInvalidSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(signed[len(prefix):])
+	if err != nil {
+		return nil, ErrMalformedToken
+	}
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrMalformedToken
+	}
+
+	return &claims, nil
+}
+
+// ParsePrivateKey decodes a standard base64 Ed25519 seed (32 bytes) or
+// private key (64 bytes).
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	default:
+		return nil, ErrInvalidKey
+	}
+}
+
+// ParsePublicKey decodes a standard base64 Ed25519 public key.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, ErrInvalidKey
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// GenerateKey returns a new private key.
+func GenerateKey() (ed25519.PrivateKey, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	return key, err
+}
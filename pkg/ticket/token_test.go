@@ -0,0 +1,70 @@
+package ticket
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSignVerify(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := GenerateKey()
+	claims := &Claims{TicketID: "abc", BookingID: 42, PassengerID: 7, RouteID: 3, DepartureAt: 1760083200, Seat: "3A", IssuedAt: 1759900000}
+
+	token, err := Sign(key, claims)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	// The old key still verifies while it is listed during a rotation
+	got, err := Verify(token, other.Public().(ed25519.PublicKey), key.Public().(ed25519.PublicKey))
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if *got != *claims {
+		t.Errorf("claims: got %+v want %+v", got, claims)
+	}
+
+	if _, err := Verify(token, other.Public().(ed25519.PublicKey)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("wrong key: got %v, want ErrInvalidSignature", err)
+	}
+
+	parts := strings.Split(token, ".")
+	forged, _ := Sign(other, &Claims{TicketID: "abc", BookingID: 43})
+	tampered := parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2]
+	if _, err := Verify(tampered, key.Public().(ed25519.PublicKey)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("tampered payload: got %v, want ErrInvalidSignature", err)
+	}
+
+	for _, bad := range []string{"", "PT1.", "PT1.abc", "JWT.abc.def", token + "x"} {
+		if _, err := Verify(bad, key.Public().(ed25519.PublicKey)); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key, _ := GenerateKey()
+
+	for _, encoded := range []string{
+		base64.StdEncoding.EncodeToString(key.Seed()),
+		base64.StdEncoding.EncodeToString(key),
+	} {
+		parsed, err := ParsePrivateKey(encoded)
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		if !parsed.Equal(key) {
+			t.Error("parsed key differs")
+		}
+	}
+
+	if _, err := ParsePrivateKey("c2hvcnQ="); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("short key: got %v, want ErrInvalidKey", err)
+	}
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateAddress is returned when a webhook would reach a host that is not
+// on the public internet.
+var ErrPrivateAddress = errors.New("webhook address is not public")
+
+// PublicAddr reports whether addr is a routable public address, i.e. not
+// loopback, link-local (which includes cloud metadata endpoints), private,
+// shared, multicast or unspecified.
+func PublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which IsPrivate misses.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// ValidURL reports whether raw is an https URL whose host is not obviously
+// internal. Host names are resolved only when dialling, where NewClient
+// checks the address again.
+func ValidURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return false
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return PublicAddr(addr)
+	}
+	return true
+}
+
+// NewClient returns an HTTP client for outbound webhooks that refuses to
+// connect to non-public addresses, so a partner URL cannot reach internal
+// services even if its name later resolves to one.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !PublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrPrivateAddress, addrPort.Addr())
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("whsec_test")
+	body := []byte(`{"type":"booking.paid","booking_id":42}`)
+	sentAt := time.Unix(1700000000, 0)
+	header := Sign(secret, sentAt, body)
+	_, digest, _ := strings.Cut(header, ",v1=")
+
+	tests := []struct {
+		name   string
+		secret []byte
+		header string
+		body   []byte
+		now    time.Time
+		want   error
+	}{
+		{"valid", secret, header, body, sentAt, nil},
+		{"valid within tolerance", secret, header, body, sentAt.Add(DefaultTolerance), nil},
+		{"clock behind sender", secret, header, body, sentAt.Add(-DefaultTolerance), nil},
+		{"rotated secret", secret, "t=1700000000,v1=" + strings.Repeat("0", 64) + ",v1=" + digest, body, sentAt, nil},
+		{"spaces around parts", secret, "t=1700000000, v1=" + digest, body, sentAt, nil},
+		{"tampered body", secret, header, []byte(`{"type":"booking.paid","booking_id":43}`), sentAt, ErrInvalidSignature},
+		{"wrong secret", []byte("whsec_other"), header, body, sentAt, ErrInvalidSignature},
+		{"stale", secret, header, body, sentAt.Add(DefaultTolerance + time.Second), ErrSignatureExpired},
+		{"from the future", secret, header, body, sentAt.Add(-DefaultTolerance - time.Second), ErrSignatureExpired},
+		{"timestamp swapped", secret, "t=1700000001,v1=" + digest, body, sentAt, ErrInvalidSignature},
+		{"missing", secret, "", body, sentAt, ErrMissingSignature},
+		{"no timestamp", secret, "v1=" + digest, body, sentAt, ErrInvalidSignature},
+		{"no digest", secret, "t=1700000000", body, sentAt, ErrInvalidSignature},
+		{"part without value", secret, "t=1700000000,v1", body, sentAt, ErrInvalidSignature},
+		{"non-numeric timestamp", secret, "t=yesterday,v1=" + digest, body, sentAt, ErrInvalidSignature},
+		{"unknown scheme only", secret, "t=1700000000,v0=" + digest, body, sentAt, ErrInvalidSignature},
+		{"garbage", secret, "not a signature", body, sentAt, ErrInvalidSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.header, tt.body, DefaultTolerance, tt.now)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	header := Sign([]byte("whsec_test"), time.Unix(1700000000, 0), []byte("{}"))
+	timestamp, digest, ok := strings.Cut(header, ",v1=")
+	if !ok || timestamp != "t=1700000000" || len(digest) != 64 {
+		t.Errorf("got %q, want t=<unix>,v1=<64 hex digits>", header)
+	}
+	if again := Sign([]byte("whsec_test"), time.Unix(1700000000, 0), []byte("{}")); again != header {
+		t.Errorf("signing is not deterministic: %q and %q", header, again)
+	}
+}
@@ -0,0 +1,23 @@
+// Package api embeds the booking service API contracts so the binary can serve
+// them without relying on the working directory.
+package api
+
+import (
+	_ "embed"
+
+	swaggerfiles "github.com/swaggo/files/v2"
+)
+
+// OpenAPISpec is the OpenAPI 3 document describing the REST routes.
+//
+//go:embed openapi.json
+var OpenAPISpec []byte
+
+// DocsPage renders OpenAPISpec with Swagger UI.
+//
+//go:embed docs.html
+var DocsPage []byte
+
+// DocsAssets holds the Swagger UI files DocsPage loads. They are served by the
+// binary itself, at the version go.mod pins, rather than from a CDN.
+var DocsAssets = swaggerfiles.FS
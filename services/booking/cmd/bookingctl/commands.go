@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/importer"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/manifest"
+)
+
+func runGet(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("get", flag.ExitOnError)
+	includeDeleted := flags.Bool("include-deleted", false, "also find soft-deleted bookings")
+	id, err := bookingID(parseArgs(flags, args))
+	if err != nil {
+		return err
+	}
+
+	var opts []repository.QueryOption
+	if *includeDeleted {
+		opts = append(opts, repository.IncludeDeleted())
+	}
+	booking, err := c.bookingRepo.GetByID(ctx, id, opts...)
+	if err != nil {
+		return err
+	}
+
+	return c.out.bookings([]*entity.Booking{booking})
+}
+
+func runList(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	limit := flags.Int("limit", 20, "maximum number of bookings")
+	offset := flags.Int("offset", 0, "number of bookings to skip")
+	parseArgs(flags, args)
+
+	bookings, err := c.bookingService.ListBookings(ctx, entity.BookingFilter{}, *limit, *offset)
+	if err != nil {
+		return err
+	}
+
+	return c.out.bookings(bookings)
+}
+
+func runSearch(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	limit := flags.Int("limit", 20, "maximum number of bookings")
+	offset := flags.Int("offset", 0, "number of bookings to skip")
+	userID := flags.Int64("user", 0, "user ID")
+	routeID := flags.Int64("route", 0, "route ID")
+	status := flags.String("status", "", "booking status")
+	from := flags.String("from", "", "created at or after (RFC 3339)")
+	to := flags.String("to", "", "created before (RFC 3339)")
+	parseArgs(flags, args)
+
+	filter := entity.BookingFilter{
+		UserID:  *userID,
+		RouteID: *routeID,
+		Status:  entity.BookingStatus(strings.ToUpper(*status)),
+	}
+	var err error
+	if filter.CreatedFrom, err = parseTime("from", *from); err != nil {
+		return err
+	}
+	if filter.CreatedTo, err = parseTime("to", *to); err != nil {
+		return err
+	}
+
+	bookings, err := c.bookingService.ListBookings(ctx, filter, *limit, *offset)
+	if err != nil {
+		return err
+	}
+
+	return c.out.bookings(bookings)
+}
+
+func runHistory(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("history", flag.ExitOnError)
+	id, err := bookingID(parseArgs(flags, args))
+	if err != nil {
+		return err
+	}
+
+	history, err := c.bookingService.GetBookingHistory(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return c.out.history(history)
+}
+
+func runTransition(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("transition", flag.ExitOnError)
+	positional := parseArgs(flags, args)
+	if len(positional) != 2 {
+		return errors.New("usage: transition <id> <STATUS>")
+	}
+	id, err := bookingID(positional[:1])
+	if err != nil {
+		return err
+	}
+
+	booking, err := c.bookingService.TransitionStatus(ctx, id, entity.BookingStatus(strings.ToUpper(positional[1])))
+	if err != nil {
+		return err
+	}
+
+	return c.out.bookings([]*entity.Booking{booking})
+}
+
+func runCancel(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("cancel", flag.ExitOnError)
+	reason := flags.String("reason", string(entity.CancelReasonOperator), "cancellation reason")
+	id, err := bookingID(parseArgs(flags, args))
+	if err != nil {
+		return err
+	}
+
+	cancellation, err := c.bookingService.CancelBooking(ctx, id, entity.CancellationReason(strings.ToUpper(*reason)))
+	if err != nil {
+		return err
+	}
+
+	return c.out.message(
+		fmt.Sprintf("cancelled booking %d (%s), refund %d%% = %s",
+			cancellation.BookingID, cancellation.Reason, cancellation.RefundPercent, cancellation.RefundAmount),
+		cancellation,
+	)
+}
+
+func runManifest(ctx context.Context, c *cli, args []string) (err error) {
+	flags := flag.NewFlagSet("manifest", flag.ExitOnError)
+	routeID := flags.Int64("route", 0, "route ID")
+	departure := flags.String("departure", "", "departure time (RFC 3339)")
+	format := flags.String("format", manifest.FormatText, "csv, json or text")
+	file := flags.String("file", "", "write to this file instead of stdout")
+	parseArgs(flags, args)
+
+	departureAt, err := parseTime("departure", *departure)
+	if err != nil {
+		return err
+	}
+	if *routeID <= 0 || departureAt == nil {
+		return errors.New("manifest needs -route and -departure")
+	}
+	filter := entity.ManifestFilter{RouteID: *routeID, DepartureAt: *departureAt}
+
+	var out io.Writer = os.Stdout
+	if *file != "" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+		}()
+		out = f
+	}
+
+	mw, err := manifest.NewWriter(out, *format, filter)
+	if err != nil {
+		return err
+	}
+	if err := c.manifestService.StreamManifest(ctx, filter, mw.Write); err != nil {
+		return err
+	}
+
+	return mw.Close()
+}
+
+func runImport(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	mode := flags.String("mode", string(entity.ImportAtomic), "atomic or best_effort")
+	format := flags.String("format", "", "csv or ndjson (default from the file extension)")
+	files := parseArgs(flags, args)
+	if len(files) != 1 {
+		return errors.New("import needs exactly one file, or - for stdin")
+	}
+
+	in := os.Stdin
+	if files[0] != "-" {
+		f, err := os.Open(files[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	if *format == "" {
+		*format = importer.FormatCSV
+		if ext := strings.ToLower(filepath.Ext(files[0])); ext == ".ndjson" || ext == ".jsonl" {
+			*format = importer.FormatNDJSON
+		}
+	}
+
+	rows, err := importer.Read(in, *format)
+	if err != nil {
+		return err
+	}
+	result, err := c.importService.ImportBookings(ctx, rows, entity.ImportMode(*mode))
+	if err != nil {
+		return err
+	}
+	if err := c.out.importResult(result); err != nil {
+		return err
+	}
+
+	// Scripts see a failed import in the exit status
+	if result.Failed > 0 {
+		return fmt.Errorf("%d of %d row(s) failed", result.Failed, result.Total)
+	}
+	return nil
+}
+
+func runOutbox(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: outbox list|replay [filters]")
+	}
+
+	flags := flag.NewFlagSet("outbox "+args[0], flag.ExitOnError)
+	pending := flags.Bool("pending", false, "only unpublished events")
+	eventType := flags.String("type", "", "event type, e.g. booking.cancelled")
+	bookingID := flags.Int64("booking", 0, "booking ID")
+	fromID := flags.Int64("from-id", 0, "first event ID")
+	toID := flags.Int64("to-id", 0, "last event ID")
+	limit := flags.Int("limit", 50, "maximum number of events to list")
+	parseArgs(flags, args[1:])
+
+	filter := entity.OutboxFilter{
+		FromID:      *fromID,
+		ToID:        *toID,
+		AggregateID: *bookingID,
+		EventType:   *eventType,
+		PendingOnly: *pending,
+	}
+
+	switch args[0] {
+	case "list":
+		events, err := c.outboxRepo.List(ctx, filter, *limit)
+		if err != nil {
+			return err
+		}
+		return c.out.events(events)
+	case "replay":
+		// Replaying everything is never what an operator means
+		if filter == (entity.OutboxFilter{}) {
+			return errors.New("outbox replay needs at least one of -from-id, -to-id, -booking or -type")
+		}
+		n, err := c.outboxRepo.MarkPending(ctx, filter)
+		if err != nil {
+			return err
+		}
+		return c.out.message(fmt.Sprintf("marked %d event(s) for redelivery", n), map[string]int{"replayed": n})
+	default:
+		return fmt.Errorf("unknown outbox command %q", args[0])
+	}
+}
+
+// parseArgs parses flags wherever they appear among the positional arguments,
+// so both "get -include-deleted 42" and "get 42 -include-deleted" work.
+func parseArgs(flags *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		flags.Parse(args)
+		args = flags.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func bookingID(args []string) (int64, error) {
+	if len(args) != 1 {
+		return 0, errors.New("expected exactly one booking ID")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid booking ID %q", args[0])
+	}
+	return id, nil
+}
+
+func parseTime(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -%s: expected RFC 3339 timestamp", name)
+	}
+	return &t, nil
+}
@@ -0,0 +1,285 @@
+// Command bookingctl is the operator CLI for the booking service. It talks to
+// the booking database directly but routes every change through the booking
+// use case, so lifecycle rules, history and outbox events still apply.
+//
+//	bookingctl [-o table|json] [-actor name] [-tenant id] <command> [flags] [args]
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/cache"
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	"github.com/ibnuzaman/porta-pay/pkg/ledger"
+	"github.com/ibnuzaman/porta-pay/pkg/logger"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/config"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/policy"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/job"
+	postgres "github.com/ibnuzaman/porta-pay/services/booking/internal/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/usecase"
+)
+
+const usage = `Usage: bookingctl [-o table|json] [-actor name] [-tenant id] <command> [flags] [args]
+
+Commands:
+  get <id> [-include-deleted]        Show one booking
+  list [-limit n] [-offset n]        List bookings, newest first
+  search [filters]                   List bookings matching -user, -route,
+                                     -status, -from and -to (RFC 3339)
+  history <id>                       Show the audit trail of a booking
+  transition <id> <STATUS>           Move a booking to PAID, CONFIRMED, ...
+  cancel <id> [-reason REASON]       Cancel a booking under the refund policy
+  manifest -route n -departure T     Print the passenger manifest of a
+                                     departure (-format csv|json|text,
+                                     -file path; -o does not apply)
+  expire                             Run the unpaid booking expiry sweep once
+  outbox list [filters]              List outbox events (-pending, -type,
+                                     -booking, -from-id, -to-id)
+  outbox replay [filters]            Mark published or dead-lettered events
+                                     pending again so they are redelivered
+                                     (same filters)
+  import [-mode m] [-format f] <file>
+                                     Create the bookings of a CSV or NDJSON
+                                     file (- for stdin), atomic or
+                                     best_effort, and print a per-row report
+  reports refresh [-full]            Bring the reporting rollups up to date,
+                                     or rebuild them all with -full
+  reconcile [-dir path]              Ingest new settlement files and
+                                     reconcile them with paid bookings
+  ledger balances [-account code] [-booking id]
+                                     Show ledger balances per account and
+                                     currency
+
+Without -tenant, commands act across tenants and bookings are created for
+the default tenant. The database is configured from the same environment as
+the service.
+`
+
+// cli holds what every command needs.
+type cli struct {
+	bookingRepo     repository.BookingRepository
+	outboxRepo      repository.OutboxRepository
+	bookingService  service.BookingService
+	manifestService service.ManifestService
+	reportService   service.ReportService
+	importService   service.ImportService
+	reconcileRepo   repository.ReconciliationRepository
+	ledgerService   service.LedgerService
+	transactor      repository.Transactor
+	cfg             *config.BookingConfig
+	out             *printer
+}
+
+type command func(ctx context.Context, c *cli, args []string) error
+
+var commands = map[string]command{
+	"get":        runGet,
+	"list":       runList,
+	"search":     runSearch,
+	"history":    runHistory,
+	"transition": runTransition,
+	"cancel":     runCancel,
+	"manifest":   runManifest,
+	"expire":     runExpire,
+	"outbox":     runOutbox,
+	"import":     runImport,
+	"reports":    runReports,
+	"reconcile":  runReconcile,
+	"ledger":     runLedger,
+}
+
+func main() {
+	flags := flag.NewFlagSet("bookingctl", flag.ExitOnError)
+	flags.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	format := flags.String("o", "table", "output format: table or json")
+	actor := flags.String("actor", defaultActor(), "actor recorded in the booking history")
+	tenant := flags.String("tenant", "", "act as this tenant rather than across tenants")
+	flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	run, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "bookingctl: unknown command %q\n\n", flags.Arg(0))
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *format != "table" && *format != "json" {
+		fatal(fmt.Errorf("unknown output format %q", *format))
+	}
+
+	cfg, err := config.LoadBookingConfig()
+	if err != nil {
+		fatal(err)
+	}
+	if cfg.GetDSN() == "" {
+		fatal(errors.New("database is not configured"))
+	}
+
+	db := database.Open(cfg.GetDSN())
+	defer db.Close()
+
+	bookingRepo := postgres.NewPostgresBookingRepository(db)
+	// Writes made here must evict the bookings the service has cached in Redis
+	if cfg.BookingCacheEnabled && cfg.RedisAddr != "" {
+		rdb := database.OpenRedis(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB)
+		defer rdb.Close()
+		ttls := postgres.BookingCacheTTLs{Found: cfg.BookingCacheTTL, NotFound: cfg.BookingCacheNotFoundTTL}
+		bookingRepo = postgres.NewCachedBookingRepository(bookingRepo, cache.NewRedis(rdb, cfg.AppName+":cache:"), ttls, "redis")
+	}
+	outboxRepo := postgres.NewPostgresOutboxRepository(db)
+	historyRepo := postgres.NewPostgresBookingHistoryRepository(db)
+	idemRepo := postgres.NewPostgresIdempotencyRepository(db)
+	cancelPolicy := policy.NewCancellationPolicy(cfg.AllowCancelHours, policy.DefaultRefundTiers)
+	reportLocation, err := time.LoadLocation(cfg.ReportTimeZone)
+	if err != nil {
+		fatal(err)
+	}
+
+	transactor := database.NewTransactor(db)
+	routeRepo := postgres.NewPostgresRouteRepository(db)
+	tenantService := usecase.NewTenantUsecase(postgres.NewPostgresTenantRepository(db), routeRepo, cache.NewLRU(100), time.Minute)
+	bookingService := usecase.NewBookingUsecase(bookingRepo, outboxRepo, historyRepo, idemRepo, postgres.NewPostgresPromotionRepository(db), routeRepo, transactor, tenantService, cancelPolicy)
+
+	c := &cli{
+		bookingRepo:     bookingRepo,
+		outboxRepo:      outboxRepo,
+		bookingService:  bookingService,
+		manifestService: usecase.NewManifestUsecase(postgres.NewPostgresManifestRepository(db)),
+		reportService:   usecase.NewReportUsecase(postgres.NewPostgresReportRepository(db), reportLocation, cfg.ReportRefreshOverlap),
+		importService:   usecase.NewImportUsecase(bookingService, transactor, cfg.ImportMaxRows),
+		reconcileRepo:   postgres.NewPostgresReconciliationRepository(db),
+		ledgerService:   usecase.NewLedgerUsecase(ledger.NewStore(db)),
+		transactor:      transactor,
+		cfg:             cfg,
+		out:             newPrinter(os.Stdout, *format),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	ctx = reqctx.WithActor(ctx, *actor)
+	if *tenant != "" {
+		if _, err := tenantService.GetTenant(ctx, *tenant); err != nil {
+			fatal(err)
+		}
+		ctx = reqctx.WithTenant(ctx, *tenant)
+	} else {
+		ctx = reqctx.WithTenant(ctx, reqctx.AllTenants)
+	}
+
+	if err := run(ctx, c, flags.Args()[1:]); err != nil {
+		fatal(err)
+	}
+}
+
+func runExpire(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("expire", flag.ExitOnError)
+	batch := flags.Int("batch", c.cfg.ExpiryBatchSize, "bookings expired per batch")
+	flags.Parse(args)
+
+	expirer := job.NewExpirer(
+		c.bookingService,
+		time.Duration(c.cfg.BookingExpiryHours)*time.Hour,
+		0,
+		*batch,
+		logger.New("bookingctl", c.cfg.Env),
+	)
+	n, err := expirer.RunOnce(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.out.message(fmt.Sprintf("expired %d booking(s)", n), map[string]int{"expired": n})
+}
+
+func runReports(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 || args[0] != "refresh" {
+		return errors.New("usage: reports refresh [-full]")
+	}
+
+	flags := flag.NewFlagSet("reports refresh", flag.ExitOnError)
+	full := flags.Bool("full", false, "rebuild every rollup instead of the changed ones")
+	parseArgs(flags, args[1:])
+
+	n, err := c.reportService.RefreshReports(ctx, *full)
+	if err != nil {
+		return err
+	}
+
+	return c.out.message(fmt.Sprintf("recomputed %d hourly bucket(s)", n), map[string]int{"recomputed_buckets": n})
+}
+
+func runReconcile(ctx context.Context, c *cli, args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	dir := flags.String("dir", c.cfg.SettlementDir, "settlement directory, laid out as <provider>/<name>.csv")
+	parseArgs(flags, args)
+
+	var files fs.FS
+	if *dir != "" {
+		files = os.DirFS(*dir)
+	}
+	reconciliationService := usecase.NewReconciliationUsecase(c.reconcileRepo, c.ledgerService, c.transactor, files, c.cfg.ReconcileGrace, c.cfg.ReconcileLookback)
+
+	run, err := reconciliationService.Reconcile(ctx)
+	if err != nil {
+		return err
+	}
+	text := fmt.Sprintf("ingested %d file(s) with %d settlement(s), opened %d and resolved %d discrepancy(ies)",
+		run.Files, run.Settlements, run.Opened, run.Resolved)
+	for _, msg := range run.Errors {
+		text += "\nskipped " + msg
+	}
+	if err := c.out.message(text, run); err != nil {
+		return err
+	}
+
+	// Scripts see unreadable files in the exit status
+	if len(run.Errors) > 0 {
+		return fmt.Errorf("%d settlement file(s) could not be read", len(run.Errors))
+	}
+	return nil
+}
+
+func runLedger(ctx context.Context, c *cli, args []string) error {
+	if len(args) == 0 || args[0] != "balances" {
+		return errors.New("usage: ledger balances [-account code] [-booking id]")
+	}
+
+	flags := flag.NewFlagSet("ledger balances", flag.ExitOnError)
+	account := flags.String("account", "", "only this account")
+	booking := flags.Int64("booking", 0, "only entries of this booking")
+	parseArgs(flags, args[1:])
+
+	balances, err := c.ledgerService.Balances(ctx, ledger.Filter{BookingID: *booking, Account: *account})
+	if err != nil {
+		return err
+	}
+
+	return c.out.balances(balances)
+}
+
+func defaultActor() string {
+	if user := os.Getenv("USER"); user != "" {
+		return "ops:" + user
+	}
+	return "ops"
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "bookingctl:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/ledger"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+// printer renders command results as aligned tables or indented JSON.
+type printer struct {
+	w      io.Writer
+	asJSON bool
+}
+
+func newPrinter(w io.Writer, format string) *printer {
+	return &printer{w: w, asJSON: format == "json"}
+}
+
+func (p *printer) bookings(bookings []*entity.Booking) error {
+	if p.asJSON {
+		return p.json(bookings)
+	}
+
+	return p.table(
+		[]string{"ID", "USER", "ROUTE", "QTY", "STATUS", "PRICE", "DEPARTURE", "CREATED", "DELETED"},
+		len(bookings),
+		func(i int) []string {
+			b := bookings[i]
+			return []string{
+				fmt.Sprint(b.ID), fmt.Sprint(b.UserID), fmt.Sprint(b.RouteID), fmt.Sprint(b.Qty),
+				string(b.Status), b.PriceTotal.String(), formatTime(b.DepartureAt),
+				formatTime(&b.CreatedAt), formatTime(b.DeletedAt),
+			}
+		},
+	)
+}
+
+func (p *printer) history(history []*entity.BookingHistoryEntry) error {
+	if p.asJSON {
+		return p.json(history)
+	}
+
+	return p.table(
+		[]string{"AT", "ACTION", "STATUS", "ACTOR", "CHANGES"},
+		len(history),
+		func(i int) []string {
+			e := history[i]
+			status := string(e.NewStatus)
+			if e.OldStatus != "" && e.OldStatus != e.NewStatus {
+				status = string(e.OldStatus) + " -> " + status
+			}
+			return []string{formatTime(&e.CreatedAt), e.Action, status, e.Actor, changedFields(e.Changes)}
+		},
+	)
+}
+
+func (p *printer) events(events []*entity.OutboxEvent) error {
+	if p.asJSON {
+		return p.json(events)
+	}
+
+	return p.table(
+		[]string{"ID", "TYPE", "BOOKING", "CREATED", "PUBLISHED", "ATTEMPTS", "DEAD"},
+		len(events),
+		func(i int) []string {
+			e := events[i]
+			return []string{
+				fmt.Sprint(e.ID), e.EventType, fmt.Sprint(e.AggregateID), formatTime(&e.CreatedAt), formatTime(e.PublishedAt),
+				fmt.Sprint(e.Attempts), formatTime(e.DeadAt),
+			}
+		},
+	)
+}
+
+func (p *printer) importResult(result *entity.ImportResult) error {
+	if p.asJSON {
+		return p.json(result)
+	}
+
+	err := p.table(
+		[]string{"ROW", "REF", "LINES", "STATUS", "BOOKING", "ERROR"},
+		len(result.Rows),
+		func(i int) []string {
+			r := result.Rows[i]
+			lines := make([]string, len(r.Lines))
+			for j, line := range r.Lines {
+				lines[j] = fmt.Sprint(line)
+			}
+			booking := "-"
+			if r.BookingID != nil {
+				booking = fmt.Sprint(*r.BookingID)
+			}
+			return []string{fmt.Sprint(r.Row), r.Ref, strings.Join(lines, ","), string(r.Status), booking, r.Error}
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(p.w, "\n%s import: %d created, %d failed of %d, committed: %t\n",
+		result.Mode, result.Created, result.Failed, result.Total, result.Committed)
+	return err
+}
+
+// message prints text for tables and v for JSON output.
+func (p *printer) balances(balances []*ledger.Balance) error {
+	if p.asJSON {
+		return p.json(balances)
+	}
+
+	return p.table(
+		[]string{"ACCOUNT", "TYPE", "DEBITS", "CREDITS", "BALANCE"},
+		len(balances),
+		func(i int) []string {
+			b := balances[i]
+			return []string{b.Account, string(b.Type), b.Debits.String(), b.Credits.String(), b.Balance.String()}
+		},
+	)
+}
+
+func (p *printer) message(text string, v interface{}) error {
+	if p.asJSON {
+		return p.json(v)
+	}
+	_, err := fmt.Fprintln(p.w, text)
+	return err
+}
+
+func (p *printer) json(v interface{}) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (p *printer) table(header []string, rows int, row func(i int) []string) error {
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for i := 0; i < rows; i++ {
+		fmt.Fprintln(tw, strings.Join(row(i), "\t"))
+	}
+	return tw.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func changedFields(changes map[string]entity.FieldChange) string {
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return strings.Join(fields, ",")
+}
@@ -2,28 +2,45 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"io/fs"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/redis/go-redis/v9"
 
-	"github.com/ibnuzaman/porta-pay/pkg/config"
+	"github.com/ibnuzaman/porta-pay/pkg/cache"
 	"github.com/ibnuzaman/porta-pay/pkg/database"
+	"github.com/ibnuzaman/porta-pay/pkg/ledger"
 	"github.com/ibnuzaman/porta-pay/pkg/logger"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/pkg/ticket"
 	"github.com/ibnuzaman/porta-pay/pkg/tracer"
+	"github.com/ibnuzaman/porta-pay/pkg/webhook"
 
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/config"
+	grpchandler "github.com/ibnuzaman/porta-pay/services/booking/internal/delivery/grpc/handler"
+	grpcserver "github.com/ibnuzaman/porta-pay/services/booking/internal/delivery/grpc/server"
 	"github.com/ibnuzaman/porta-pay/services/booking/internal/delivery/http/handler"
+	bookingmw "github.com/ibnuzaman/porta-pay/services/booking/internal/delivery/http/middleware"
 	"github.com/ibnuzaman/porta-pay/services/booking/internal/delivery/http/router"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/policy"
+	domainrepo "github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/job"
 	"github.com/ibnuzaman/porta-pay/services/booking/internal/repository"
 	"github.com/ibnuzaman/porta-pay/services/booking/internal/usecase"
 )
 
 func main() {
-	cfg, err := config.Load()
+	cfg, err := config.LoadBookingConfig()
 	if err != nil {
 		panic(err)
 	}
@@ -40,19 +57,200 @@ func main() {
 		defer shutdownTracer(context.Background())
 	}
 
+	shutdownMeter, err := tracer.SetupMeter(ctx, cfg.AppName, cfg.OTLPEndpoint)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to setup metrics")
+	} else {
+		defer shutdownMeter(context.Background())
+	}
+
+	// Background jobs run until shutdown, acting across tenants
+	jobsCtx, stopJobs := context.WithCancel(reqctx.WithTenant(context.Background(), reqctx.AllTenants))
+	defer stopJobs()
+
 	// Setup database (skip if POSTGRES_DSN is not set)
 	var r chi.Router
+	var grpcServer *grpcserver.Server
 	if cfg.GetDSN() != "" && cfg.GetDSN() != "postgres://::@:0/?sslmode=disable" {
 		db := database.Open(cfg.GetDSN())
 		defer db.Close()
 
 		// Dependency injection - Clean Architecture wiring
+		var rdb *redis.Client
+		if cfg.RedisAddr != "" {
+			rdb = database.OpenRedis(cfg.RedisAddr, cfg.RedisPassword, cfg.RedisDB)
+			defer rdb.Close()
+		}
+
+		transactor := database.NewTransactor(db)
 		bookingRepo := repository.NewPostgresBookingRepository(db)
-		bookingUsecase := usecase.NewBookingUsecase(bookingRepo)
+		var bookingCache cache.Cache
+		if cfg.BookingCacheEnabled {
+			ttls := repository.BookingCacheTTLs{Found: cfg.BookingCacheTTL, NotFound: cfg.BookingCacheNotFoundTTL}
+			if rdb != nil {
+				bookingCache = cache.NewRedis(rdb, cfg.AppName+":cache:")
+				bookingRepo = repository.NewCachedBookingRepository(bookingRepo, bookingCache, ttls, "redis")
+			} else {
+				bookingCache = cache.NewLRU(cfg.BookingCacheSize)
+				bookingRepo = repository.NewCachedBookingRepository(bookingRepo, bookingCache, ttls, "lru")
+			}
+		}
+		outboxRepo := repository.NewPostgresOutboxRepository(db)
+		cancelPolicy := policy.NewCancellationPolicy(cfg.AllowCancelHours, policy.DefaultRefundTiers)
+		historyRepo := repository.NewPostgresBookingHistoryRepository(db)
+		idemRepo := repository.NewPostgresIdempotencyRepository(db)
+		promotionRepo := repository.NewPostgresPromotionRepository(db)
+		routeRepo := repository.NewPostgresRouteRepository(db)
+		tenantUsecase := usecase.NewTenantUsecase(repository.NewPostgresTenantRepository(db), routeRepo, cache.NewLRU(1000), cfg.TenantCacheTTL)
+		tenantHandler := handler.NewTenantHandler(tenantUsecase)
+		promotionUsecase := usecase.NewPromotionUsecase(promotionRepo)
+		promotionHandler := handler.NewPromotionHandler(promotionUsecase)
+		bookingUsecase := usecase.NewBookingUsecase(bookingRepo, outboxRepo, historyRepo, idemRepo, promotionRepo, routeRepo, transactor, tenantUsecase, cancelPolicy)
 		bookingHandler := handler.NewBookingHandler(bookingUsecase)
+		ledgerUsecase := usecase.NewLedgerUsecase(ledger.NewStore(db))
+		ledgerHandler := handler.NewLedgerHandler(ledgerUsecase)
+		paymentUsecase := usecase.NewPaymentUsecase(bookingUsecase, bookingRepo, repository.NewPostgresPaymentEventRepository(db), ledgerUsecase, transactor)
+		paymentWebhookHandler := handler.NewPaymentWebhookHandler(paymentUsecase, cfg.PaymentWebhookSecrets, cfg.PaymentWebhookTolerance)
+		subscriptionRepo := repository.NewPostgresWebhookSubscriptionRepository(db)
+		deliveryRepo := repository.NewPostgresWebhookDeliveryRepository(db)
+		webhookUsecase := usecase.NewWebhookUsecase(subscriptionRepo, deliveryRepo, bookingRepo)
+		webhookHandler := handler.NewWebhookHandler(webhookUsecase)
+
+		var holdStore domainrepo.HoldStore
+		if rdb != nil {
+			holdStore = repository.NewRedisHoldStore(rdb)
+		} else {
+			log.Warn().Msg("Redis not configured, keeping seat holds in process memory")
+			holdStore = repository.NewMemoryHoldStore(nil)
+		}
+		holdHandler := handler.NewHoldHandler(usecase.NewHoldUsecase(bookingUsecase, bookingRepo, holdStore, tenantUsecase, cfg.HoldTTL, cfg.HoldExtension))
+		manifestHandler := handler.NewManifestHandler(usecase.NewManifestUsecase(repository.NewPostgresManifestRepository(db)))
+
+		reportLocation, err := time.LoadLocation(cfg.ReportTimeZone)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid REPORT_TIME_ZONE")
+		}
+		reportUsecase := usecase.NewReportUsecase(repository.NewPostgresReportRepository(db), reportLocation, cfg.ReportRefreshOverlap)
+		reportHandler := handler.NewReportHandler(reportUsecase)
+		exportHandler := handler.NewExportHandler(usecase.NewExportUsecase(repository.NewPostgresExportRepository(db)))
+		importHandler := handler.NewImportHandler(usecase.NewImportUsecase(bookingUsecase, transactor, cfg.ImportMaxRows))
+		var settlementFiles fs.FS
+		if cfg.SettlementDir != "" {
+			settlementFiles = os.DirFS(cfg.SettlementDir)
+		} else {
+			log.Warn().Msg("SETTLEMENT_DIR not set, reconciliation ingests no settlement files")
+		}
+		reconciliationUsecase := usecase.NewReconciliationUsecase(repository.NewPostgresReconciliationRepository(db), ledgerUsecase, transactor, settlementFiles, cfg.ReconcileGrace, cfg.ReconcileLookback)
+		reconciliationHandler := handler.NewReconciliationHandler(reconciliationUsecase)
+		resolveTenant := bookingmw.ResolveTenant(cfg.TenantTokens, cfg.DefaultTenant, func(ctx context.Context, id string) error {
+			_, err := tenantUsecase.GetTenant(ctx, id)
+			return err
+		})
+		if len(cfg.AdminTokens) == 0 {
+			log.Warn().Msg("ADMIN_TOKENS not set, admin endpoints will refuse every request")
+		}
+		if len(cfg.GateTokens) == 0 {
+			log.Warn().Msg("GATE_TOKENS not set, ticket check-in will refuse every request")
+		}
+
+		var signingKey ed25519.PrivateKey
+		if cfg.TicketSigningKey != "" {
+			if signingKey, err = ticket.ParsePrivateKey(cfg.TicketSigningKey); err != nil {
+				log.Fatal().Err(err).Msg("Invalid TICKET_SIGNING_KEY")
+			}
+		} else {
+			log.Warn().Msg("TICKET_SIGNING_KEY not set, signing tickets with a throwaway key")
+			if signingKey, err = ticket.GenerateKey(); err != nil {
+				log.Fatal().Err(err).Msg("Failed to generate ticket signing key")
+			}
+		}
+		var previousKeys []ed25519.PublicKey
+		for _, encoded := range cfg.TicketPreviousKeys {
+			key, err := ticket.ParsePublicKey(encoded)
+			if err != nil {
+				log.Fatal().Err(err).Msg("Invalid TICKET_PREVIOUS_PUBLIC_KEYS")
+			}
+			previousKeys = append(previousKeys, key)
+		}
+		ticketUsecase := usecase.NewTicketUsecase(repository.NewPostgresTicketRepository(db, bookingCache), bookingRepo, signingKey, previousKeys, cfg.TicketQRSize)
+		ticketHandler := handler.NewTicketHandler(ticketUsecase)
+
+		publishers := service.Publishers{
+			{Name: "webhooks", Publisher: webhookUsecase},
+			{Name: "tickets", Publisher: ticketUsecase},
+			{Name: "ledger", Publisher: ledgerUsecase},
+			{Name: "promotions", Publisher: promotionUsecase},
+		}
+		relay := job.NewOutboxRelay(
+			outboxRepo,
+			transactor,
+			publishers,
+			job.RetryPolicy{
+				MaxAttempts: cfg.OutboxMaxAttempts,
+				BaseDelay:   cfg.OutboxBackoffBase,
+				MaxDelay:    cfg.OutboxBackoffMax,
+			},
+			cfg.OutboxRelayInterval,
+			cfg.OutboxRelayBatchSize,
+			log,
+		)
+		go relay.Run(jobsCtx)
+
+		if cfg.WebhookDeliveryEnabled {
+			deliverer := job.NewWebhookDeliverer(
+				subscriptionRepo,
+				deliveryRepo,
+				webhook.NewClient(cfg.WebhookTimeout),
+				job.RetryPolicy{
+					MaxAttempts: cfg.WebhookMaxAttempts,
+					BaseDelay:   cfg.WebhookBackoffBase,
+					MaxDelay:    cfg.WebhookBackoffMax,
+				},
+				cfg.WebhookPollInterval,
+				cfg.WebhookBatchSize,
+				cfg.WebhookConcurrency,
+				log,
+			)
+			go deliverer.Run(jobsCtx)
+		}
+
+		if cfg.ExpiryEnabled {
+			expirer := job.NewExpirer(
+				bookingUsecase,
+				time.Duration(cfg.BookingExpiryHours)*time.Hour,
+				cfg.ExpiryInterval,
+				cfg.ExpiryBatchSize,
+				log,
+			)
+			go expirer.Run(jobsCtx)
+		}
+
+		if cfg.ArchiveEnabled {
+			archiver := job.NewArchiver(
+				repository.NewPostgresArchiveRepository(db, bookingCache),
+				time.Duration(cfg.ArchiveAfterDays)*24*time.Hour,
+				cfg.ArchiveInterval,
+				cfg.ArchiveBatchSize,
+				log,
+			)
+			go archiver.Run(jobsCtx)
+		}
+
+		if cfg.ReportRefreshEnabled {
+			refresher := job.NewReportRefresher(reportUsecase, cfg.ReportRefreshInterval, log)
+			go refresher.Run(jobsCtx)
+		}
+
+		if cfg.ReconcileEnabled {
+			reconciler := job.NewReconciler(reconciliationUsecase, cfg.ReconcileInterval, log)
+			go reconciler.Run(jobsCtx)
+		}
 
 		// Setup router with all middleware applied
-		r = router.NewBookingRouter(bookingHandler)
+		r = router.NewBookingRouter(bookingHandler, paymentWebhookHandler, webhookHandler, holdHandler, manifestHandler, ticketHandler, reportHandler, exportHandler, importHandler, reconciliationHandler, ledgerHandler, promotionHandler, tenantHandler, bookingmw.Authenticate(cfg.AdminTokens, cfg.GateTokens), bookingmw.RequireAdmin(cfg.AdminTokens), bookingmw.RequireGate(cfg.GateTokens), resolveTenant)
+
+		// gRPC shares the same use case instance as REST
+		grpcServer = grpcserver.NewBookingServer(grpchandler.NewBookingServer(bookingUsecase), cfg.GRPCInternalTokens, cfg.TenantTokens, cfg.DefaultTenant, log)
 	} else {
 		log.Warn().Msg("Database not configured, running in health-check mode only")
 		r = setupHealthOnlyRouter()
@@ -73,20 +271,48 @@ func main() {
 		}
 	}()
 
+	if grpcServer != nil {
+		lis, err := net.Listen("tcp", cfg.GRPCAddr)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to listen for gRPC")
+		}
+
+		go func() {
+			log.Printf("Booking gRPC service starting on %s", cfg.GRPCAddr)
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatal().Err(err).Msg("gRPC server failed")
+			}
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Println("Shutting down server...")
+	stopJobs()
 
 	// Graceful shutdown
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
+	// Drain HTTP and gRPC concurrently so neither eats the other's grace period
+	var wg sync.WaitGroup
+	if grpcServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := grpcServer.Shutdown(shutdownCtx); err != nil {
+				log.Error().Err(err).Msg("gRPC server forced to shutdown")
+			}
+		}()
+	}
+
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		log.Fatal().Err(err).Msg("Server forced to shutdown")
 	}
+	wg.Wait()
 
 	log.Println("Server exited")
 }
@@ -0,0 +1,114 @@
+// Command paymentsim sends signed payment webhooks to a local booking service,
+// standing in for a payment provider during development.
+//
+//	paymentsim -secret dev-secret -booking 42 -amount 150000.00
+//	paymentsim -secret dev-secret -booking 42 -type payment.refunded -amount 75000.00
+//	paymentsim -secret dev-secret -booking 42 -amount 150000.00 -repeat 3   # dedupe
+//	paymentsim -secret dev-secret -booking 42 -amount 150000.00 -skew -10m  # stale signature
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/pkg/webhook"
+)
+
+type paymentEvent struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	BookingID  int64       `json:"booking_id"`
+	Amount     money.Money `json:"amount"`
+	OccurredAt time.Time   `json:"occurred_at"`
+}
+
+func main() {
+	baseURL := flag.String("url", "http://localhost:8080", "booking service base URL")
+	provider := flag.String("provider", "sandbox", "provider name, as configured in PAYMENT_WEBHOOK_SECRETS")
+	secret := flag.String("secret", os.Getenv("PAYMENT_WEBHOOK_SECRET"), "provider shared secret (default $PAYMENT_WEBHOOK_SECRET)")
+	bookingID := flag.Int64("booking", 0, "booking ID")
+	eventType := flag.String("type", "payment.succeeded", "payment.succeeded, payment.failed or payment.refunded")
+	amount := flag.String("amount", "", "amount in major units, e.g. 150000.00")
+	currency := flag.String("currency", "IDR", "ISO 4217 currency")
+	eventID := flag.String("event-id", "", "provider event ID (default random)")
+	repeat := flag.Int("repeat", 1, "deliver the same event this many times")
+	skew := flag.Duration("skew", 0, "shift the signature timestamp, e.g. -10m to test the tolerance")
+	badSignature := flag.Bool("bad-signature", false, "sign with a wrong secret")
+	flag.Parse()
+
+	if *secret == "" || *bookingID <= 0 || *amount == "" {
+		fmt.Fprintln(os.Stderr, "paymentsim: -secret, -booking and -amount are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cur, err := money.ParseCurrency(*currency)
+	if err != nil {
+		fatal(err)
+	}
+	paid, err := money.Parse(*amount, cur)
+	if err != nil {
+		fatal(err)
+	}
+	if *eventID == "" {
+		*eventID = "evt_" + randomHex(12)
+	}
+
+	body, err := json.Marshal(paymentEvent{
+		ID:         *eventID,
+		Type:       *eventType,
+		BookingID:  *bookingID,
+		Amount:     paid,
+		OccurredAt: time.Now().UTC(),
+	})
+	if err != nil {
+		fatal(err)
+	}
+
+	signingSecret := []byte(*secret)
+	if *badSignature {
+		signingSecret = []byte(randomHex(16))
+	}
+
+	endpoint := strings.TrimSuffix(*baseURL, "/") + "/webhooks/payments/" + *provider
+	fmt.Printf("POST %s\n%s\n", endpoint, body)
+
+	for i := 0; i < *repeat; i++ {
+		req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(webhook.SignatureHeader, webhook.Sign(signingSecret, time.Now().Add(*skew), body))
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			fatal(err)
+		}
+		respBody, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		fmt.Printf("-> %s %s\n", resp.Status, bytes.TrimSpace(respBody))
+	}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "paymentsim:", err)
+	os.Exit(1)
+}
@@ -1,6 +1,10 @@
 package config
 
 import (
+	"time"
+
+	"github.com/caarlos0/env/v11"
+
 	"github.com/ibnuzaman/porta-pay/pkg/config"
 )
 
@@ -9,9 +13,95 @@ type BookingConfig struct {
 	*config.Config
 
 	// Booking-specific configurations
-	MaxBookingQty      int `env:"MAX_BOOKING_QTY,default=10"`
-	BookingExpiryHours int `env:"BOOKING_EXPIRY_HOURS,default=1"`
-	AllowCancelHours   int `env:"ALLOW_CANCEL_HOURS,default=2"`
+	GRPCAddr           string `env:"GRPC_ADDR" envDefault:":9090"`
+	MaxBookingQty      int    `env:"MAX_BOOKING_QTY" envDefault:"10"`
+	BookingExpiryHours int    `env:"BOOKING_EXPIRY_HOURS" envDefault:"1"`
+	AllowCancelHours   int    `env:"ALLOW_CANCEL_HOURS" envDefault:"2"`
+
+	// Expiry of unpaid bookings after BookingExpiryHours
+	ExpiryEnabled   bool          `env:"EXPIRY_ENABLED" envDefault:"true"`
+	ExpiryInterval  time.Duration `env:"EXPIRY_INTERVAL" envDefault:"1m"`
+	ExpiryBatchSize int           `env:"EXPIRY_BATCH_SIZE" envDefault:"100"`
+
+	// Read-through cache for GetBooking, in Redis when REDIS_ADDR is set and
+	// in a per-instance LRU otherwise
+	BookingCacheEnabled     bool          `env:"BOOKING_CACHE_ENABLED" envDefault:"true"`
+	BookingCacheTTL         time.Duration `env:"BOOKING_CACHE_TTL" envDefault:"30s"`
+	BookingCacheNotFoundTTL time.Duration `env:"BOOKING_CACHE_NOT_FOUND_TTL" envDefault:"5s"`
+	BookingCacheSize        int           `env:"BOOKING_CACHE_SIZE" envDefault:"10000"`
+
+	// Seat holds live in Redis when REDIS_ADDR is set, in process otherwise
+	HoldTTL       time.Duration `env:"HOLD_TTL" envDefault:"10m"`
+	HoldExtension time.Duration `env:"HOLD_EXTENSION" envDefault:"5m"`
+
+	// Ticket tokens are signed with an Ed25519 key (base64 seed); without one
+	// a throwaway key is generated and tickets stop verifying after a restart
+	TicketSigningKey string `env:"TICKET_SIGNING_KEY"`
+	// Public keys of retired signing keys whose tickets are still accepted
+	TicketPreviousKeys []string `env:"TICKET_PREVIOUS_PUBLIC_KEYS"`
+	TicketQRSize       int      `env:"TICKET_QR_SIZE" envDefault:"256"`
+
+	// Bearer tokens for admin endpoints as "name:token" pairs; the name is
+	// recorded as the actor
+	AdminTokens map[string]string `env:"ADMIN_TOKENS"`
+	// Bearer tokens of gate scanners, as "name:token" pairs like AdminTokens
+	GateTokens map[string]string `env:"GATE_TOKENS"`
+	// Bearer tokens of internal services, as "name:token" pairs, that may
+	// name any tenant on gRPC calls, or none to act across tenants
+	GRPCInternalTokens map[string]string `env:"GRPC_INTERNAL_TOKENS"`
+
+	// Tenants: requests bearing one of TENANT_TOKENS ("tenant:token" pairs)
+	// act as that tenant, others as DEFAULT_TENANT unless an admin or internal
+	// caller names one with X-Tenant-ID. Tenants are cached per instance for
+	// TENANT_CACHE_TTL
+	TenantTokens   map[string]string `env:"TENANT_TOKENS"`
+	DefaultTenant  string            `env:"DEFAULT_TENANT" envDefault:"default"`
+	TenantCacheTTL time.Duration     `env:"TENANT_CACHE_TTL" envDefault:"1m"`
+
+	// Most bookings one bulk import may hold
+	ImportMaxRows int `env:"IMPORT_MAX_ROWS" envDefault:"1000"`
+
+	// Inbound payment webhooks; secrets are "provider:secret" pairs
+	PaymentWebhookSecrets   map[string]string `env:"PAYMENT_WEBHOOK_SECRETS"`
+	PaymentWebhookTolerance time.Duration     `env:"PAYMENT_WEBHOOK_TOLERANCE" envDefault:"5m"`
+
+	// Outbox relay hands committed events to webhook fan-out and ticketing
+	OutboxRelayInterval  time.Duration `env:"OUTBOX_RELAY_INTERVAL" envDefault:"1s"`
+	OutboxRelayBatchSize int           `env:"OUTBOX_RELAY_BATCH_SIZE" envDefault:"100"`
+	OutboxMaxAttempts    int           `env:"OUTBOX_MAX_ATTEMPTS" envDefault:"10"`
+	OutboxBackoffBase    time.Duration `env:"OUTBOX_BACKOFF_BASE" envDefault:"5s"`
+	OutboxBackoffMax     time.Duration `env:"OUTBOX_BACKOFF_MAX" envDefault:"1h"`
+
+	// Outbound partner webhooks
+	WebhookDeliveryEnabled bool          `env:"WEBHOOK_DELIVERY_ENABLED" envDefault:"true"`
+	WebhookPollInterval    time.Duration `env:"WEBHOOK_POLL_INTERVAL" envDefault:"2s"`
+	WebhookBatchSize       int           `env:"WEBHOOK_BATCH_SIZE" envDefault:"50"`
+	WebhookConcurrency     int           `env:"WEBHOOK_CONCURRENCY" envDefault:"8"`
+	WebhookTimeout         time.Duration `env:"WEBHOOK_TIMEOUT" envDefault:"10s"`
+	WebhookMaxAttempts     int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	WebhookBackoffBase     time.Duration `env:"WEBHOOK_BACKOFF_BASE" envDefault:"30s"`
+	WebhookBackoffMax      time.Duration `env:"WEBHOOK_BACKOFF_MAX" envDefault:"6h"`
+
+	// Archival of old terminal and soft-deleted bookings
+	ArchiveEnabled   bool          `env:"ARCHIVE_ENABLED" envDefault:"true"`
+	ArchiveAfterDays int           `env:"ARCHIVE_AFTER_DAYS" envDefault:"180"`
+	ArchiveInterval  time.Duration `env:"ARCHIVE_INTERVAL" envDefault:"1h"`
+	ArchiveBatchSize int           `env:"ARCHIVE_BATCH_SIZE" envDefault:"500"`
+
+	// Reporting rollups and the zone reports bucket days in by default
+	ReportRefreshEnabled  bool          `env:"REPORT_REFRESH_ENABLED" envDefault:"true"`
+	ReportRefreshInterval time.Duration `env:"REPORT_REFRESH_INTERVAL" envDefault:"5m"`
+	ReportRefreshOverlap  time.Duration `env:"REPORT_REFRESH_OVERLAP" envDefault:"5m"`
+	ReportTimeZone        string        `env:"REPORT_TIME_ZONE" envDefault:"Asia/Jakarta"`
+
+	// Settlement reconciliation; files are read from SETTLEMENT_DIR as
+	// <provider>/<name>.csv. Bookings paid within the lookback must be settled
+	// once the grace period has passed
+	ReconcileEnabled  bool          `env:"RECONCILE_ENABLED" envDefault:"true"`
+	SettlementDir     string        `env:"SETTLEMENT_DIR"`
+	ReconcileInterval time.Duration `env:"RECONCILE_INTERVAL" envDefault:"1h"`
+	ReconcileGrace    time.Duration `env:"RECONCILE_GRACE" envDefault:"72h"`
+	ReconcileLookback time.Duration `env:"RECONCILE_LOOKBACK" envDefault:"720h"`
 }
 
 // LoadBookingConfig loads booking service configuration
@@ -25,5 +115,9 @@ func LoadBookingConfig() (*BookingConfig, error) {
 		Config: &baseConfig,
 	}
 
+	if err := env.Parse(bookingConfig); err != nil {
+		return nil, err
+	}
+
 	return bookingConfig, nil
 }
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	bookingv1 "github.com/ibnuzaman/porta-pay/services/booking/api/proto/booking/v1"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+// BookingServer adapts service.BookingService to the generated gRPC interface.
+type BookingServer struct {
+	bookingv1.UnimplementedBookingServiceServer
+
+	bookingService service.BookingService
+}
+
+func NewBookingServer(bookingService service.BookingService) *BookingServer {
+	return &BookingServer{
+		bookingService: bookingService,
+	}
+}
+
+func (s *BookingServer) CreateBooking(ctx context.Context, req *bookingv1.CreateBookingRequest) (*bookingv1.CreateBookingResponse, error) {
+	booking := &entity.Booking{
+		UserID:      req.GetUserId(),
+		RouteID:     req.GetRouteId(),
+		Qty:         int(req.GetQty()),
+		PriceTotal:  fromProtoMoney(req.GetPriceTotal()),
+		DepartureAt: fromProtoTime(req.GetDepartureAt()),
+		Passengers:  fromProtoPassengers(req.GetPassengers()),
+	}
+
+	if err := s.bookingService.CreateBooking(ctx, booking); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &bookingv1.CreateBookingResponse{Booking: toProtoBooking(booking)}, nil
+}
+
+func (s *BookingServer) GetBooking(ctx context.Context, req *bookingv1.GetBookingRequest) (*bookingv1.GetBookingResponse, error) {
+	booking, err := s.bookingService.GetBooking(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &bookingv1.GetBookingResponse{Booking: toProtoBooking(booking)}, nil
+}
+
+func (s *BookingServer) UpdateBooking(ctx context.Context, req *bookingv1.UpdateBookingRequest) (*bookingv1.UpdateBookingResponse, error) {
+	booking := fromProtoBooking(req.GetBooking())
+	if err := s.bookingService.UpdateBooking(ctx, booking); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &bookingv1.UpdateBookingResponse{Booking: toProtoBooking(booking)}, nil
+}
+
+func (s *BookingServer) CancelBooking(ctx context.Context, req *bookingv1.CancelBookingRequest) (*bookingv1.CancelBookingResponse, error) {
+	cancellation, err := s.bookingService.CancelBooking(ctx, req.GetId(), entity.CancellationReason(req.GetReason()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &bookingv1.CancelBookingResponse{
+		BookingId:     cancellation.BookingID,
+		Reason:        string(cancellation.Reason),
+		RefundPercent: int32(cancellation.RefundPercent),
+		RefundAmount:  toProtoMoney(cancellation.RefundAmount),
+		CancelledAt:   timestamppb.New(cancellation.CancelledAt),
+	}, nil
+}
+
+func (s *BookingServer) CancelPassenger(ctx context.Context, req *bookingv1.CancelPassengerRequest) (*bookingv1.CancelPassengerResponse, error) {
+	cancellation, err := s.bookingService.CancelPassenger(ctx, req.GetBookingId(), req.GetPassengerId(), entity.CancellationReason(req.GetReason()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &bookingv1.CancelPassengerResponse{
+		BookingId:     cancellation.BookingID,
+		PassengerId:   req.GetPassengerId(),
+		Reason:        string(cancellation.Reason),
+		RefundPercent: int32(cancellation.RefundPercent),
+		RefundAmount:  toProtoMoney(cancellation.RefundAmount),
+		CancelledAt:   timestamppb.New(cancellation.CancelledAt),
+	}, nil
+}
+
+func (s *BookingServer) ListBookings(ctx context.Context, req *bookingv1.ListBookingsRequest) (*bookingv1.ListBookingsResponse, error) {
+	filter := entity.BookingFilter{
+		UserID:  req.GetUserId(),
+		RouteID: req.GetRouteId(),
+		Status:  statusFromProto[req.GetStatus()],
+	}
+	if req.GetCreatedFrom() != nil {
+		from := req.GetCreatedFrom().AsTime()
+		filter.CreatedFrom = &from
+	}
+	if req.GetCreatedTo() != nil {
+		to := req.GetCreatedTo().AsTime()
+		filter.CreatedTo = &to
+	}
+
+	bookings, err := s.bookingService.ListBookings(ctx, filter, int(req.GetLimit()), int(req.GetOffset()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &bookingv1.ListBookingsResponse{Bookings: make([]*bookingv1.Booking, 0, len(bookings))}
+	for _, booking := range bookings {
+		resp.Bookings = append(resp.Bookings, toProtoBooking(booking))
+	}
+
+	return resp, nil
+}
+
+func (s *BookingServer) GetBookingHistory(ctx context.Context, req *bookingv1.GetBookingHistoryRequest) (*bookingv1.GetBookingHistoryResponse, error) {
+	history, err := s.bookingService.GetBookingHistory(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &bookingv1.GetBookingHistoryResponse{Entries: make([]*bookingv1.BookingHistoryEntry, 0, len(history))}
+	for _, entry := range history {
+		pbEntry, err := toProtoHistoryEntry(entry)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		resp.Entries = append(resp.Entries, pbEntry)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+// toStatus maps domain errors to gRPC status codes, mirroring the HTTP mapping.
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, apperrors.ErrBookingNotFound),
+		errors.Is(err, apperrors.ErrPassengerNotFound),
+		errors.Is(err, apperrors.ErrTenantNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, apperrors.ErrInvalidBookingID),
+		errors.Is(err, apperrors.ErrInvalidQuantity),
+		errors.Is(err, apperrors.ErrInvalidCancelReason),
+		errors.Is(err, apperrors.ErrDepartureInPast),
+		errors.Is(err, apperrors.ErrInvalidPrice),
+		errors.Is(err, apperrors.ErrCurrencyChanged),
+		errors.Is(err, apperrors.ErrInvalidStatus),
+		errors.Is(err, apperrors.ErrInvalidPassengers),
+		errors.Is(err, apperrors.ErrInvalidSeats),
+		errors.Is(err, apperrors.ErrInvalidPromoCode),
+		errors.Is(err, money.ErrCurrencyMismatch),
+		errors.Is(err, money.ErrUnknownCurrency),
+		errors.Is(err, money.ErrInvalidAmount):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, apperrors.ErrBookingNotCancellable),
+		errors.Is(err, apperrors.ErrBookingArchived),
+		errors.Is(err, apperrors.ErrInvalidStatusTransition),
+		errors.Is(err, apperrors.ErrCancellationWindowClosed),
+		errors.Is(err, apperrors.ErrBookingConfirmed),
+		errors.Is(err, apperrors.ErrBookingExpired),
+		errors.Is(err, apperrors.ErrPassengerCountLocked),
+		errors.Is(err, apperrors.ErrPassengerAlreadyCancelled),
+		errors.Is(err, apperrors.ErrPassengerOfPaidBooking),
+		errors.Is(err, apperrors.ErrPromoCodeExhausted),
+		errors.Is(err, apperrors.ErrForeignRoute):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, apperrors.ErrSeatUnavailable):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return internalError{err}
+	}
+}
+
+// internalError answers an unexpected error with a generic message, which
+// leaks nothing of the database or other internals, while its Error still
+// tells the real cause to the logging interceptor.
+type internalError struct {
+	err error
+}
+
+func (e internalError) Error() string { return e.err.Error() }
+
+func (e internalError) Unwrap() error { return e.err }
+
+func (e internalError) GRPCStatus() *status.Status {
+	return status.New(codes.Internal, "internal error")
+}
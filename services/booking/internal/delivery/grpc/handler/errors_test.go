@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{apperrors.ErrBookingNotFound, codes.NotFound},
+		{apperrors.ErrTenantNotFound, codes.NotFound},
+		{apperrors.ErrInvalidPromoCode, codes.InvalidArgument},
+		{fmt.Errorf("%w: IDR and USD", money.ErrCurrencyMismatch), codes.InvalidArgument},
+		{apperrors.ErrPromoCodeExhausted, codes.FailedPrecondition},
+		{apperrors.ErrBookingArchived, codes.FailedPrecondition},
+		{apperrors.ErrSeatUnavailable, codes.AlreadyExists},
+	}
+	for _, tt := range tests {
+		if got := status.Code(toStatus(tt.err)); got != tt.code {
+			t.Errorf("toStatus(%v) = %v, want %v", tt.err, got, tt.code)
+		}
+	}
+}
+
+func TestToStatusHidesInternalErrors(t *testing.T) {
+	cause := errors.New(`pq: relation "bookings" does not exist`)
+	err := toStatus(cause)
+
+	st, _ := status.FromError(err)
+	if st.Code() != codes.Internal || st.Message() != "internal error" {
+		t.Errorf("status = %v %q, want Internal with a generic message", st.Code(), st.Message())
+	}
+	// The logging interceptor still sees the cause
+	if !errors.Is(err, cause) || err.Error() != cause.Error() {
+		t.Errorf("error = %v, want the cause", err)
+	}
+}
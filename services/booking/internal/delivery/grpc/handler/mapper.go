@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/json"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	bookingv1 "github.com/ibnuzaman/porta-pay/services/booking/api/proto/booking/v1"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+var statusToProto = map[entity.BookingStatus]bookingv1.BookingStatus{
+	entity.StatusCreated:   bookingv1.BookingStatus_BOOKING_STATUS_CREATED,
+	entity.StatusPaid:      bookingv1.BookingStatus_BOOKING_STATUS_PAID,
+	entity.StatusConfirmed: bookingv1.BookingStatus_BOOKING_STATUS_CONFIRMED,
+	entity.StatusExpired:   bookingv1.BookingStatus_BOOKING_STATUS_EXPIRED,
+	entity.StatusCancelled: bookingv1.BookingStatus_BOOKING_STATUS_CANCELLED,
+	entity.StatusRefunded:  bookingv1.BookingStatus_BOOKING_STATUS_REFUNDED,
+}
+
+var statusFromProto = func() map[bookingv1.BookingStatus]entity.BookingStatus {
+	m := make(map[bookingv1.BookingStatus]entity.BookingStatus, len(statusToProto))
+	for k, v := range statusToProto {
+		m[v] = k
+	}
+	return m
+}()
+
+func toProtoMoney(m money.Money) *bookingv1.Money {
+	return &bookingv1.Money{Amount: m.Amount(), Currency: string(m.Currency())}
+}
+
+// fromProtoMoney leaves invalid currencies for the use case to reject.
+func fromProtoMoney(m *bookingv1.Money) money.Money {
+	if m == nil {
+		return money.Money{}
+	}
+	parsed, err := money.New(m.GetAmount(), money.Currency(m.GetCurrency()))
+	if err != nil {
+		return money.Money{}
+	}
+	return parsed
+}
+
+func toProtoTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func fromProtoTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func toProtoBooking(b *entity.Booking) *bookingv1.Booking {
+	return &bookingv1.Booking{
+		Id:           b.ID,
+		UserId:       b.UserID,
+		RouteId:      b.RouteID,
+		Qty:          int32(b.Qty),
+		Status:       statusToProto[b.Status],
+		PriceTotal:   toProtoMoney(b.PriceTotal),
+		DepartureAt:  toProtoTime(b.DepartureAt),
+		CancelReason: string(b.CancelReason),
+		CancelledAt:  toProtoTime(b.CancelledAt),
+		CreatedAt:    timestamppb.New(b.CreatedAt),
+		UpdatedAt:    timestamppb.New(b.UpdatedAt),
+		Passengers:   toProtoPassengers(b.Passengers),
+	}
+}
+
+// toProtoPassengers leaves out identity documents, which only manifests carry.
+func toProtoPassengers(passengers []*entity.Passenger) []*bookingv1.Passenger {
+	out := make([]*bookingv1.Passenger, len(passengers))
+	for i, p := range passengers {
+		out[i] = &bookingv1.Passenger{
+			Id:           p.ID,
+			FullName:     p.FullName,
+			DocumentType: string(p.DocumentType),
+			Seat:         p.Seat,
+			Status:       string(p.Status),
+			CancelReason: string(p.CancelReason),
+			CancelledAt:  toProtoTime(p.CancelledAt),
+		}
+	}
+	return out
+}
+
+// fromProtoPassengers leaves unparsable dates zero for the use case to reject.
+func fromProtoPassengers(passengers []*bookingv1.Passenger) []*entity.Passenger {
+	if len(passengers) == 0 {
+		return nil
+	}
+
+	out := make([]*entity.Passenger, len(passengers))
+	for i, p := range passengers {
+		dob, _ := entity.ParseDate(p.GetDateOfBirth())
+		out[i] = &entity.Passenger{
+			FullName:       p.GetFullName(),
+			DocumentType:   entity.DocumentType(p.GetDocumentType()),
+			DocumentNumber: p.GetDocumentNumber(),
+			DateOfBirth:    dob,
+			Seat:           p.GetSeat(),
+		}
+	}
+	return out
+}
+
+func fromProtoBooking(b *bookingv1.Booking) *entity.Booking {
+	return &entity.Booking{
+		ID:          b.GetId(),
+		UserID:      b.GetUserId(),
+		RouteID:     b.GetRouteId(),
+		Qty:         int(b.GetQty()),
+		Status:      statusFromProto[b.GetStatus()],
+		PriceTotal:  fromProtoMoney(b.GetPriceTotal()),
+		DepartureAt: fromProtoTime(b.GetDepartureAt()),
+	}
+}
+
+func toProtoHistoryEntry(e *entity.BookingHistoryEntry) (*bookingv1.BookingHistoryEntry, error) {
+	changes := make(map[string]*bookingv1.FieldChange, len(e.Changes))
+	for field, change := range e.Changes {
+		oldJSON, err := marshalValue(change.Old)
+		if err != nil {
+			return nil, err
+		}
+		newJSON, err := marshalValue(change.New)
+		if err != nil {
+			return nil, err
+		}
+		changes[field] = &bookingv1.FieldChange{OldJson: oldJSON, NewJson: newJSON}
+	}
+
+	return &bookingv1.BookingHistoryEntry{
+		Id:        e.ID,
+		BookingId: e.BookingID,
+		Action:    e.Action,
+		OldStatus: statusToProto[e.OldStatus],
+		NewStatus: statusToProto[e.NewStatus],
+		Changes:   changes,
+		Actor:     e.Actor,
+		RequestId: e.RequestID,
+		CreatedAt: timestamppb.New(e.CreatedAt),
+	}, nil
+}
+
+func marshalValue(v interface{}) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(v)
+	return string(data), err
+}
@@ -0,0 +1,156 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+)
+
+const (
+	// ActorMetadataKey identifies the caller for the audit trail
+	ActorMetadataKey = "x-actor"
+	// RequestIDMetadataKey carries the caller's request ID, if any
+	RequestIDMetadataKey = "x-request-id"
+	// IdempotencyKeyMetadataKey lets clients retry creates safely
+	IdempotencyKeyMetadataKey = "idempotency-key"
+	// TenantMetadataKey names the tenant internal callers act for
+	TenantMetadataKey = "x-tenant-id"
+	// AuthorizationMetadataKey carries "Bearer <token>" of internal callers
+	AuthorizationMetadataKey = "authorization"
+)
+
+// RequestContext copies the request ID and actor from incoming metadata into
+// the context read by the use cases, generating a request ID when absent.
+func RequestContext() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		requestID := first(md, RequestIDMetadataKey)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		ctx = reqctx.WithRequestID(ctx, requestID)
+		// The actor is unverified, so it cannot claim a credentialed role
+		if actor := reqctx.ClaimedActor(first(md, ActorMetadataKey)); actor != "" {
+			ctx = reqctx.WithActor(ctx, actor)
+		}
+		if key := first(md, IdempotencyKeyMetadataKey); key != "" {
+			ctx = reqctx.WithIdempotencyKey(ctx, key)
+		}
+
+		grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadataKey, requestID))
+
+		return handler(ctx, req)
+	}
+}
+
+// ResolveTenant scopes calls to a tenant like its HTTP namesake: the one
+// whose token, from the "tenant:token" pairs of tenantTokens, the call bears,
+// else fallback. Only callers bearing one of the internal "name:token" pairs
+// of internalTokens may name a tenant with TenantMetadataKey, or name none to
+// act across tenants. Metadata naming another tenant than the tenant token, or
+// than fallback from anybody else, is refused. Health checks need no tenant.
+func ResolveTenant(internalTokens, tenantTokens map[string]string, fallback string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, "/grpc.health.v1.Health/") {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		named := first(md, TenantMetadataKey)
+		if bearer, ok := strings.CutPrefix(first(md, AuthorizationMetadataKey), "Bearer "); ok && bearer != "" {
+			if _, ok := tokenHolder(bearer, internalTokens); ok {
+				if named == "" {
+					named = reqctx.AllTenants
+				}
+				return handler(reqctx.WithTenant(ctx, named), req)
+			}
+			if tenant, ok := tokenHolder(bearer, tenantTokens); ok {
+				if named != "" && named != tenant {
+					return nil, status.Error(codes.PermissionDenied, "token is not valid for tenant "+named)
+				}
+				return handler(reqctx.WithTenant(ctx, tenant), req)
+			}
+		}
+
+		if named != "" && named != fallback {
+			return nil, status.Error(codes.PermissionDenied, TenantMetadataKey+" needs internal credentials or the tenant's own token")
+		}
+		return handler(reqctx.WithTenant(ctx, fallback), req)
+	}
+}
+
+// Logger logs every call with its status code and duration.
+func Logger(log zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		event := log.Info()
+		if code == codes.Internal || code == codes.Unknown {
+			event = log.Error().Err(err)
+		}
+		event.
+			Str("method", info.FullMethod).
+			Str("code", code.String()).
+			Str("request_id", reqctx.RequestID(ctx)).
+			Dur("duration", time.Since(start)).
+			Msg("gRPC request")
+
+		return resp, err
+	}
+}
+
+// Recoverer turns panics into codes.Internal instead of crashing the process.
+func Recoverer(log zerolog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error().
+					Interface("panic", r).
+					Str("method", info.FullMethod).
+					Bytes("stack", debug.Stack()).
+					Msg("gRPC handler panicked")
+				err = status.Error(codes.Internal, "internal error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// tokenHolder returns the name under which tokens holds bearer.
+func tokenHolder(bearer string, tokens map[string]string) (string, bool) {
+	for name, token := range tokens {
+		if subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func first(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
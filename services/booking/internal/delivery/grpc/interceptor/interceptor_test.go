@@ -0,0 +1,56 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+)
+
+func TestTenantMetadataIsTrustedOnlyFromInternalCallers(t *testing.T) {
+	resolve := ResolveTenant(map[string]string{"payment": "internal-secret"}, map[string]string{"acme": "acme-secret"}, "default")
+	info := &grpc.UnaryServerInfo{FullMethod: "/booking.v1.BookingService/GetBooking"}
+
+	tests := []struct {
+		name   string
+		bearer string
+		tenant string
+		want   codes.Code
+		scope  string
+	}{
+		{"anonymous", "", "", codes.OK, "default"},
+		{"anonymous naming the default", "", "default", codes.OK, "default"},
+		{"anonymous naming another tenant", "", "acme", codes.PermissionDenied, ""},
+		{"anonymous naming every tenant", "", reqctx.AllTenants, codes.PermissionDenied, ""},
+		{"tenant token", "acme-secret", "", codes.OK, "acme"},
+		{"tenant token with another tenant", "acme-secret", "globex", codes.PermissionDenied, ""},
+		{"internal naming a tenant", "internal-secret", "globex", codes.OK, "globex"},
+		{"internal without a tenant", "internal-secret", "", codes.OK, reqctx.AllTenants},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := metadata.MD{}
+			if tt.bearer != "" {
+				md.Set(AuthorizationMetadataKey, "Bearer "+tt.bearer)
+			}
+			if tt.tenant != "" {
+				md.Set(TenantMetadataKey, tt.tenant)
+			}
+			ctx := metadata.NewIncomingContext(context.Background(), md)
+
+			var scope string
+			_, err := resolve(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				scope = reqctx.Tenant(ctx)
+				return nil, nil
+			})
+			if status.Code(err) != tt.want || scope != tt.scope {
+				t.Errorf("got %v with tenant %q, want %v with %q", status.Code(err), scope, tt.want, tt.scope)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"context"
+	"net"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	bookingv1 "github.com/ibnuzaman/porta-pay/services/booking/api/proto/booking/v1"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/delivery/grpc/interceptor"
+)
+
+// Server is the booking gRPC server with health and reflection services.
+type Server struct {
+	grpcServer *grpc.Server
+	health     *health.Server
+}
+
+// NewBookingServer serves bookingServer, scoping calls to the tenant of the
+// tenantTokens they bear, else defaultTenant; callers bearing one of the
+// internal "name:token" pairs of internalTokens may name any tenant.
+func NewBookingServer(bookingServer bookingv1.BookingServiceServer, internalTokens, tenantTokens map[string]string, defaultTenant string, log zerolog.Logger) *Server {
+	grpcServer := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(
+			interceptor.Recoverer(log),
+			interceptor.RequestContext(),
+			interceptor.Logger(log),
+			interceptor.ResolveTenant(internalTokens, tenantTokens, defaultTenant),
+		),
+	)
+
+	bookingv1.RegisterBookingServiceServer(grpcServer, bookingServer)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(bookingv1.BookingService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+
+	reflection.Register(grpcServer)
+
+	return &Server{
+		grpcServer: grpcServer,
+		health:     healthServer,
+	}
+}
+
+// Serve accepts connections on lis until Shutdown is called.
+func (s *Server) Serve(lis net.Listener) error {
+	return s.grpcServer.Serve(lis)
+}
+
+// Shutdown marks the server NOT_SERVING and waits for in-flight calls to
+// finish, forcing a stop when ctx expires.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
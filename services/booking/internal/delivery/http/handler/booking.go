@@ -2,8 +2,10 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/ibnuzaman/porta-pay/pkg/response"
@@ -29,7 +31,7 @@ func (h *BookingHandler) CreateBooking(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.bookingService.CreateBooking(r.Context(), &booking); err != nil {
-		response.InternalServerError(w, err.Error())
+		writeError(w, err)
 		return
 	}
 
@@ -69,13 +71,17 @@ func (h *BookingHandler) UpdateBooking(w http.ResponseWriter, r *http.Request) {
 
 	booking.ID = id
 	if err := h.bookingService.UpdateBooking(r.Context(), &booking); err != nil {
-		response.InternalServerError(w, err.Error())
+		writeError(w, err)
 		return
 	}
 
 	response.Success(w, http.StatusOK, booking)
 }
 
+type cancelBookingRequest struct {
+	Reason entity.CancellationReason `json:"reason"`
+}
+
 func (h *BookingHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -84,12 +90,51 @@ func (h *BookingHandler) CancelBooking(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.bookingService.CancelBooking(r.Context(), id); err != nil {
-		response.InternalServerError(w, err.Error())
+	// The reason may come from the query string or an optional JSON body.
+	req := cancelBookingRequest{Reason: entity.CancellationReason(r.URL.Query().Get("reason"))}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "Invalid JSON")
+			return
+		}
+	}
+
+	cancellation, err := h.bookingService.CancelBooking(r.Context(), id, req.Reason)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, cancellation)
+}
+
+func (h *BookingHandler) CancelPassenger(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid ID")
+		return
+	}
+	passengerID, err := strconv.ParseInt(chi.URLParam(r, "passengerID"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid passenger ID")
+		return
+	}
+
+	req := cancelBookingRequest{Reason: entity.CancellationReason(r.URL.Query().Get("reason"))}
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "Invalid JSON")
+			return
+		}
+	}
+
+	cancellation, err := h.bookingService.CancelPassenger(r.Context(), id, passengerID, req.Reason)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
-	response.Success(w, http.StatusOK, map[string]string{"message": "Booking cancelled successfully"})
+	response.Success(w, http.StatusOK, cancellation)
 }
 
 func (h *BookingHandler) ListBookings(w http.ResponseWriter, r *http.Request) {
@@ -111,15 +156,38 @@ func (h *BookingHandler) ListBookings(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	bookings, err := h.bookingService.ListBookings(r.Context(), limit, offset)
+	filter, err := parseBookingFilter(r)
 	if err != nil {
-		response.InternalServerError(w, err.Error())
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	bookings, err := h.bookingService.ListBookings(r.Context(), filter, limit, offset)
+	if err != nil {
+		writeError(w, err)
 		return
 	}
 
 	response.Success(w, http.StatusOK, bookings)
 }
 
+func (h *BookingHandler) GetBookingHistory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid ID")
+		return
+	}
+
+	history, err := h.bookingService.GetBookingHistory(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, history)
+}
+
 func (h *BookingHandler) Health(w http.ResponseWriter, r *http.Request) {
 	healthData := map[string]string{
 		"status":  "ok",
@@ -128,3 +196,41 @@ func (h *BookingHandler) Health(w http.ResponseWriter, r *http.Request) {
 
 	response.Success(w, http.StatusOK, healthData)
 }
+
+// parseBookingFilter reads the optional list filters from the query string.
+// Dates are RFC 3339 timestamps.
+func parseBookingFilter(r *http.Request) (entity.BookingFilter, error) {
+	query := r.URL.Query()
+	filter := entity.BookingFilter{Status: entity.BookingStatus(query.Get("status"))}
+
+	var err error
+	if v := query.Get("user_id"); v != "" {
+		if filter.UserID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return filter, errors.New("invalid user_id")
+		}
+	}
+	if v := query.Get("route_id"); v != "" {
+		if filter.RouteID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return filter, errors.New("invalid route_id")
+		}
+	}
+	if filter.CreatedFrom, err = parseTimeParam(query.Get("created_from")); err != nil {
+		return filter, errors.New("invalid created_from: expected RFC 3339 timestamp")
+	}
+	if filter.CreatedTo, err = parseTimeParam(query.Get("created_to")); err != nil {
+		return filter, errors.New("invalid created_to: expected RFC 3339 timestamp")
+	}
+
+	return filter, nil
+}
+
+func parseTimeParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
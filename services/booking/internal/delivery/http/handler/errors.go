@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+)
+
+// writeError maps domain errors to HTTP responses.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrBookingNotFound),
+		errors.Is(err, apperrors.ErrWebhookSubscriptionNotFound),
+		errors.Is(err, apperrors.ErrWebhookDeliveryNotFound),
+		errors.Is(err, apperrors.ErrHoldNotFound),
+		errors.Is(err, apperrors.ErrPassengerNotFound),
+		errors.Is(err, apperrors.ErrTicketNotFound),
+		errors.Is(err, apperrors.ErrPromotionNotFound),
+		errors.Is(err, apperrors.ErrTenantNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, apperrors.ErrInvalidBookingID),
+		errors.Is(err, apperrors.ErrInvalidQuantity),
+		errors.Is(err, apperrors.ErrInvalidCancelReason),
+		errors.Is(err, apperrors.ErrDepartureInPast),
+		errors.Is(err, apperrors.ErrInvalidPrice),
+		errors.Is(err, apperrors.ErrCurrencyChanged),
+		errors.Is(err, apperrors.ErrInvalidStatus),
+		errors.Is(err, apperrors.ErrInvalidPaymentEvent),
+		errors.Is(err, apperrors.ErrInvalidWebhookSubscription),
+		errors.Is(err, apperrors.ErrInvalidSeats),
+		errors.Is(err, apperrors.ErrInvalidPassengers),
+		errors.Is(err, apperrors.ErrInvalidManifestFilter),
+		errors.Is(err, apperrors.ErrInvalidManifestFormat),
+		errors.Is(err, apperrors.ErrInvalidExportFormat),
+		errors.Is(err, apperrors.ErrInvalidImportFormat),
+		errors.Is(err, apperrors.ErrInvalidImportMode),
+		errors.Is(err, apperrors.ErrInvalidImport),
+		errors.Is(err, apperrors.ErrImportTooLarge),
+		errors.Is(err, apperrors.ErrInvalidCheckIn),
+		errors.Is(err, apperrors.ErrInvalidReportQuery),
+		errors.Is(err, apperrors.ErrInvalidDiscrepancyFilter),
+		errors.Is(err, apperrors.ErrInvalidPromotion),
+		errors.Is(err, apperrors.ErrInvalidPromoCode),
+		errors.Is(err, apperrors.ErrInvalidTenant),
+		errors.Is(err, money.ErrCurrencyMismatch):
+		response.BadRequest(w, err.Error())
+	case errors.Is(err, apperrors.ErrBookingNotCancellable),
+		errors.Is(err, apperrors.ErrBookingArchived),
+		errors.Is(err, apperrors.ErrInvalidStatusTransition),
+		errors.Is(err, apperrors.ErrCancellationWindowClosed),
+		errors.Is(err, apperrors.ErrBookingConfirmed),
+		errors.Is(err, apperrors.ErrBookingExpired),
+		errors.Is(err, apperrors.ErrHoldAlreadyExtended),
+		errors.Is(err, apperrors.ErrSeatUnavailable),
+		errors.Is(err, apperrors.ErrPassengerCountLocked),
+		errors.Is(err, apperrors.ErrPassengerAlreadyCancelled),
+		errors.Is(err, apperrors.ErrPassengerOfPaidBooking),
+		errors.Is(err, apperrors.ErrTicketRevoked),
+		errors.Is(err, apperrors.ErrPromotionCodeExists),
+		errors.Is(err, apperrors.ErrPromoCodeExhausted),
+		errors.Is(err, apperrors.ErrTenantExists),
+		errors.Is(err, apperrors.ErrForeignRoute):
+		response.Conflict(w, err.Error())
+	default:
+		response.InternalServerError(w, err.Error())
+	}
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/export"
+)
+
+type ExportHandler struct {
+	exportService service.ExportService
+}
+
+func NewExportHandler(exportService service.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// ExportBookings streams every booking matching the list filters as CSV (the
+// default) or NDJSON, oldest first.
+func (h *ExportHandler) ExportBookings(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseBookingFilter(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+
+	body := &trackingWriter{w: w}
+	ew, err := export.NewWriter(body, format)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Exports outlast the server write timeout; the request timeout still applies
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		writeError(w, err)
+		return
+	}
+
+	ext := map[string]string{export.FormatCSV: "csv", export.FormatNDJSON: "ndjson"}[format]
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="bookings-`+time.Now().UTC().Format("20060102T150405Z")+`.`+ext+`"`)
+
+	err = h.exportService.ExportBookings(r.Context(), filter, ew.Write)
+	if err == nil {
+		err = ew.Close()
+	}
+	if err != nil {
+		if !body.written {
+			w.Header().Del("Content-Disposition")
+			writeError(w, err)
+			return
+		}
+		// Part of the export is out; cut the connection so the client sees a
+		// truncated download rather than a short export.
+		panic(http.ErrAbortHandler)
+	}
+}
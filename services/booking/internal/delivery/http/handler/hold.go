@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type HoldHandler struct {
+	holdService service.HoldService
+}
+
+func NewHoldHandler(holdService service.HoldService) *HoldHandler {
+	return &HoldHandler{
+		holdService: holdService,
+	}
+}
+
+func (h *HoldHandler) CreateHold(w http.ResponseWriter, r *http.Request) {
+	var hold entity.Hold
+	if err := json.NewDecoder(r.Body).Decode(&hold); err != nil {
+		response.BadRequest(w, "Invalid JSON")
+		return
+	}
+
+	if err := h.holdService.CreateHold(r.Context(), &hold); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusCreated, hold)
+}
+
+func (h *HoldHandler) GetHold(w http.ResponseWriter, r *http.Request) {
+	hold, err := h.holdService.GetHold(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, hold)
+}
+
+func (h *HoldHandler) ExtendHold(w http.ResponseWriter, r *http.Request) {
+	hold, err := h.holdService.ExtendHold(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, hold)
+}
+
+type convertHoldRequest struct {
+	Passengers []*entity.Passenger `json:"passengers"`
+}
+
+func (h *HoldHandler) ConvertHold(w http.ResponseWriter, r *http.Request) {
+	// The body is optional; without it the booking has no passengers
+	var req convertHoldRequest
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "Invalid JSON")
+			return
+		}
+	}
+
+	booking, err := h.holdService.ConvertHold(r.Context(), chi.URLParam(r, "id"), req.Passengers)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusCreated, booking)
+}
+
+func (h *HoldHandler) ReleaseHold(w http.ResponseWriter, r *http.Request) {
+	if err := h.holdService.ReleaseHold(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/importer"
+)
+
+// maxImportBytes bounds an import file; row count is limited separately.
+const maxImportBytes = 10 << 20
+
+type ImportHandler struct {
+	importService service.ImportService
+}
+
+func NewImportHandler(importService service.ImportService) *ImportHandler {
+	return &ImportHandler{
+		importService: importService,
+	}
+}
+
+// ImportBookings creates the bookings of a CSV or NDJSON body and answers
+// with a per-row report. The format comes from the format parameter or else
+// the Content-Type; mode defaults to atomic.
+func (h *ImportHandler) ImportBookings(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = importer.FormatCSV
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/x-ndjson" {
+			format = importer.FormatNDJSON
+		}
+	}
+	mode := entity.ImportMode(query.Get("mode"))
+	if mode == "" {
+		mode = entity.ImportAtomic
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportBytes))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		response.Error(w, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", "import file exceeds 10 MB")
+		return
+	}
+	if err != nil {
+		response.BadRequest(w, "failed to read import file")
+		return
+	}
+
+	rows, err := importer.Read(bytes.NewReader(body), format)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	result, err := h.importService.ImportBookings(r.Context(), rows, mode)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, result)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ibnuzaman/porta-pay/pkg/ledger"
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type LedgerHandler struct {
+	ledgerService service.LedgerService
+}
+
+func NewLedgerHandler(ledgerService service.LedgerService) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerService: ledgerService,
+	}
+}
+
+// GetBalances returns balances per account and currency, for one account
+// with account and over one booking's entries with booking_id.
+func (h *LedgerHandler) GetBalances(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseLedgerFilter(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	balances, err := h.ledgerService.Balances(r.Context(), filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, balances)
+}
+
+// ListEntries lists entries newest first, those of one booking with
+// booking_id and those posting to one account with account.
+func (h *LedgerHandler) ListEntries(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseLedgerFilter(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	limit, offset := pageParams(r)
+	entries, err := h.ledgerService.ListEntries(r.Context(), filter, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, entries)
+}
+
+func parseLedgerFilter(r *http.Request) (ledger.Filter, error) {
+	values := r.URL.Query()
+	filter := ledger.Filter{Account: values.Get("account")}
+
+	if v := values.Get("booking_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			return filter, errors.New("invalid booking_id")
+		}
+		filter.BookingID = id
+	}
+
+	return filter, nil
+}
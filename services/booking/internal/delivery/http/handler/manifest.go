@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/manifest"
+)
+
+type ManifestHandler struct {
+	manifestService service.ManifestService
+}
+
+func NewManifestHandler(manifestService service.ManifestService) *ManifestHandler {
+	return &ManifestHandler{
+		manifestService: manifestService,
+	}
+}
+
+// GetManifest streams the passenger manifest of one departure as CSV (the
+// default), JSON or plain text.
+func (h *ManifestHandler) GetManifest(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	routeID, err := strconv.ParseInt(query.Get("route_id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "invalid route_id")
+		return
+	}
+	departureAt, err := time.Parse(time.RFC3339, query.Get("departure_at"))
+	if err != nil {
+		response.BadRequest(w, "invalid departure_at: expected RFC 3339 timestamp")
+		return
+	}
+	format := query.Get("format")
+	if format == "" {
+		format = manifest.FormatCSV
+	}
+
+	filter := entity.ManifestFilter{RouteID: routeID, DepartureAt: departureAt}
+	body := &trackingWriter{w: w}
+	mw, err := manifest.NewWriter(body, format, filter)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// Large departures outlast the server write timeout; the request timeout
+	// still applies
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", manifest.ContentType(format))
+	if format != manifest.FormatJSON {
+		ext := map[string]string{manifest.FormatCSV: "csv", manifest.FormatText: "txt"}[format]
+		filename := fmt.Sprintf("manifest-%d-%s.%s", routeID, departureAt.UTC().Format("20060102T1504Z"), ext)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	}
+
+	err = h.manifestService.StreamManifest(r.Context(), filter, mw.Write)
+	if err == nil {
+		err = mw.Close()
+	}
+	if err != nil {
+		if !body.written {
+			w.Header().Del("Content-Disposition")
+			writeError(w, err)
+			return
+		}
+		// Part of the manifest is out; cut the connection so the client sees
+		// a truncated download rather than a short manifest.
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// trackingWriter records whether any of the response body has been written.
+type trackingWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (t *trackingWriter) Write(p []byte) (int, error) {
+	if len(p) > 0 {
+		t.written = true
+	}
+	return t.w.Write(p)
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/pkg/webhook"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+// maxWebhookBody bounds provider payloads; real ones are a few hundred bytes.
+const maxWebhookBody = 64 << 10
+
+type PaymentWebhookHandler struct {
+	paymentService service.PaymentService
+	secrets        map[string]string
+	tolerance      time.Duration
+}
+
+// NewPaymentWebhookHandler accepts webhooks from the providers in secrets,
+// keyed by the provider name used in the URL.
+func NewPaymentWebhookHandler(paymentService service.PaymentService, secrets map[string]string, tolerance time.Duration) *PaymentWebhookHandler {
+	if tolerance <= 0 {
+		tolerance = webhook.DefaultTolerance
+	}
+
+	return &PaymentWebhookHandler{
+		paymentService: paymentService,
+		secrets:        secrets,
+		tolerance:      tolerance,
+	}
+}
+
+type paymentWebhookResponse struct {
+	EventID   string                `json:"event_id"`
+	Outcome   entity.PaymentOutcome `json:"outcome"`
+	Duplicate bool                  `json:"duplicate"`
+}
+
+func (h *PaymentWebhookHandler) HandlePayment(w http.ResponseWriter, r *http.Request) {
+	provider := chi.URLParam(r, "provider")
+	secret, ok := h.secrets[provider]
+	if !ok || secret == "" {
+		response.NotFound(w, "unknown payment provider")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Verify before parsing so unsigned input never reaches the decoder
+	if err := webhook.Verify([]byte(secret), r.Header.Get(webhook.SignatureHeader), body, h.tolerance, time.Now()); err != nil {
+		response.Unauthorized(w, err.Error())
+		return
+	}
+
+	var event entity.PaymentEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+	event.Provider = provider
+	event.Payload = body
+
+	ctx := reqctx.WithActor(r.Context(), "payment:"+provider)
+	recorded, err := h.paymentService.HandlePaymentEvent(ctx, &event)
+	duplicate := errors.Is(err, apperrors.ErrDuplicatePaymentEvent)
+	if err != nil && !duplicate {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, paymentWebhookResponse{
+		EventID:   recorded.EventID,
+		Outcome:   recorded.Outcome,
+		Duplicate: duplicate,
+	})
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type PromotionHandler struct {
+	promotionService service.PromotionService
+}
+
+func NewPromotionHandler(promotionService service.PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+	}
+}
+
+// promotionRequest is the settable part of a promotion. Code is ignored on
+// update.
+type promotionRequest struct {
+	Code           string               `json:"code"`
+	Description    string               `json:"description"`
+	Kind           entity.PromotionKind `json:"kind"`
+	Percent        int                  `json:"percent"`
+	Amount         *money.Money         `json:"amount"`
+	RouteIDs       []int64              `json:"route_ids"`
+	MinQty         int                  `json:"min_qty"`
+	MaxRedemptions *int                 `json:"max_redemptions"`
+	MaxPerUser     *int                 `json:"max_per_user"`
+	StartsAt       *time.Time           `json:"starts_at"`
+	EndsAt         *time.Time           `json:"ends_at"`
+	Disabled       bool                 `json:"disabled"`
+}
+
+// promotion builds the promotion, starting now unless starts_at says
+// otherwise.
+func (req *promotionRequest) promotion() *entity.Promotion {
+	promotion := &entity.Promotion{
+		Code:           req.Code,
+		Description:    req.Description,
+		Kind:           req.Kind,
+		Percent:        req.Percent,
+		Amount:         req.Amount,
+		RouteIDs:       req.RouteIDs,
+		MinQty:         req.MinQty,
+		MaxRedemptions: req.MaxRedemptions,
+		MaxPerUser:     req.MaxPerUser,
+		StartsAt:       time.Now(),
+		EndsAt:         req.EndsAt,
+		Disabled:       req.Disabled,
+	}
+	if req.StartsAt != nil {
+		promotion.StartsAt = *req.StartsAt
+	}
+
+	return promotion
+}
+
+func (h *PromotionHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req promotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON")
+		return
+	}
+
+	promotion := req.promotion()
+	if err := h.promotionService.CreatePromotion(r.Context(), promotion); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusCreated, promotion)
+}
+
+func (h *PromotionHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pageParams(r)
+	promotions, err := h.promotionService.ListPromotions(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, promotions)
+}
+
+func (h *PromotionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid ID")
+		return
+	}
+
+	promotion, err := h.promotionService.GetPromotion(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, promotion)
+}
+
+func (h *PromotionHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid ID")
+		return
+	}
+
+	var req promotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON")
+		return
+	}
+
+	promotion := req.promotion()
+	promotion.ID = id
+	updated, err := h.promotionService.UpdatePromotion(r.Context(), promotion)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, updated)
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type ReconciliationHandler struct {
+	reconciliationService service.ReconciliationService
+}
+
+func NewReconciliationHandler(reconciliationService service.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: reconciliationService,
+	}
+}
+
+// GetReport lists discrepancies, open ones unless status says resolved or
+// all, alongside the open counts and the last run.
+func (h *ReconciliationHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	values := r.URL.Query()
+	filter := entity.DiscrepancyFilter{
+		Kind:     entity.DiscrepancyKind(values.Get("kind")),
+		Provider: values.Get("provider"),
+	}
+	switch values.Get("status") {
+	case "", "open":
+		resolved := false
+		filter.Resolved = &resolved
+	case "resolved":
+		resolved := true
+		filter.Resolved = &resolved
+	case "all":
+	default:
+		writeError(w, apperrors.ErrInvalidDiscrepancyFilter)
+		return
+	}
+
+	limit, offset := pageParams(r)
+	report, err := h.reconciliationService.ReconciliationReport(r.Context(), filter, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, report)
+}
+
+// Run ingests new settlement files and reconciles now instead of waiting
+// for the background job.
+func (h *ReconciliationHandler) Run(w http.ResponseWriter, r *http.Request) {
+	run, err := h.reconciliationService.Reconcile(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, run)
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type ReportHandler struct {
+	reportService service.ReportService
+}
+
+func NewReportHandler(reportService service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+func (h *ReportHandler) RevenueReport(w http.ResponseWriter, r *http.Request) {
+	h.serveReport(w, r, h.reportService.RevenueReport)
+}
+
+func (h *ReportHandler) FunnelReport(w http.ResponseWriter, r *http.Request) {
+	h.serveReport(w, r, h.reportService.FunnelReport)
+}
+
+func (h *ReportHandler) StatusReport(w http.ResponseWriter, r *http.Request) {
+	h.serveReport(w, r, h.reportService.StatusReport)
+}
+
+// RefreshReports brings the rollups up to date now instead of waiting for
+// the background job; full=true rebuilds them from scratch.
+func (h *ReportHandler) RefreshReports(w http.ResponseWriter, r *http.Request) {
+	full, _ := strconv.ParseBool(r.URL.Query().Get("full"))
+
+	recomputed, err := h.reportService.RefreshReports(r.Context(), full)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, map[string]int{"recomputed_buckets": recomputed})
+}
+
+func (h *ReportHandler) serveReport(w http.ResponseWriter, r *http.Request, run func(context.Context, *entity.ReportQuery) (*entity.Report, error)) {
+	query, err := parseReportQuery(r)
+	if err != nil {
+		response.BadRequest(w, err.Error())
+		return
+	}
+
+	report, err := run(r.Context(), query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, report)
+}
+
+// parseReportQuery reads the report range and grouping from the query
+// string. Dates are YYYY-MM-DD in the tz time zone.
+func parseReportQuery(r *http.Request) (*entity.ReportQuery, error) {
+	values := r.URL.Query()
+	query := &entity.ReportQuery{Interval: entity.ReportInterval(values.Get("interval"))}
+
+	var err error
+	if v := values.Get("from"); v != "" {
+		if query.From, err = entity.ParseDate(v); err != nil {
+			return nil, errors.New("invalid from: expected YYYY-MM-DD")
+		}
+	}
+	if v := values.Get("to"); v != "" {
+		if query.To, err = entity.ParseDate(v); err != nil {
+			return nil, errors.New("invalid to: expected YYYY-MM-DD")
+		}
+	}
+	if v := values.Get("tz"); v != "" {
+		// Local names no zone the database knows
+		if query.Location, err = time.LoadLocation(v); err != nil || v == "Local" {
+			return nil, errors.New("invalid tz: expected IANA time zone")
+		}
+	}
+	if v := values.Get("route_id"); v != "" {
+		if query.RouteID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, errors.New("invalid route_id")
+		}
+	}
+	switch values.Get("group_by") {
+	case "":
+	case "route":
+		query.ByRoute = true
+	default:
+		return nil, errors.New("invalid group_by: expected route")
+	}
+
+	return query, nil
+}
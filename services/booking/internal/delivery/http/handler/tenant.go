@@ -0,0 +1,122 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type TenantHandler struct {
+	tenantService service.TenantService
+}
+
+func NewTenantHandler(tenantService service.TenantService) *TenantHandler {
+	return &TenantHandler{
+		tenantService: tenantService,
+	}
+}
+
+// tenantRequest is the settable part of a tenant. ID is ignored on update.
+type tenantRequest struct {
+	ID       string                `json:"id"`
+	Name     string                `json:"name"`
+	Settings entity.TenantSettings `json:"settings"`
+}
+
+func (h *TenantHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req tenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON")
+		return
+	}
+
+	tenant := &entity.Tenant{ID: req.ID, Name: req.Name, Settings: req.Settings}
+	if err := h.tenantService.CreateTenant(r.Context(), tenant); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusCreated, tenant)
+}
+
+func (h *TenantHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pageParams(r)
+	tenants, err := h.tenantService.ListTenants(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, tenants)
+}
+
+func (h *TenantHandler) Get(w http.ResponseWriter, r *http.Request) {
+	tenant, err := h.tenantService.GetTenant(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, tenant)
+}
+
+func (h *TenantHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var req tenantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON")
+		return
+	}
+
+	tenant := &entity.Tenant{ID: chi.URLParam(r, "id"), Name: req.Name, Settings: req.Settings}
+	updated, err := h.tenantService.UpdateTenant(r.Context(), tenant)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, updated)
+}
+
+func (h *TenantHandler) ListRoutes(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pageParams(r)
+	routes, err := h.tenantService.ListRoutes(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, routes)
+}
+
+// RegisterRoute registers the route to the request's tenant, renaming it
+// when the body names it.
+func (h *TenantHandler) RegisterRoute(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil || id <= 0 {
+		response.BadRequest(w, "Invalid ID")
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.BadRequest(w, "Invalid JSON")
+			return
+		}
+	}
+
+	route := &entity.Route{ID: id, Name: req.Name}
+	if err := h.tenantService.RegisterRoute(r.Context(), route); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, route)
+}
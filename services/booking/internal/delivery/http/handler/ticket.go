@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type TicketHandler struct {
+	ticketService service.TicketService
+}
+
+func NewTicketHandler(ticketService service.TicketService) *TicketHandler {
+	return &TicketHandler{
+		ticketService: ticketService,
+	}
+}
+
+func (h *TicketHandler) ListTickets(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid ID")
+		return
+	}
+
+	tickets, err := h.ticketService.ListTickets(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, tickets)
+}
+
+// GetTicketQRCode serves an issued ticket's QR code as a PNG image.
+func (h *TicketHandler) GetTicketQRCode(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid ID")
+		return
+	}
+
+	png, err := h.ticketService.TicketQRCode(r.Context(), id, chi.URLParam(r, "ticketID"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(png)
+}
+
+// CheckIn answers a gate scan. Denied scans are still 200 responses; the
+// result's reason tells the scanner why.
+func (h *TicketHandler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	var checkIn entity.CheckIn
+	if err := json.NewDecoder(r.Body).Decode(&checkIn); err != nil {
+		response.BadRequest(w, "Invalid JSON")
+		return
+	}
+
+	result, err := h.ticketService.CheckIn(r.Context(), &checkIn)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, result)
+}
+
+// PublicKeys lists the base64 Ed25519 keys scanners verify tokens with.
+func (h *TicketHandler) PublicKeys(w http.ResponseWriter, r *http.Request) {
+	keys := h.ticketService.VerificationKeys()
+	encoded := make([]string, len(keys))
+	for i, key := range keys {
+		encoded[i] = base64.StdEncoding.EncodeToString(key)
+	}
+
+	response.Success(w, http.StatusOK, map[string]interface{}{
+		"algorithm": "Ed25519",
+		"keys":      encoded,
+	})
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+type createSubscriptionRequest struct {
+	Partner     string   `json:"partner"`
+	Description string   `json:"description"`
+	URL         string   `json:"url"`
+	Secret      string   `json:"secret"`
+	EventTypes  []string `json:"event_types"`
+	UserID      *int64   `json:"user_id"`
+}
+
+func (h *WebhookHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	var req createSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON")
+		return
+	}
+
+	subscription := &entity.WebhookSubscription{
+		Partner:     req.Partner,
+		Description: req.Description,
+		URL:         req.URL,
+		Secret:      req.Secret,
+		EventTypes:  req.EventTypes,
+		UserID:      req.UserID,
+	}
+	if err := h.webhookService.CreateSubscription(r.Context(), subscription); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// The only response that carries the signing secret
+	response.Success(w, http.StatusCreated, subscription)
+}
+
+func (h *WebhookHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pageParams(r)
+	subscriptions, err := h.webhookService.ListSubscriptions(r.Context(), limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, subscriptions)
+}
+
+func (h *WebhookHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid ID")
+		return
+	}
+
+	subscription, err := h.webhookService.GetSubscription(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, subscription)
+}
+
+func (h *WebhookHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid ID")
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(r.Context(), id); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := entity.WebhookDeliveryFilter{
+		EventType: query.Get("event_type"),
+		Status:    entity.WebhookDeliveryStatus(query.Get("status")),
+	}
+
+	var err error
+	if v := query.Get("subscription_id"); v != "" {
+		if filter.SubscriptionID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			response.BadRequest(w, "invalid subscription_id")
+			return
+		}
+	}
+	if v := query.Get("booking_id"); v != "" {
+		if filter.BookingID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			response.BadRequest(w, "invalid booking_id")
+			return
+		}
+	}
+
+	limit, offset := pageParams(r)
+	deliveries, err := h.webhookService.ListDeliveries(r.Context(), filter, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid ID")
+		return
+	}
+
+	delivery, err := h.webhookService.GetDelivery(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, delivery)
+}
+
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid ID")
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusAccepted, delivery)
+}
+
+// pageParams reads limit and offset, leaving bad values to the use case defaults.
+func pageParams(r *http.Request) (int, int) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	return limit, offset
+}
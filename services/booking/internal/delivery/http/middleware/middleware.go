@@ -1,10 +1,33 @@
 package middleware
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+)
+
+const (
+	// ActorHeader identifies the caller for the audit trail
+	ActorHeader = "X-Actor"
+	// IdempotencyKeyHeader lets clients retry creates safely
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// TenantHeader names the tenant admins and other verified callers act for
+	TenantHeader = "X-Tenant-ID"
 )
 
 // CORS middleware
@@ -12,7 +35,7 @@ func CORS(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-CSRF-Token")
+		w.Header().Set("Access-Control-Allow-Headers", "Accept, Authorization, Content-Type, X-CSRF-Token, X-Actor, X-Tenant-ID, Idempotency-Key, traceparent, tracestate")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -31,10 +54,171 @@ func ContentTypeJSON(next http.Handler) http.Handler {
 	})
 }
 
+// RequestContext copies the request ID and the calling actor into the context
+// read by the use cases. The actor of ActorHeader is unverified, so it cannot
+// claim the roles of Authenticate.
+func RequestContext(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := reqctx.WithRequestID(r.Context(), middleware.GetReqID(r.Context()))
+		if actor := reqctx.ClaimedActor(r.Header.Get(ActorHeader)); actor != "" {
+			ctx = reqctx.WithActor(ctx, actor)
+		}
+		if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+			ctx = reqctx.WithIdempotencyKey(ctx, key)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequireAdmin admits requests bearing one of the admin tokens, keyed by the
+// name of their holder, and records the holder as the actor "admin:<name>".
+// With no tokens configured every request is refused.
+func RequireAdmin(tokens map[string]string) func(http.Handler) http.Handler {
+	return requireToken("admin", tokens)
+}
+
+// RequireGate admits gate scanners bearing one of the gate tokens and records
+// the scanner as the actor "gate:<name>". With no tokens configured every
+// request is refused.
+func RequireGate(tokens map[string]string) func(http.Handler) http.Handler {
+	return requireToken("gate", tokens)
+}
+
+func requireToken(role string, tokens map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || bearer == "" {
+				response.Unauthorized(w, role+" token required")
+				return
+			}
+
+			name, ok := tokenHolder(bearer, tokens)
+			if !ok {
+				response.Unauthorized(w, "invalid "+role+" token")
+				return
+			}
+			ctx := reqctx.WithActor(r.Context(), role+":"+name)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Authenticate records the holder of an admin or gate token as the actor
+// "admin:<name>" or "gate:<name>" on any route, in place of ActorHeader.
+// Requests without such a token pass unchanged; routes that need one still
+// use RequireAdmin or RequireGate.
+func Authenticate(adminTokens, gateTokens map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && bearer != "" {
+				if name, ok := tokenHolder(bearer, adminTokens); ok {
+					r = r.WithContext(reqctx.WithActor(r.Context(), "admin:"+name))
+				} else if name, ok := tokenHolder(bearer, gateTokens); ok {
+					r = r.WithContext(reqctx.WithActor(r.Context(), "gate:"+name))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// tokenHolder returns the name under which tokens holds bearer.
+func tokenHolder(bearer string, tokens map[string]string) (string, bool) {
+	for name, token := range tokens {
+		if subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// ResolveTenant scopes requests to a tenant: the one whose token, from the
+// "tenant:token" pairs of tokens, the request bears, else fallback. Only
+// callers Authenticate verified, such as admins, may name a tenant with
+// TenantHeader; a header naming another tenant than the token is refused, and
+// so is one anybody else sends naming another tenant than fallback, as are
+// tenants lookup does not know.
+func ResolveTenant(tokens map[string]string, fallback string, lookup func(ctx context.Context, id string) error) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get(TenantHeader)
+			tenant := ""
+			if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && bearer != "" {
+				tenant, _ = tokenHolder(bearer, tokens)
+			}
+
+			switch {
+			case tenant != "":
+				if header != "" && header != tenant {
+					response.Forbidden(w, "token is not valid for tenant "+header)
+					return
+				}
+			case header == "":
+				tenant = fallback
+			case reqctx.Credentialed(reqctx.Actor(r.Context())) || header == fallback:
+				tenant = header
+			default:
+				response.Forbidden(w, TenantHeader+" needs an admin token or the tenant's own token")
+				return
+			}
+
+			if err := lookup(r.Context(), tenant); errors.Is(err, apperrors.ErrTenantNotFound) {
+				response.NotFound(w, err.Error())
+				return
+			} else if err != nil {
+				response.InternalServerError(w, err.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(reqctx.WithTenant(r.Context(), tenant)))
+		})
+	}
+}
+
+// AcrossTenants lets platform endpoints, such as the ledger or the payment
+// provider callbacks, act across tenants. Requests that are not scoped, by it
+// or by ResolveTenant, see no tenant's data.
+func AcrossTenants(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(reqctx.WithTenant(r.Context(), reqctx.AllTenants)))
+	})
+}
+
+// Tracing continues the caller's trace from the request headers and records a
+// server span named after the matched route
+func Tracing(next http.Handler) http.Handler {
+	tracer := otel.Tracer("github.com/ibnuzaman/porta-pay/services/booking")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			span.SetName(r.Method + " " + rctx.RoutePattern())
+		}
+		span.SetAttributes(
+			attribute.String("http.method", r.Method),
+			attribute.String("http.target", r.URL.Path),
+			attribute.Int("http.status_code", ww.Status()),
+		)
+		if ww.Status() >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(ww.Status()))
+		}
+	})
+}
+
 // DefaultStack returns a set of common middleware
 func DefaultStack() []func(http.Handler) http.Handler {
 	return []func(http.Handler) http.Handler{
 		middleware.RequestID,
+		Tracing,
+		RequestContext,
 		middleware.RealIP,
 		middleware.Logger,
 		middleware.Recoverer,
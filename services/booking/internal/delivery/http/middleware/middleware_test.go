@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+)
+
+func TestActorIsTakenFromTokensOverTheHeader(t *testing.T) {
+	admins := map[string]string{"ops": "admin-secret"}
+	gates := map[string]string{"gate-3": "gate-secret"}
+
+	tests := []struct {
+		name   string
+		bearer string
+		actor  string
+		want   string
+	}{
+		{"header actor", "", "user:42", "user:42"},
+		{"no actor", "", "", reqctx.AnonymousActor},
+		{"admin token wins over the header", "admin-secret", "user:42", "admin:ops"},
+		{"gate token", "gate-secret", "", "gate:gate-3"},
+		{"unknown token keeps the header", "other", "user:42", "user:42"},
+		{"header cannot claim admin", "", "admin:ops", reqctx.AnonymousActor},
+		{"header cannot claim a gate", "", "gate:gate-3", reqctx.AnonymousActor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			h := RequestContext(Authenticate(admins, gates)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = reqctx.Actor(r.Context())
+			})))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			if tt.actor != "" {
+				req.Header.Set(ActorHeader, tt.actor)
+			}
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("actor = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenantHeaderIsTrustedOnlyFromVerifiedCallers(t *testing.T) {
+	admins := map[string]string{"ops": "admin-secret"}
+	tenants := map[string]string{"acme": "acme-secret"}
+
+	tests := []struct {
+		name   string
+		bearer string
+		header string
+		want   int
+		tenant string
+	}{
+		{"anonymous", "", "", http.StatusOK, "default"},
+		{"anonymous naming the default", "", "default", http.StatusOK, "default"},
+		{"anonymous naming another tenant", "", "acme", http.StatusForbidden, ""},
+		{"unknown token naming a tenant", "other", "acme", http.StatusForbidden, ""},
+		{"tenant token", "acme-secret", "", http.StatusOK, "acme"},
+		{"tenant token with its own header", "acme-secret", "acme", http.StatusOK, "acme"},
+		{"tenant token with another header", "acme-secret", "globex", http.StatusForbidden, ""},
+		{"admin naming a tenant", "admin-secret", "globex", http.StatusOK, "globex"},
+		{"admin without a header", "admin-secret", "", http.StatusOK, "default"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			resolve := ResolveTenant(tenants, "default", func(ctx context.Context, id string) error { return nil })
+			h := RequestContext(Authenticate(admins, nil)(resolve(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = reqctx.Tenant(r.Context())
+			}))))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			if tt.header != "" {
+				req.Header.Set(TenantHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, req)
+
+			if w.Code != tt.want || got != tt.tenant {
+				t.Errorf("got %d with tenant %q, want %d with %q", w.Code, got, tt.want, tt.tenant)
+			}
+		})
+	}
+}
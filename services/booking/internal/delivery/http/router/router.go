@@ -1,31 +1,147 @@
 package router
 
 import (
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
+	"github.com/ibnuzaman/porta-pay/services/booking/api"
 	"github.com/ibnuzaman/porta-pay/services/booking/internal/delivery/http/handler"
 	"github.com/ibnuzaman/porta-pay/services/booking/internal/delivery/http/middleware"
 )
 
-func NewBookingRouter(bookingHandler *handler.BookingHandler) chi.Router {
+func NewBookingRouter(
+	bookingHandler *handler.BookingHandler,
+	paymentWebhookHandler *handler.PaymentWebhookHandler,
+	webhookHandler *handler.WebhookHandler,
+	holdHandler *handler.HoldHandler,
+	manifestHandler *handler.ManifestHandler,
+	ticketHandler *handler.TicketHandler,
+	reportHandler *handler.ReportHandler,
+	exportHandler *handler.ExportHandler,
+	importHandler *handler.ImportHandler,
+	reconciliationHandler *handler.ReconciliationHandler,
+	ledgerHandler *handler.LedgerHandler,
+	promotionHandler *handler.PromotionHandler,
+	tenantHandler *handler.TenantHandler,
+	authenticate func(http.Handler) http.Handler,
+	requireAdmin func(http.Handler) http.Handler,
+	requireGate func(http.Handler) http.Handler,
+	resolveTenant func(http.Handler) http.Handler,
+) chi.Router {
 	r := chi.NewRouter()
 
 	// Apply middleware stack
 	for _, mw := range middleware.DefaultStack() {
 		r.Use(mw)
 	}
+	r.Use(authenticate)
 
 	// Health check endpoints
 	r.Get("/health", bookingHandler.Health)
 	r.Get("/ping", bookingHandler.Health)
 
+	// API documentation
+	docsHandler := handler.NewDocsHandler(api.OpenAPISpec, api.DocsPage, api.DocsAssets)
+	r.Get("/openapi.json", docsHandler.OpenAPISpec)
+	r.Get("/docs", docsHandler.DocsUI)
+	r.Get("/docs/{asset}", docsHandler.DocsAsset)
+
 	// API endpoints
 	r.Route("/api/v1/bookings", func(r chi.Router) {
+		r.Use(resolveTenant)
 		r.Post("/", bookingHandler.CreateBooking)
 		r.Get("/", bookingHandler.ListBookings)
+		r.With(requireAdmin).Get("/export", exportHandler.ExportBookings)
+		r.With(requireAdmin).Post("/import", importHandler.ImportBookings)
 		r.Get("/{id}", bookingHandler.GetBooking)
 		r.Put("/{id}", bookingHandler.UpdateBooking)
 		r.Delete("/{id}", bookingHandler.CancelBooking)
+		r.Get("/{id}/history", bookingHandler.GetBookingHistory)
+		r.Delete("/{id}/passengers/{passengerID}", bookingHandler.CancelPassenger)
+		r.Get("/{id}/tickets", ticketHandler.ListTickets)
+		r.Get("/{id}/tickets/{ticketID}/qr", ticketHandler.GetTicketQRCode)
+	})
+
+	// Short-lived seat holds that convert into bookings
+	r.Route("/api/v1/holds", func(r chi.Router) {
+		r.Use(resolveTenant)
+		r.Post("/", holdHandler.CreateHold)
+		r.Get("/{id}", holdHandler.GetHold)
+		r.Post("/{id}/extend", holdHandler.ExtendHold)
+		r.Post("/{id}/convert", holdHandler.ConvertHold)
+		r.Delete("/{id}", holdHandler.ReleaseHold)
+	})
+
+	// Gate scanners
+	r.Route("/api/v1/tickets", func(r chi.Router) {
+		r.With(requireGate, resolveTenant).Post("/check-in", ticketHandler.CheckIn)
+		r.Get("/public-keys", ticketHandler.PublicKeys)
+	})
+
+	// Crew manifests of confirmed passengers per departure, which carry their
+	// identity documents
+	r.With(requireAdmin, resolveTenant).Get("/api/v1/manifests", manifestHandler.GetManifest)
+
+	// Revenue and funnel reporting over the booking rollups
+	r.Route("/api/v1/reports", func(r chi.Router) {
+		r.Use(requireAdmin, resolveTenant)
+		r.Get("/revenue", reportHandler.RevenueReport)
+		r.Get("/funnel", reportHandler.FunnelReport)
+		r.Get("/statuses", reportHandler.StatusReport)
+		r.Post("/refresh", reportHandler.RefreshReports)
+	})
+
+	// Settlement reconciliation against paid bookings
+	r.Route("/api/v1/reconciliation", func(r chi.Router) {
+		r.Use(requireAdmin, middleware.AcrossTenants)
+		r.Get("/report", reconciliationHandler.GetReport)
+		r.Post("/run", reconciliationHandler.Run)
 	})
 
+	// Double-entry books of payments, refunds and provider fees
+	r.Route("/api/v1/ledger", func(r chi.Router) {
+		r.Use(requireAdmin, middleware.AcrossTenants)
+		r.Get("/balances", ledgerHandler.GetBalances)
+		r.Get("/entries", ledgerHandler.ListEntries)
+	})
+
+	// Campaign promo codes, applied through promo_code on booking creation
+	r.Route("/api/v1/promotions", func(r chi.Router) {
+		r.Use(requireAdmin, resolveTenant)
+		r.Post("/", promotionHandler.Create)
+		r.Get("/", promotionHandler.List)
+		r.Get("/{id}", promotionHandler.Get)
+		r.Put("/{id}", promotionHandler.Update)
+	})
+
+	// Operators sharing the platform, and the routes each of them runs
+	r.Route("/api/v1/tenants", func(r chi.Router) {
+		r.Use(requireAdmin, middleware.AcrossTenants)
+		r.Post("/", tenantHandler.Create)
+		r.Get("/", tenantHandler.List)
+		r.Get("/{id}", tenantHandler.Get)
+		r.Put("/{id}", tenantHandler.Update)
+	})
+	r.Route("/api/v1/routes", func(r chi.Router) {
+		r.Use(requireAdmin, resolveTenant)
+		r.Get("/", tenantHandler.ListRoutes)
+		r.Put("/{id}", tenantHandler.RegisterRoute)
+	})
+
+	// Partner webhook subscriptions and delivery log
+	r.Route("/api/v1/webhooks", func(r chi.Router) {
+		r.Use(requireAdmin, resolveTenant)
+		r.Post("/subscriptions", webhookHandler.CreateSubscription)
+		r.Get("/subscriptions", webhookHandler.ListSubscriptions)
+		r.Get("/subscriptions/{id}", webhookHandler.GetSubscription)
+		r.Delete("/subscriptions/{id}", webhookHandler.DeleteSubscription)
+		r.Get("/deliveries", webhookHandler.ListDeliveries)
+		r.Get("/deliveries/{id}", webhookHandler.GetDelivery)
+		r.Post("/deliveries/{id}/redeliver", webhookHandler.Redeliver)
+	})
+
+	// Inbound integrations, authenticated by signature rather than actor
+	r.With(middleware.AcrossTenants).Post("/webhooks/payments/{provider}", paymentWebhookHandler.HandlePayment)
+
 	return r
 }
@@ -0,0 +1,97 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/api"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/delivery/http/handler"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/delivery/http/middleware"
+)
+
+// newTestRouter wires the routes to handlers without use cases, for tests
+// that stop at the middleware or need no data.
+func newTestRouter() chi.Router {
+	return NewBookingRouter(handler.NewBookingHandler(nil), handler.NewPaymentWebhookHandler(nil, nil, 0), handler.NewWebhookHandler(nil), handler.NewHoldHandler(nil), handler.NewManifestHandler(nil), handler.NewTicketHandler(nil), handler.NewReportHandler(nil), handler.NewExportHandler(nil), handler.NewImportHandler(nil), handler.NewReconciliationHandler(nil), handler.NewLedgerHandler(nil), handler.NewPromotionHandler(nil), handler.NewTenantHandler(nil), middleware.Authenticate(nil, nil), middleware.RequireAdmin(nil), middleware.RequireGate(nil), middleware.ResolveTenant(nil, "default", nil))
+}
+
+func TestOpenAPISpecMatchesRoutes(t *testing.T) {
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(api.OpenAPISpec, &spec); err != nil {
+		t.Fatalf("openapi.json is not valid JSON: %v", err)
+	}
+
+	r := newTestRouter()
+
+	registered := make(map[string]bool)
+	err := chi.Walk(r, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		path := strings.TrimSuffix(route, "/")
+		if path == "" {
+			path = "/"
+		}
+		registered[method+" "+path] = true
+
+		if _, ok := spec.Paths[path][strings.ToLower(method)]; !ok {
+			t.Errorf("route %s %s is not documented in openapi.json", method, path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walk routes: %v", err)
+	}
+
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			if !registered[strings.ToUpper(method)+" "+path] {
+				t.Errorf("openapi.json documents %s %s which is not registered", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+func TestDocsAssetsAreServedByTheBinary(t *testing.T) {
+	r := newTestRouter()
+
+	page := httptest.NewRecorder()
+	r.ServeHTTP(page, httptest.NewRequest(http.MethodGet, "/docs", nil))
+	if strings.Contains(page.Body.String(), "https://") {
+		t.Errorf("docs page loads assets from another origin:\n%s", page.Body)
+	}
+
+	for asset, contentType := range map[string]string{
+		"/docs/swagger-ui-bundle.js": "text/javascript",
+		"/docs/swagger-ui.css":       "text/css",
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, asset, nil))
+		if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), contentType) {
+			t.Errorf("GET %s = %d %q, want 200 %s", asset, w.Code, w.Header().Get("Content-Type"), contentType)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/missing.js", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET /docs/missing.js = %d, want 404", w.Code)
+	}
+}
+
+func TestManifestsRequireAnAdminToken(t *testing.T) {
+	r := newTestRouter()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/manifests?route_id=1&departure_at=2026-01-02T08:00:00Z", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("GET /api/v1/manifests without a token = %d, want 401", w.Code)
+	}
+}
@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
 
 type BookingStatus string
 
@@ -9,15 +13,78 @@ const (
 	StatusPaid      BookingStatus = "PAID"
 	StatusConfirmed BookingStatus = "CONFIRMED"
 	StatusExpired   BookingStatus = "EXPIRED"
+	StatusCancelled BookingStatus = "CANCELLED"
+	StatusRefunded  BookingStatus = "REFUNDED"
 )
 
 type Booking struct {
-	ID         int64         `json:"id" db:"id"`
-	UserID     int64         `json:"user_id" db:"user_id"`
-	RouteID    int64         `json:"route_id" db:"route_id"`
-	Qty        int           `json:"qty" db:"qty"`
-	Status     BookingStatus `json:"status" db:"status"`
-	PriceTotal int64         `json:"price_total" db:"price_total"`
-	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
-	UpdatedAt  time.Time     `json:"updated_at" db:"updated_at"`
+	ID           int64              `json:"id" db:"id"`
+	TenantID     string             `json:"tenant_id" db:"tenant_id"`
+	UserID       int64              `json:"user_id" db:"user_id"`
+	RouteID      int64              `json:"route_id" db:"route_id"`
+	Qty          int                `json:"qty" db:"qty"`
+	Status       BookingStatus      `json:"status" db:"status"`
+	PriceTotal   money.Money        `json:"price_total" db:"price_total"`
+	PromoCode    string             `json:"promo_code,omitempty" db:"promo_code"`
+	Discount     *money.Money       `json:"discount,omitempty" db:"discount"`
+	DepartureAt  *time.Time         `json:"departure_at,omitempty" db:"departure_at"`
+	CancelReason CancellationReason `json:"cancel_reason,omitempty" db:"cancel_reason"`
+	CancelledAt  *time.Time         `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CreatedAt    time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" db:"updated_at"`
+	DeletedAt    *time.Time         `json:"deleted_at,omitempty" db:"deleted_at"`
+	// Passengers includes cancelled ones; see ActivePassengers.
+	Passengers []*Passenger `json:"passengers,omitempty" db:"-"`
+}
+
+// TerminalStatuses end the booking lifecycle. A CANCELLED booking may still be
+// marked REFUNDED once its refund settles.
+var TerminalStatuses = []BookingStatus{StatusExpired, StatusCancelled, StatusRefunded}
+
+// IsTerminal reports whether s is one of TerminalStatuses.
+func (s BookingStatus) IsTerminal() bool {
+	for _, terminal := range TerminalStatuses {
+		if s == terminal {
+			return true
+		}
+	}
+	return false
+}
+
+// transitions lists the statuses each status may move to. Cancellation is
+// included so CanTransition is complete, but it is governed by the
+// cancellation policy rather than forced directly.
+var transitions = map[BookingStatus][]BookingStatus{
+	StatusCreated:   {StatusPaid, StatusExpired, StatusCancelled},
+	StatusPaid:      {StatusConfirmed, StatusCancelled},
+	StatusConfirmed: {StatusCancelled},
+	StatusCancelled: {StatusRefunded},
+}
+
+// Valid reports whether s is a known status.
+func (s BookingStatus) Valid() bool {
+	switch s {
+	case StatusCreated, StatusPaid, StatusConfirmed, StatusExpired, StatusCancelled, StatusRefunded:
+		return true
+	}
+	return false
+}
+
+// CanTransition reports whether a booking in status from may move to status to.
+func CanTransition(from, to BookingStatus) bool {
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// BookingFilter narrows booking listings. Zero-valued fields are ignored.
+type BookingFilter struct {
+	UserID      int64
+	RouteID     int64
+	Status      BookingStatus
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
 }
@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+type CancellationReason string
+
+const (
+	CancelReasonCustomerRequest CancellationReason = "CUSTOMER_REQUEST"
+	CancelReasonScheduleChange  CancellationReason = "SCHEDULE_CHANGE"
+	CancelReasonOperator        CancellationReason = "OPERATOR"
+	CancelReasonDuplicate       CancellationReason = "DUPLICATE"
+	CancelReasonOther           CancellationReason = "OTHER"
+)
+
+// Valid reports whether r is one of the known cancellation reasons.
+func (r CancellationReason) Valid() bool {
+	switch r {
+	case CancelReasonCustomerRequest, CancelReasonScheduleChange, CancelReasonOperator,
+		CancelReasonDuplicate, CancelReasonOther:
+		return true
+	}
+	return false
+}
+
+// Cancellation is the outcome of cancelling a booking, or one passenger of
+// it when PassengerID is set.
+type Cancellation struct {
+	BookingID     int64              `json:"booking_id"`
+	PassengerID   *int64             `json:"passenger_id,omitempty"`
+	Reason        CancellationReason `json:"reason"`
+	RefundPercent int                `json:"refund_percent"`
+	RefundAmount  money.Money        `json:"refund_amount"`
+	CancelledAt   time.Time          `json:"cancelled_at"`
+}
+
+// RefundRequest asks the payment side to return money for a cancelled booking.
+type RefundRequest struct {
+	BookingID   int64              `json:"booking_id"`
+	PassengerID *int64             `json:"passenger_id,omitempty"`
+	UserID      int64              `json:"user_id"`
+	Amount      money.Money        `json:"amount"`
+	Percent     int                `json:"percent"`
+	Reason      CancellationReason `json:"reason"`
+	RequestedAt time.Time          `json:"requested_at"`
+}
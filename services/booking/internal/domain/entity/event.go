@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AggregateBooking = "booking"
+
+	EventBookingCreated   = "booking.created"
+	EventBookingPaid      = "booking.paid"
+	EventBookingConfirmed = "booking.confirmed"
+	EventBookingExpired   = "booking.expired"
+	EventBookingCancelled = "booking.cancelled"
+	EventBookingRefunded  = "booking.refunded"
+	EventRefundRequested  = "booking.refund_requested"
+
+	EventPassengerCancelled = "booking.passenger_cancelled"
+)
+
+// StatusEvents maps the status a booking enters to the event announcing it.
+var StatusEvents = map[BookingStatus]string{
+	StatusPaid:      EventBookingPaid,
+	StatusConfirmed: EventBookingConfirmed,
+	StatusExpired:   EventBookingExpired,
+	StatusCancelled: EventBookingCancelled,
+	StatusRefunded:  EventBookingRefunded,
+}
+
+// StatusChange is the payload of status events.
+type StatusChange struct {
+	BookingID int64         `json:"booking_id"`
+	UserID    int64         `json:"user_id"`
+	RouteID   int64         `json:"route_id"`
+	OldStatus BookingStatus `json:"old_status"`
+	NewStatus BookingStatus `json:"new_status"`
+	ChangedAt time.Time     `json:"changed_at"`
+}
+
+// OutboxFilter selects outbox events. Zero-valued fields are ignored.
+type OutboxFilter struct {
+	FromID      int64
+	ToID        int64
+	AggregateID int64
+	EventType   string
+	// PendingOnly restricts the selection to events not yet published,
+	// dead-lettered ones included.
+	PendingOnly bool
+}
+
+// OutboxEvent is a domain event stored alongside the change that produced it
+// and picked up by consumers after the transaction commits.
+type OutboxEvent struct {
+	ID            int64           `json:"id" db:"id"`
+	AggregateType string          `json:"aggregate_type" db:"aggregate_type"`
+	AggregateID   int64           `json:"aggregate_id" db:"aggregate_id"`
+	EventType     string          `json:"event_type" db:"event_type"`
+	Payload       json.RawMessage `json:"payload" db:"payload"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+	PublishedAt   *time.Time      `json:"published_at,omitempty" db:"published_at"`
+	// PublishedTo names the publishers that handled the event so far.
+	PublishedTo   []string   `json:"published_to,omitempty" db:"published_to"`
+	Attempts      int        `json:"attempts" db:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" db:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty" db:"last_error"`
+	// DeadAt is set once the relay gave up on the event.
+	DeadAt *time.Time `json:"dead_at,omitempty" db:"dead_at"`
+}
+
+// PublishedBy reports whether the publisher called name handled the event.
+func (e *OutboxEvent) PublishedBy(name string) bool {
+	for _, published := range e.PublishedTo {
+		if published == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NewBookingEvent builds an outbox event for a booking with payload encoded as JSON.
+func NewBookingEvent(bookingID int64, eventType string, payload interface{}) (*OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OutboxEvent{
+		AggregateType: AggregateBooking,
+		AggregateID:   bookingID,
+		EventType:     eventType,
+		Payload:       data,
+		CreatedAt:     time.Now(),
+	}, nil
+}
@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+const (
+	HistoryActionCreated   = "CREATED"
+	HistoryActionUpdated   = "UPDATED"
+	HistoryActionCancelled = "CANCELLED"
+	HistoryActionStatus    = "STATUS_CHANGED"
+
+	HistoryActionPassengerCancelled = "PASSENGER_CANCELLED"
+)
+
+// FieldChange holds the before and after value of a single booking field.
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// BookingHistoryEntry records one mutation of a booking.
+type BookingHistoryEntry struct {
+	ID        int64                  `json:"id" db:"id"`
+	BookingID int64                  `json:"booking_id" db:"booking_id"`
+	Action    string                 `json:"action" db:"action"`
+	OldStatus BookingStatus          `json:"old_status,omitempty" db:"old_status"`
+	NewStatus BookingStatus          `json:"new_status" db:"new_status"`
+	Changes   map[string]FieldChange `json:"changes" db:"changes"`
+	Actor     string                 `json:"actor" db:"actor"`
+	RequestID string                 `json:"request_id,omitempty" db:"request_id"`
+	CreatedAt time.Time              `json:"created_at" db:"created_at"`
+}
+
+// DiffBookings returns the JSON fields that differ between before and after,
+// keyed by their JSON name. A nil before yields every field of after.
+// updated_at is skipped because it changes on every write, and passengers
+// because their documents do not belong in the audit trail.
+func DiffBookings(before, after *Booking) (map[string]FieldChange, error) {
+	oldFields, err := bookingFields(before)
+	if err != nil {
+		return nil, err
+	}
+	newFields, err := bookingFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]FieldChange)
+	for name, newValue := range newFields {
+		if oldValue, ok := oldFields[name]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes[name] = FieldChange{Old: oldFields[name], New: newValue}
+		}
+	}
+	for name, oldValue := range oldFields {
+		if _, ok := newFields[name]; !ok {
+			changes[name] = FieldChange{Old: oldValue}
+		}
+	}
+	delete(changes, "updated_at")
+	delete(changes, "passengers")
+
+	return changes, nil
+}
+
+func bookingFields(booking *Booking) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if booking == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(booking)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,35 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+// Hold reserves seats on a route for a short time while the user pays. It
+// lives only in the hold store and disappears when it expires; converting it
+// creates the booking.
+type Hold struct {
+	ID          string      `json:"id"`
+	UserID      int64       `json:"user_id"`
+	RouteID     int64       `json:"route_id"`
+	Qty         int         `json:"qty"`
+	Seats       []string    `json:"seats,omitempty"`
+	PriceTotal  money.Money `json:"price_total"`
+	DepartureAt *time.Time  `json:"departure_at,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+	ExpiresAt   time.Time   `json:"expires_at"`
+	// Extended is set once the hold has used its single extension.
+	Extended bool `json:"extended"`
+}
+
+// Booking returns the booking a converted hold becomes.
+func (h *Hold) Booking() *Booking {
+	return &Booking{
+		UserID:      h.UserID,
+		RouteID:     h.RouteID,
+		Qty:         h.Qty,
+		PriceTotal:  h.PriceTotal,
+		DepartureAt: h.DepartureAt,
+	}
+}
@@ -0,0 +1,56 @@
+package entity
+
+// ImportMode decides what happens to the rest of an import when a row fails.
+type ImportMode string
+
+const (
+	// ImportAtomic creates every booking or, if any row fails, none.
+	ImportAtomic ImportMode = "atomic"
+	// ImportBestEffort creates the bookings whose rows succeed.
+	ImportBestEffort ImportMode = "best_effort"
+)
+
+// Valid reports whether m is a known mode.
+func (m ImportMode) Valid() bool {
+	return m == ImportAtomic || m == ImportBestEffort
+}
+
+// ImportRow is one booking read from an import file. Lines are the file
+// lines it was read from; Err is set when they do not make a booking.
+type ImportRow struct {
+	Ref     string
+	Lines   []int
+	Booking *Booking
+	Err     error
+}
+
+type ImportRowStatus string
+
+const (
+	ImportRowCreated ImportRowStatus = "CREATED"
+	ImportRowFailed  ImportRowStatus = "FAILED"
+	// ImportRowRolledBack marks a row that succeeded in an atomic import
+	// undone by another row's failure.
+	ImportRowRolledBack ImportRowStatus = "ROLLED_BACK"
+)
+
+// ImportRowResult reports what became of one ImportRow.
+type ImportRowResult struct {
+	Row       int             `json:"row"`
+	Ref       string          `json:"ref,omitempty"`
+	Lines     []int           `json:"lines"`
+	Status    ImportRowStatus `json:"status"`
+	BookingID *int64          `json:"booking_id,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// ImportResult is the per-row report of an import. Committed tells whether
+// any booking was kept.
+type ImportResult struct {
+	Mode      ImportMode         `json:"mode"`
+	Committed bool               `json:"committed"`
+	Total     int                `json:"total"`
+	Created   int                `json:"created"`
+	Failed    int                `json:"failed"`
+	Rows      []*ImportRowResult `json:"rows"`
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// ManifestFilter selects one departure: the bookings of a route leaving at
+// DepartureAt.
+type ManifestFilter struct {
+	RouteID     int64
+	DepartureAt time.Time
+}
+
+// ManifestEntry is one line of a departure manifest. Bookings without
+// passenger details appear once with only their booking fields set.
+type ManifestEntry struct {
+	BookingID      int64        `json:"booking_id"`
+	UserID         int64        `json:"user_id"`
+	Qty            int          `json:"qty"`
+	PassengerID    *int64       `json:"passenger_id,omitempty"`
+	FullName       string       `json:"full_name,omitempty"`
+	DocumentType   DocumentType `json:"document_type,omitempty"`
+	DocumentNumber string       `json:"document_number,omitempty"`
+	DateOfBirth    *Date        `json:"date_of_birth,omitempty"`
+	Seat           string       `json:"seat,omitempty"`
+	CheckedIn      bool         `json:"checked_in"`
+	CheckedInAt    *time.Time   `json:"checked_in_at,omitempty"`
+}
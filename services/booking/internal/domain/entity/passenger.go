@@ -0,0 +1,157 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type DocumentType string
+
+const (
+	DocumentNationalID       DocumentType = "NATIONAL_ID"
+	DocumentPassport         DocumentType = "PASSPORT"
+	DocumentDrivingLicense   DocumentType = "DRIVING_LICENSE"
+	DocumentBirthCertificate DocumentType = "BIRTH_CERTIFICATE"
+	DocumentOther            DocumentType = "OTHER"
+)
+
+// Valid reports whether t is one of the accepted identity documents.
+func (t DocumentType) Valid() bool {
+	switch t {
+	case DocumentNationalID, DocumentPassport, DocumentDrivingLicense,
+		DocumentBirthCertificate, DocumentOther:
+		return true
+	}
+	return false
+}
+
+type PassengerStatus string
+
+const (
+	PassengerActive    PassengerStatus = "ACTIVE"
+	PassengerCancelled PassengerStatus = "CANCELLED"
+)
+
+// Passenger is one traveller on a booking. A booking's active passengers
+// always number its Qty. The identity document is accepted in JSON but never
+// written back; only manifests carry it.
+type Passenger struct {
+	ID             int64              `json:"id"`
+	BookingID      int64              `json:"booking_id"`
+	FullName       string             `json:"full_name"`
+	DocumentType   DocumentType       `json:"document_type"`
+	DocumentNumber string             `json:"document_number"`
+	DateOfBirth    Date               `json:"date_of_birth"`
+	Seat           string             `json:"seat,omitempty"`
+	Status         PassengerStatus    `json:"status"`
+	CancelReason   CancellationReason `json:"cancel_reason,omitempty"`
+	CancelledAt    *time.Time         `json:"cancelled_at,omitempty"`
+	CheckedInAt    *time.Time         `json:"checked_in_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+// MarshalJSON writes p without its document number and date of birth, so
+// they stay out of API responses, event payloads and the booking cache.
+func (p Passenger) MarshalJSON() ([]byte, error) {
+	type passenger Passenger
+	return json.Marshal(struct {
+		passenger
+		DocumentNumber string `json:"document_number,omitempty"`
+		DateOfBirth    *Date  `json:"date_of_birth,omitempty"`
+	}{passenger: passenger(p)})
+}
+
+// ActivePassengers returns the passengers of b that are still travelling.
+func (b *Booking) ActivePassengers() []*Passenger {
+	var active []*Passenger
+	for _, p := range b.Passengers {
+		if p.Status == PassengerActive {
+			active = append(active, p)
+		}
+	}
+	return active
+}
+
+// Date is a calendar date without time of day, written as YYYY-MM-DD.
+type Date struct {
+	time.Time
+}
+
+const dateLayout = "2006-01-02"
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date{t}, nil
+}
+
+func (d Date) String() string {
+	return d.Format(dateLayout)
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	if d.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(d.String())
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*d = Date{}
+		return nil
+	}
+
+	// Full timestamps are accepted and truncated to their date
+	if len(s) > len(dateLayout) && strings.Contains(s, "T") {
+		s = s[:len(dateLayout)]
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return fmt.Errorf("invalid date %q, want YYYY-MM-DD", s)
+	}
+	*d = parsed
+	return nil
+}
+
+// Value implements driver.Valuer for DATE columns.
+func (d Date) Value() (driver.Value, error) {
+	if d.IsZero() {
+		return nil, nil
+	}
+	return d.String(), nil
+}
+
+// Scan implements sql.Scanner for DATE columns.
+func (d *Date) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*d = Date{}
+	case time.Time:
+		*d = NewDate(v.Year(), v.Month(), v.Day())
+	case []byte:
+		return d.Scan(string(v))
+	case string:
+		parsed, err := ParseDate(v[:min(len(v), len(dateLayout))])
+		if err != nil {
+			return err
+		}
+		*d = parsed
+	default:
+		return fmt.Errorf("cannot scan %T into Date", src)
+	}
+	return nil
+}
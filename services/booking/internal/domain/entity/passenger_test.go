@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPassengerJSONLeavesOutDocument(t *testing.T) {
+	passenger := &Passenger{
+		ID:             3,
+		BookingID:      1,
+		FullName:       "Siti Rahma",
+		DocumentType:   DocumentPassport,
+		DocumentNumber: "C1234567",
+		DateOfBirth:    NewDate(1990, time.April, 12),
+		Seat:           "3A",
+		Status:         PassengerActive,
+	}
+
+	data, err := json.Marshal(&Booking{ID: 1, Passengers: []*Passenger{passenger}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	for _, leaked := range []string{"C1234567", "1990-04-12", "document_number", "date_of_birth"} {
+		if strings.Contains(string(data), leaked) {
+			t.Errorf("%s leaked into %s", leaked, data)
+		}
+	}
+	if !strings.Contains(string(data), `"full_name":"Siti Rahma"`) || !strings.Contains(string(data), `"document_type":"PASSPORT"`) {
+		t.Errorf("missing passenger fields in %s", data)
+	}
+}
+
+func TestPassengerJSONAcceptsDocument(t *testing.T) {
+	var passenger Passenger
+	err := json.Unmarshal([]byte(`{"full_name": "Siti Rahma", "document_type": "PASSPORT", "document_number": "C1234567", "date_of_birth": "1990-04-12"}`), &passenger)
+	if err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if passenger.DocumentNumber != "C1234567" || passenger.DateOfBirth.String() != "1990-04-12" {
+		t.Errorf("got %+v", passenger)
+	}
+}
@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+type PaymentEventType string
+
+const (
+	PaymentSucceeded PaymentEventType = "payment.succeeded"
+	PaymentFailed    PaymentEventType = "payment.failed"
+	PaymentRefunded  PaymentEventType = "payment.refunded"
+)
+
+// Valid reports whether t is a payment event type we understand.
+func (t PaymentEventType) Valid() bool {
+	switch t {
+	case PaymentSucceeded, PaymentFailed, PaymentRefunded:
+		return true
+	}
+	return false
+}
+
+// PaymentTransitions maps payment events to the booking status they lead to.
+// Failed payments move nothing; the booking expires if it is never paid.
+var PaymentTransitions = map[PaymentEventType]BookingStatus{
+	PaymentSucceeded: StatusPaid,
+	PaymentRefunded:  StatusRefunded,
+}
+
+// PaymentOutcome records what a payment event did to its booking.
+type PaymentOutcome string
+
+const (
+	// PaymentOutcomeApplied means the booking changed status.
+	PaymentOutcomeApplied PaymentOutcome = "APPLIED"
+	// PaymentOutcomeIgnored means the event needed no change or did not fit
+	// the booking's lifecycle, e.g. a late success for an expired booking.
+	PaymentOutcomeIgnored PaymentOutcome = "IGNORED"
+	// PaymentOutcomeRejected means the paid amount did not match the booking.
+	PaymentOutcomeRejected PaymentOutcome = "REJECTED"
+)
+
+// PaymentEvent is a payment provider's notification about a booking. EventID
+// is unique per provider and used to drop redelivered notifications.
+type PaymentEvent struct {
+	Provider   string           `json:"provider" db:"provider"`
+	EventID    string           `json:"id" db:"event_id"`
+	Type       PaymentEventType `json:"type" db:"event_type"`
+	BookingID  int64            `json:"booking_id" db:"booking_id"`
+	Amount     money.Money      `json:"amount" db:"amount"`
+	OccurredAt time.Time        `json:"occurred_at" db:"occurred_at"`
+	Outcome    PaymentOutcome   `json:"outcome,omitempty" db:"outcome"`
+	Payload    json.RawMessage  `json:"-" db:"payload"`
+	ReceivedAt time.Time        `json:"received_at,omitempty" db:"received_at"`
+}
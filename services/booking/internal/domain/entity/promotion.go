@@ -0,0 +1,131 @@
+package entity
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+type PromotionKind string
+
+const (
+	// PromotionPercent takes Percent off the booking price.
+	PromotionPercent PromotionKind = "PERCENT"
+	// PromotionFixed takes Amount off the booking price, at most all of it.
+	PromotionFixed PromotionKind = "FIXED"
+)
+
+var promoCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,32}$`)
+
+// Promotion is a campaign code of a tenant. RouteIDs empty applies it to every route;
+// nil limits are unlimited. Redemptions counts the bookings holding the
+// code, which expired bookings hand back.
+type Promotion struct {
+	ID             int64         `json:"id"`
+	TenantID       string        `json:"tenant_id"`
+	Code           string        `json:"code"`
+	Description    string        `json:"description,omitempty"`
+	Kind           PromotionKind `json:"kind"`
+	Percent        int           `json:"percent,omitempty"`
+	Amount         *money.Money  `json:"amount,omitempty"`
+	RouteIDs       []int64       `json:"route_ids"`
+	MinQty         int           `json:"min_qty"`
+	MaxRedemptions *int          `json:"max_redemptions,omitempty"`
+	MaxPerUser     *int          `json:"max_per_user,omitempty"`
+	Redemptions    int           `json:"redemptions"`
+	StartsAt       time.Time     `json:"starts_at"`
+	EndsAt         *time.Time    `json:"ends_at,omitempty"`
+	Disabled       bool          `json:"disabled"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// NormalizePromoCode returns code as stored: trimmed and upper-case.
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Validate checks the promotion's settings, defaulting MinQty to 1 and
+// RouteIDs to every route.
+func (p *Promotion) Validate() error {
+	p.Code = NormalizePromoCode(p.Code)
+	if p.MinQty == 0 {
+		p.MinQty = 1
+	}
+	if p.RouteIDs == nil {
+		p.RouteIDs = []int64{}
+	}
+
+	if !promoCodePattern.MatchString(p.Code) || p.MinQty < 1 || p.StartsAt.IsZero() {
+		return apperrors.ErrInvalidPromotion
+	}
+	switch p.Kind {
+	case PromotionPercent:
+		if p.Percent < 1 || p.Percent > 100 || p.Amount != nil {
+			return apperrors.ErrInvalidPromotion
+		}
+	case PromotionFixed:
+		if p.Amount == nil || p.Amount.Amount() <= 0 || p.Percent != 0 {
+			return apperrors.ErrInvalidPromotion
+		}
+	default:
+		return apperrors.ErrInvalidPromotion
+	}
+	if (p.MaxRedemptions != nil && *p.MaxRedemptions < 1) || (p.MaxPerUser != nil && *p.MaxPerUser < 1) {
+		return apperrors.ErrInvalidPromotion
+	}
+	if p.EndsAt != nil && !p.EndsAt.After(p.StartsAt) {
+		return apperrors.ErrInvalidPromotion
+	}
+
+	return nil
+}
+
+// AppliesTo reports whether booking may use the promotion at time at,
+// limits aside.
+func (p *Promotion) AppliesTo(booking *Booking, at time.Time) bool {
+	if p.Disabled || at.Before(p.StartsAt) || (p.EndsAt != nil && !at.Before(*p.EndsAt)) {
+		return false
+	}
+	if booking.Qty < p.MinQty {
+		return false
+	}
+	if p.Kind == PromotionFixed && p.Amount.Currency() != booking.PriceTotal.Currency() {
+		return false
+	}
+	if len(p.RouteIDs) == 0 {
+		return true
+	}
+	for _, id := range p.RouteIDs {
+		if id == booking.RouteID {
+			return true
+		}
+	}
+	return false
+}
+
+// DiscountFor returns what the promotion takes off price. Percentages round
+// half up to the minor unit.
+func (p *Promotion) DiscountFor(price money.Money) (money.Money, error) {
+	if p.Kind == PromotionPercent {
+		return price.Percent(p.Percent, money.RoundHalfUp)
+	}
+
+	if cmp, err := p.Amount.Cmp(price); err != nil || cmp > 0 {
+		return price, err
+	}
+	return *p.Amount, nil
+}
+
+// PromotionRedemption is a booking's use of a promotion.
+type PromotionRedemption struct {
+	ID          int64       `json:"id"`
+	PromotionID int64       `json:"promotion_id"`
+	BookingID   int64       `json:"booking_id"`
+	UserID      int64       `json:"user_id"`
+	Discount    money.Money `json:"discount"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
@@ -0,0 +1,140 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+func TestPromotionDiscountFor(t *testing.T) {
+	fixed := func(amount int64) *money.Money {
+		m := money.MustNew(amount, "IDR")
+		return &m
+	}
+
+	tests := []struct {
+		name      string
+		promotion Promotion
+		price     int64
+		want      int64
+	}{
+		{name: "percent", promotion: Promotion{Kind: PromotionPercent, Percent: 25}, price: 300000, want: 75000},
+		{name: "percent rounds half up", promotion: Promotion{Kind: PromotionPercent, Percent: 15}, price: 10010, want: 1502},
+		{name: "percent rounds down below half", promotion: Promotion{Kind: PromotionPercent, Percent: 10}, price: 10004, want: 1000},
+		{name: "whole price", promotion: Promotion{Kind: PromotionPercent, Percent: 100}, price: 150000, want: 150000},
+		{name: "fixed", promotion: Promotion{Kind: PromotionFixed, Amount: fixed(50000)}, price: 300000, want: 50000},
+		{name: "fixed capped at price", promotion: Promotion{Kind: PromotionFixed, Amount: fixed(500000)}, price: 300000, want: 300000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.promotion.DiscountFor(money.MustNew(tt.price, "IDR"))
+			if err != nil {
+				t.Fatalf("discount: %v", err)
+			}
+			if got != money.MustNew(tt.want, "IDR") {
+				t.Errorf("got %s, want %d", got, tt.want)
+			}
+		})
+	}
+
+	usd := money.MustNew(500, "USD")
+	if _, err := (&Promotion{Kind: PromotionFixed, Amount: &usd}).DiscountFor(money.MustNew(300000, "IDR")); err == nil {
+		t.Error("fixed discount in another currency: want an error")
+	}
+}
+
+func TestPromotionAppliesTo(t *testing.T) {
+	start := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 1, 0)
+	usd := money.MustNew(500, "USD")
+
+	tests := []struct {
+		name   string
+		modify func(p *Promotion, b *Booking)
+		at     time.Time
+		want   bool
+	}{
+		{name: "applies", at: start.Add(time.Hour), want: true},
+		{name: "from its start", at: start, want: true},
+		{name: "before start", at: start.Add(-time.Second)},
+		{name: "until just before its end", at: end.Add(-time.Second), want: true},
+		{name: "at its end", at: end},
+		{name: "open ended", modify: func(p *Promotion, b *Booking) { p.EndsAt = nil }, at: end.AddDate(1, 0, 0), want: true},
+		{name: "disabled", modify: func(p *Promotion, b *Booking) { p.Disabled = true }, at: start},
+		{name: "min qty met", modify: func(p *Promotion, b *Booking) { b.Qty = 2 }, at: start, want: true},
+		{name: "below min qty", modify: func(p *Promotion, b *Booking) { b.Qty = 1 }, at: start},
+		{name: "other route", modify: func(p *Promotion, b *Booking) { b.RouteID = 9 }, at: start},
+		{name: "every route", modify: func(p *Promotion, b *Booking) { p.RouteIDs, b.RouteID = []int64{}, 9 }, at: start, want: true},
+		{name: "fixed in another currency", modify: func(p *Promotion, b *Booking) {
+			p.Kind, p.Percent, p.Amount = PromotionFixed, 0, &usd
+		}, at: start},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promotion := &Promotion{
+				Code:     "MUDIK25",
+				Kind:     PromotionPercent,
+				Percent:  25,
+				RouteIDs: []int64{7, 8},
+				MinQty:   2,
+				StartsAt: start,
+				EndsAt:   &end,
+			}
+			booking := &Booking{RouteID: 7, Qty: 3, PriceTotal: money.MustNew(300000, "IDR")}
+			if tt.modify != nil {
+				tt.modify(promotion, booking)
+			}
+
+			if got := promotion.AppliesTo(booking, tt.at); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromotionValidate(t *testing.T) {
+	start := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	before := start.Add(-time.Hour)
+	zero, amount := 0, money.MustNew(50000, "IDR")
+
+	tests := []struct {
+		name   string
+		modify func(p *Promotion)
+		want   error
+	}{
+		{name: "valid"},
+		{name: "lower-case code", modify: func(p *Promotion) { p.Code = " mudik25 " }},
+		{name: "short code", modify: func(p *Promotion) { p.Code = "MU" }, want: apperrors.ErrInvalidPromotion},
+		{name: "no start", modify: func(p *Promotion) { p.StartsAt = time.Time{} }, want: apperrors.ErrInvalidPromotion},
+		{name: "ends before start", modify: func(p *Promotion) { p.EndsAt = &before }, want: apperrors.ErrInvalidPromotion},
+		{name: "over 100 percent", modify: func(p *Promotion) { p.Percent = 101 }, want: apperrors.ErrInvalidPromotion},
+		{name: "percent with amount", modify: func(p *Promotion) { p.Amount = &amount }, want: apperrors.ErrInvalidPromotion},
+		{name: "fixed", modify: func(p *Promotion) { p.Kind, p.Percent, p.Amount = PromotionFixed, 0, &amount }},
+		{name: "fixed without amount", modify: func(p *Promotion) { p.Kind, p.Percent = PromotionFixed, 0 }, want: apperrors.ErrInvalidPromotion},
+		{name: "negative min qty", modify: func(p *Promotion) { p.MinQty = -1 }, want: apperrors.ErrInvalidPromotion},
+		{name: "zero redemptions", modify: func(p *Promotion) { p.MaxRedemptions = &zero }, want: apperrors.ErrInvalidPromotion},
+		{name: "zero per user", modify: func(p *Promotion) { p.MaxPerUser = &zero }, want: apperrors.ErrInvalidPromotion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promotion := &Promotion{Code: "MUDIK25", Kind: PromotionPercent, Percent: 25, StartsAt: start}
+			if tt.modify != nil {
+				tt.modify(promotion)
+			}
+
+			err := promotion.Validate()
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if err == nil && (promotion.Code != "MUDIK25" || promotion.MinQty != 1 || promotion.RouteIDs == nil) {
+				t.Errorf("defaults not applied: %+v", promotion)
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+// SettlementFile is a provider settlement file as ingested. Checksum is the
+// SHA-256 of its content, which keeps a file from being ingested twice.
+type SettlementFile struct {
+	ID         int64     `json:"id"`
+	Provider   string    `json:"provider"`
+	Name       string    `json:"name"`
+	Checksum   string    `json:"checksum"`
+	Rows       int       `json:"rows"`
+	IngestedAt time.Time `json:"ingested_at"`
+}
+
+// Settlement is one payment a provider reports as settled. Reference is the
+// provider's payment ID, the event ID of its payment webhook; BookingID is
+// set when the provider passes ours through. Fee is what the provider kept
+// of Amount, zero when the file has none.
+type Settlement struct {
+	ID        int64       `json:"id"`
+	FileID    int64       `json:"file_id"`
+	Line      int         `json:"line"`
+	Provider  string      `json:"provider"`
+	Reference string      `json:"reference"`
+	BookingID *int64      `json:"booking_id,omitempty"`
+	Amount    money.Money `json:"amount"`
+	Fee       money.Money `json:"fee"`
+	SettledAt *time.Time  `json:"settled_at,omitempty"`
+}
+
+type DiscrepancyKind string
+
+const (
+	// DiscrepancyUnsettledPayment is a booking paid longer ago than the
+	// settlement grace period without a settlement.
+	DiscrepancyUnsettledPayment DiscrepancyKind = "UNSETTLED_PAYMENT"
+	// DiscrepancyUnmatchedSettlement is a settlement whose payment matches no
+	// booking that was ever PAID.
+	DiscrepancyUnmatchedSettlement DiscrepancyKind = "UNMATCHED_SETTLEMENT"
+	// DiscrepancyAmountMismatch is a settlement for a paid booking whose
+	// amount or currency differs from the payment.
+	DiscrepancyAmountMismatch DiscrepancyKind = "AMOUNT_MISMATCH"
+	// DiscrepancyDuplicateSettlement is a payment settled more than once; the
+	// first settlement is matched as usual and each later one flagged.
+	DiscrepancyDuplicateSettlement DiscrepancyKind = "DUPLICATE_SETTLEMENT"
+)
+
+// Valid reports whether k is a known kind.
+func (k DiscrepancyKind) Valid() bool {
+	switch k {
+	case DiscrepancyUnsettledPayment, DiscrepancyUnmatchedSettlement,
+		DiscrepancyAmountMismatch, DiscrepancyDuplicateSettlement:
+		return true
+	}
+	return false
+}
+
+// Discrepancy is a mismatch between bookings and settlements. It resolves on
+// its own once a later run no longer finds it.
+type Discrepancy struct {
+	ID           int64           `json:"id"`
+	Kind         DiscrepancyKind `json:"kind"`
+	Provider     string          `json:"provider,omitempty"`
+	Reference    string          `json:"reference,omitempty"`
+	BookingID    *int64          `json:"booking_id,omitempty"`
+	SettlementID *int64          `json:"settlement_id,omitempty"`
+	Expected     *money.Money    `json:"expected,omitempty"`
+	Settled      *money.Money    `json:"settled,omitempty"`
+	DetectedAt   time.Time       `json:"detected_at"`
+	ResolvedAt   *time.Time      `json:"resolved_at,omitempty"`
+}
+
+// DiscrepancyFilter narrows discrepancy listings. Zero-valued fields are
+// ignored; Resolved picks open (false) or resolved (true) ones.
+type DiscrepancyFilter struct {
+	Kind     DiscrepancyKind
+	Provider string
+	Resolved *bool
+}
+
+// ReconciliationRun summarises one pass: the files and settlements it
+// ingested, the discrepancies it opened and resolved, and the files it
+// could not read.
+type ReconciliationRun struct {
+	ID          int64     `json:"id"`
+	StartedAt   time.Time `json:"started_at"`
+	FinishedAt  time.Time `json:"finished_at"`
+	Files       int       `json:"files"`
+	Settlements int       `json:"settlements"`
+	Opened      int       `json:"opened"`
+	Resolved    int       `json:"resolved"`
+	Errors      []string  `json:"errors,omitempty"`
+}
+
+// ReconciliationReport is the state of reconciliation: the last run, open
+// discrepancies by kind and the discrepancies matching a filter.
+type ReconciliationReport struct {
+	LastRun       *ReconciliationRun      `json:"last_run"`
+	Open          map[DiscrepancyKind]int `json:"open"`
+	Discrepancies []*Discrepancy          `json:"discrepancies"`
+}
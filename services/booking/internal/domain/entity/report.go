@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+// ReportInterval is the period report rows are bucketed into.
+type ReportInterval string
+
+const (
+	ReportDaily   ReportInterval = "day"
+	ReportWeekly  ReportInterval = "week"
+	ReportMonthly ReportInterval = "month"
+)
+
+// Valid reports whether i is a known interval.
+func (i ReportInterval) Valid() bool {
+	switch i {
+	case ReportDaily, ReportWeekly, ReportMonthly:
+		return true
+	}
+	return false
+}
+
+// ReportQuery selects bookings created from From through To, both local
+// calendar dates in Location, bucketed by Interval. Weeks start on Monday.
+// With ByRoute each route gets its own rows; RouteID limits the report to
+// one route.
+type ReportQuery struct {
+	From     Date
+	To       Date
+	Location *time.Location
+	Interval ReportInterval
+	RouteID  int64
+	ByRoute  bool
+}
+
+// Start returns the first instant the query covers.
+func (q *ReportQuery) Start() time.Time {
+	return time.Date(q.From.Year(), q.From.Month(), q.From.Day(), 0, 0, 0, 0, q.Location)
+}
+
+// End returns the instant just after the last one the query covers.
+func (q *ReportQuery) End() time.Time {
+	return time.Date(q.To.Year(), q.To.Month(), q.To.Day()+1, 0, 0, 0, 0, q.Location)
+}
+
+// Report is the answer to a ReportQuery. RefreshedAt tells how current the
+// underlying rollups are; bookings changed after it are not reflected yet.
+type Report struct {
+	From        Date           `json:"from"`
+	To          Date           `json:"to"`
+	TimeZone    string         `json:"time_zone"`
+	Interval    ReportInterval `json:"interval"`
+	RefreshedAt *time.Time     `json:"refreshed_at"`
+	Rows        interface{}    `json:"rows"`
+}
+
+// RevenueRow is the revenue of the bookings created in one period, per
+// currency. Revenue is the current total of bookings that were paid;
+// Refunds are the refunds requested when whole bookings were cancelled.
+type RevenueRow struct {
+	Period       Date        `json:"period"`
+	RouteID      *int64      `json:"route_id,omitempty"`
+	Bookings     int         `json:"bookings"`
+	Seats        int         `json:"seats"`
+	PaidBookings int         `json:"paid_bookings"`
+	Revenue      money.Money `json:"revenue"`
+	Refunds      money.Money `json:"refunds"`
+	NetRevenue   money.Money `json:"net_revenue"`
+}
+
+// FunnelRow follows the bookings created in one period through the
+// lifecycle. Paid and Confirmed count bookings that ever reached that
+// status; the rates are fractions of Created.
+type FunnelRow struct {
+	Period        Date    `json:"period"`
+	RouteID       *int64  `json:"route_id,omitempty"`
+	Created       int     `json:"created"`
+	Paid          int     `json:"paid"`
+	Confirmed     int     `json:"confirmed"`
+	Expired       int     `json:"expired"`
+	Cancelled     int     `json:"cancelled"`
+	Refunded      int     `json:"refunded"`
+	PaidRate      float64 `json:"paid_rate"`
+	ConfirmedRate float64 `json:"confirmed_rate"`
+}
+
+// StatusRow counts the bookings created in one period by current status.
+type StatusRow struct {
+	Period   Date          `json:"period"`
+	RouteID  *int64        `json:"route_id,omitempty"`
+	Status   BookingStatus `json:"status"`
+	Bookings int           `json:"bookings"`
+	Seats    int           `json:"seats"`
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+)
+
+// DefaultTenantID owns everything created without a tenant, including all
+// data from before tenants existed.
+const DefaultTenantID = "default"
+
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// Tenant is an operator sharing the platform. Its bookings, routes and
+// promotions are invisible to other tenants.
+type Tenant struct {
+	ID        string         `json:"id"`
+	Name      string         `json:"name"`
+	Settings  TenantSettings `json:"settings"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// TenantSettings override the service configuration for one tenant; unset
+// fields fall back to it.
+type TenantSettings struct {
+	AllowCancelHours *int         `json:"allow_cancel_hours,omitempty"`
+	RefundTiers      []RefundTier `json:"refund_tiers,omitempty"`
+	HoldTTLSeconds   *int         `json:"hold_ttl_seconds,omitempty"`
+}
+
+// RefundTier grants Percent of the paid amount to cancellations at least
+// MinHoursBefore hours before departure.
+type RefundTier struct {
+	MinHoursBefore int `json:"min_hours_before"`
+	Percent        int `json:"percent"`
+}
+
+// Validate checks the tenant's ID, name and settings.
+func (t *Tenant) Validate() error {
+	t.ID = strings.TrimSpace(t.ID)
+	t.Name = strings.TrimSpace(t.Name)
+	if !tenantIDPattern.MatchString(t.ID) || t.Name == "" {
+		return apperrors.ErrInvalidTenant
+	}
+
+	s := t.Settings
+	if s.AllowCancelHours != nil && *s.AllowCancelHours < 0 {
+		return apperrors.ErrInvalidTenant
+	}
+	if s.HoldTTLSeconds != nil && *s.HoldTTLSeconds <= 0 {
+		return apperrors.ErrInvalidTenant
+	}
+	for _, tier := range s.RefundTiers {
+		if tier.MinHoursBefore < 0 || tier.Percent < 0 || tier.Percent > 100 {
+			return apperrors.ErrInvalidTenant
+		}
+	}
+
+	return nil
+}
+
+// HoldTTL returns the tenant's hold lifetime, or fallback.
+func (s TenantSettings) HoldTTL(fallback time.Duration) time.Duration {
+	if s.HoldTTLSeconds == nil {
+		return fallback
+	}
+	return time.Duration(*s.HoldTTLSeconds) * time.Second
+}
+
+// Route is a route operated by a tenant.
+type Route struct {
+	ID        int64     `json:"id" db:"id"`
+	TenantID  string    `json:"tenant_id" db:"tenant_id"`
+	Name      string    `json:"name,omitempty" db:"name"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
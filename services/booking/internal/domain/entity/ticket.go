@@ -0,0 +1,70 @@
+package entity
+
+import "time"
+
+type TicketStatus string
+
+const (
+	TicketIssued  TicketStatus = "ISSUED"
+	TicketRevoked TicketStatus = "REVOKED"
+)
+
+// Ticket is what a passenger presents at the gate. Token is a signed
+// ticket.Claims that scanners can verify without the booking service.
+type Ticket struct {
+	ID        string `json:"id"`
+	BookingID int64  `json:"booking_id"`
+	// Seq numbers the tickets of a booking from 1.
+	Seq         int          `json:"seq"`
+	PassengerID *int64       `json:"passenger_id,omitempty"`
+	HolderName  string       `json:"holder_name,omitempty"`
+	Seat        string       `json:"seat,omitempty"`
+	Status      TicketStatus `json:"status"`
+	Token       string       `json:"token"`
+	IssuedAt    time.Time    `json:"issued_at"`
+	RevokedAt   *time.Time   `json:"revoked_at,omitempty"`
+	// UsedAt is set by the first accepted gate scan; UsedBy names the gate.
+	UsedAt *time.Time `json:"used_at,omitempty"`
+	UsedBy string     `json:"used_by,omitempty"`
+	// QRCode is the token rendered as a PNG, set on issued tickets returned
+	// by the API.
+	QRCode []byte `json:"qr_png,omitempty"`
+}
+
+// CheckInReason explains a gate decision to the scanner.
+type CheckInReason string
+
+const (
+	CheckInOK               CheckInReason = "OK"
+	CheckInMalformedToken   CheckInReason = "MALFORMED_TOKEN"
+	CheckInInvalidSignature CheckInReason = "INVALID_SIGNATURE"
+	CheckInWrongDeparture   CheckInReason = "WRONG_DEPARTURE"
+	CheckInUnknownTicket    CheckInReason = "UNKNOWN_TICKET"
+	CheckInRevoked          CheckInReason = "REVOKED"
+	CheckInAlreadyUsed      CheckInReason = "ALREADY_USED"
+	CheckInNotConfirmed     CheckInReason = "BOOKING_NOT_CONFIRMED"
+)
+
+// CheckIn is a scan at the gate of one departure.
+type CheckIn struct {
+	Token       string    `json:"token"`
+	RouteID     int64     `json:"route_id"`
+	DepartureAt time.Time `json:"departure_at"`
+	// Gate identifies the scanner; the caller is recorded when empty.
+	Gate string `json:"gate,omitempty"`
+}
+
+// CheckInResult is the allow/deny answer to a scan. Ticket details are
+// filled in whenever the token could be read.
+type CheckInResult struct {
+	Allowed     bool          `json:"allowed"`
+	Reason      CheckInReason `json:"reason"`
+	TicketID    string        `json:"ticket_id,omitempty"`
+	BookingID   int64         `json:"booking_id,omitempty"`
+	PassengerID *int64        `json:"passenger_id,omitempty"`
+	HolderName  string        `json:"holder_name,omitempty"`
+	Seat        string        `json:"seat,omitempty"`
+	// UsedAt is when the ticket was accepted, now or by an earlier scan.
+	UsedAt *time.Time `json:"used_at,omitempty"`
+	UsedBy string     `json:"used_by,omitempty"`
+}
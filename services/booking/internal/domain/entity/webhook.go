@@ -0,0 +1,125 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventTypes are the booking events partners can subscribe to.
+// WebhookAllEvents subscribes to all of them.
+var WebhookEventTypes = []string{
+	EventBookingCreated,
+	EventBookingPaid,
+	EventBookingConfirmed,
+	EventBookingExpired,
+	EventBookingCancelled,
+	EventBookingRefunded,
+	EventPassengerCancelled,
+}
+
+const WebhookAllEvents = "*"
+
+// WebhookSubscription asks for booking events of its tenant to be pushed to a
+// partner URL. When UserID is set only events of that user's bookings are
+// delivered.
+type WebhookSubscription struct {
+	ID          int64     `json:"id" db:"id"`
+	TenantID    string    `json:"tenant_id" db:"tenant_id"`
+	Partner     string    `json:"partner" db:"partner"`
+	Description string    `json:"description,omitempty" db:"description"`
+	URL         string    `json:"url" db:"url"`
+	Secret      string    `json:"secret,omitempty" db:"secret"`
+	EventTypes  []string  `json:"event_types" db:"event_types"`
+	UserID      *int64    `json:"user_id,omitempty" db:"user_id"`
+	Active      bool      `json:"active" db:"active"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Wants reports whether the subscription receives eventType.
+func (s *WebhookSubscription) Wants(eventType string) bool {
+	for _, t := range s.EventTypes {
+		if t == WebhookAllEvents || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidWebhookEventType reports whether t can be subscribed to.
+func ValidWebhookEventType(t string) bool {
+	if t == WebhookAllEvents {
+		return true
+	}
+	for _, known := range WebhookEventTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	// DeliveryPending deliveries are waiting for their next attempt.
+	DeliveryPending WebhookDeliveryStatus = "PENDING"
+	// DeliveryDelivered deliveries got a 2xx response.
+	DeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	// DeliveryDead deliveries ran out of attempts and wait for a manual redelivery.
+	DeliveryDead WebhookDeliveryStatus = "DEAD"
+)
+
+// WebhookDelivery is one outbox event addressed to one subscription. Payload
+// is the exact body sent, so redeliveries are byte-identical.
+type WebhookDelivery struct {
+	ID             int64                 `json:"id" db:"id"`
+	SubscriptionID int64                 `json:"subscription_id" db:"subscription_id"`
+	EventID        int64                 `json:"event_id" db:"event_id"`
+	EventType      string                `json:"event_type" db:"event_type"`
+	BookingID      int64                 `json:"booking_id" db:"booking_id"`
+	Payload        json.RawMessage       `json:"payload" db:"payload"`
+	Status         WebhookDeliveryStatus `json:"status" db:"status"`
+	Attempts       int                   `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time             `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError      string                `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty" db:"delivered_at"`
+
+	// Attempts log, filled in when a single delivery is requested
+	AttemptLog []*WebhookAttempt `json:"attempt_log,omitempty" db:"-"`
+}
+
+// WebhookAttempt is the result of one HTTP call for a delivery.
+type WebhookAttempt struct {
+	ID          int64     `json:"id" db:"id"`
+	DeliveryID  int64     `json:"delivery_id" db:"delivery_id"`
+	Attempt     int       `json:"attempt" db:"attempt"`
+	StatusCode  int       `json:"status_code,omitempty" db:"status_code"`
+	Error       string    `json:"error,omitempty" db:"error"`
+	DurationMS  int64     `json:"duration_ms" db:"duration_ms"`
+	AttemptedAt time.Time `json:"attempted_at" db:"attempted_at"`
+}
+
+// Succeeded reports whether the partner accepted the delivery.
+func (a *WebhookAttempt) Succeeded() bool {
+	return a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300
+}
+
+// WebhookDeliveryFilter narrows delivery listings. Zero-valued fields are ignored.
+type WebhookDeliveryFilter struct {
+	SubscriptionID int64
+	BookingID      int64
+	EventType      string
+	Status         WebhookDeliveryStatus
+}
+
+// WebhookEnvelope is the JSON body partners receive.
+type WebhookEnvelope struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	BookingID int64           `json:"booking_id"`
+	TenantID  string          `json:"tenant_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
@@ -0,0 +1,101 @@
+package policy
+
+import (
+	"sort"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+// RefundTier grants Percent of the paid amount when a booking is cancelled at
+// least MinHoursBefore hours before departure. Tenants configure their own as
+// entity.RefundTier.
+type RefundTier = entity.RefundTier
+
+// DefaultRefundTiers is used when no tiers are configured. Cancellations that
+// pass the AllowCancelHours cut-off but match no tier get no refund.
+var DefaultRefundTiers = []RefundTier{
+	{MinHoursBefore: 72, Percent: 100},
+	{MinHoursBefore: 24, Percent: 75},
+	{MinHoursBefore: 0, Percent: 50},
+}
+
+// CancellationDecision describes whether a booking may be cancelled and how
+// much of the paid amount should be refunded.
+type CancellationDecision struct {
+	RefundPercent int
+	RefundAmount  money.Money
+}
+
+// CancellationPolicy decides cancellability and refunds by time before departure.
+type CancellationPolicy struct {
+	// AllowCancelHours is the cut-off before departure after which paid
+	// bookings can no longer be cancelled.
+	AllowCancelHours int
+	Tiers            []RefundTier
+}
+
+func NewCancellationPolicy(allowCancelHours int, tiers []RefundTier) *CancellationPolicy {
+	if len(tiers) == 0 {
+		tiers = DefaultRefundTiers
+	}
+
+	sorted := make([]RefundTier, len(tiers))
+	copy(sorted, tiers)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].MinHoursBefore > sorted[j].MinHoursBefore
+	})
+
+	return &CancellationPolicy{
+		AllowCancelHours: allowCancelHours,
+		Tiers:            sorted,
+	}
+}
+
+// Evaluate applies the policy to booking at time now.
+//
+// Unpaid (CREATED) bookings can always be cancelled and owe no refund. PAID and
+// CONFIRMED bookings can be cancelled until AllowCancelHours before departure
+// and are refunded according to the first matching tier. Bookings without a
+// departure time predate the policy and get the most generous tier.
+func (p *CancellationPolicy) Evaluate(booking *entity.Booking, now time.Time) (*CancellationDecision, error) {
+	switch booking.Status {
+	case entity.StatusCreated:
+		return &CancellationDecision{RefundAmount: money.Zero(booking.PriceTotal.Currency())}, nil
+	case entity.StatusPaid, entity.StatusConfirmed:
+	default:
+		return nil, apperrors.ErrBookingNotCancellable
+	}
+
+	if booking.DepartureAt == nil {
+		return p.decision(booking, p.Tiers[0].Percent)
+	}
+
+	hoursBefore := booking.DepartureAt.Sub(now).Hours()
+	if hoursBefore < float64(p.AllowCancelHours) {
+		return nil, apperrors.ErrCancellationWindowClosed
+	}
+
+	for _, tier := range p.Tiers {
+		if hoursBefore >= float64(tier.MinHoursBefore) {
+			return p.decision(booking, tier.Percent)
+		}
+	}
+
+	return p.decision(booking, 0)
+}
+
+// decision rounds refunds down so partial refunds never exceed the tier.
+func (p *CancellationPolicy) decision(booking *entity.Booking, percent int) (*CancellationDecision, error) {
+	amount, err := booking.PriceTotal.Percent(percent, money.RoundDown)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CancellationDecision{
+		RefundPercent: percent,
+		RefundAmount:  amount,
+	}, nil
+}
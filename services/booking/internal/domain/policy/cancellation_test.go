@@ -0,0 +1,85 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+func TestCancellationPolicyEvaluate(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	p := NewCancellationPolicy(2, nil)
+
+	tests := []struct {
+		name        string
+		status      entity.BookingStatus
+		before      time.Duration
+		noDeparture bool
+		wantPercent int
+		wantAmount  int64
+		wantErr     error
+	}{
+		{name: "unpaid inside window", status: entity.StatusCreated, before: time.Hour, wantAmount: 0},
+		{name: "at 72h", status: entity.StatusPaid, before: 72 * time.Hour, wantPercent: 100, wantAmount: 99999},
+		{name: "just under 72h", status: entity.StatusPaid, before: 72*time.Hour - time.Second, wantPercent: 75, wantAmount: 74999},
+		{name: "at 24h", status: entity.StatusConfirmed, before: 24 * time.Hour, wantPercent: 75, wantAmount: 74999},
+		{name: "just under 24h", status: entity.StatusConfirmed, before: 24*time.Hour - time.Second, wantPercent: 50, wantAmount: 49999},
+		{name: "at the cut-off", status: entity.StatusPaid, before: 2 * time.Hour, wantPercent: 50, wantAmount: 49999},
+		{name: "window closed", status: entity.StatusPaid, before: 2*time.Hour - time.Second, wantErr: apperrors.ErrCancellationWindowClosed},
+		{name: "departed", status: entity.StatusConfirmed, before: -time.Hour, wantErr: apperrors.ErrCancellationWindowClosed},
+		{name: "no departure", status: entity.StatusPaid, noDeparture: true, wantPercent: 100, wantAmount: 99999},
+		{name: "expired", status: entity.StatusExpired, before: 96 * time.Hour, wantErr: apperrors.ErrBookingNotCancellable},
+		{name: "already cancelled", status: entity.StatusCancelled, before: 96 * time.Hour, wantErr: apperrors.ErrBookingNotCancellable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := &entity.Booking{Status: tt.status, PriceTotal: money.MustNew(99999, "IDR")}
+			if !tt.noDeparture {
+				departure := now.Add(tt.before)
+				booking.DepartureAt = &departure
+			}
+
+			decision, err := p.Evaluate(booking, now)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("evaluate: %v", err)
+			}
+			if decision.RefundPercent != tt.wantPercent || decision.RefundAmount != money.MustNew(tt.wantAmount, "IDR") {
+				t.Errorf("got %d%% = %v, want %d%% = %d", decision.RefundPercent, decision.RefundAmount, tt.wantPercent, tt.wantAmount)
+			}
+		})
+	}
+}
+
+func TestCancellationPolicyCustomTiers(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	// Unsorted on purpose, and without a tier down to the cut-off
+	p := NewCancellationPolicy(0, []RefundTier{{MinHoursBefore: 12, Percent: 40}, {MinHoursBefore: 48, Percent: 90}})
+
+	for before, want := range map[time.Duration]int{
+		48 * time.Hour: 90,
+		47 * time.Hour: 40,
+		12 * time.Hour: 40,
+		11 * time.Hour: 0,
+	} {
+		departure := now.Add(before)
+		booking := &entity.Booking{Status: entity.StatusPaid, PriceTotal: money.MustNew(1000, "USD"), DepartureAt: &departure}
+		decision, err := p.Evaluate(booking, now)
+		if err != nil {
+			t.Fatalf("%s before: %v", before, err)
+		}
+		if decision.RefundPercent != want || decision.RefundAmount.Amount() != int64(10*want) {
+			t.Errorf("%s before: got %d%% = %v, want %d%%", before, decision.RefundPercent, decision.RefundAmount, want)
+		}
+	}
+}
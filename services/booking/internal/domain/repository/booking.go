@@ -2,14 +2,60 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
 )
 
 type BookingRepository interface {
 	Create(ctx context.Context, booking *entity.Booking) error
-	GetByID(ctx context.Context, id int64) (*entity.Booking, error)
+	GetByID(ctx context.Context, id int64, opts ...QueryOption) (*entity.Booking, error)
 	Update(ctx context.Context, booking *entity.Booking) error
+	// Delete soft-deletes the booking; the row is kept for financial history.
 	Delete(ctx context.Context, id int64) error
-	List(ctx context.Context, limit, offset int) ([]*entity.Booking, error)
+	List(ctx context.Context, filter entity.BookingFilter, limit, offset int, opts ...QueryOption) ([]*entity.Booking, error)
+	// CancelPassenger stores the cancellation of an active passenger, returning
+	// ErrPassengerNotFound if there is none with its ID on its booking.
+	CancelPassenger(ctx context.Context, passenger *entity.Passenger) error
+	// BookedSeats returns those of seats that an active passenger of a live
+	// booking already has on the departure.
+	BookedSeats(ctx context.Context, routeID int64, departureAt *time.Time, seats []string) ([]string, error)
+}
+
+// ArchiveRepository moves old bookings out of the live table.
+type ArchiveRepository interface {
+	// Archive moves up to limit bookings that are terminal or soft-deleted and
+	// were last updated before the cutoff, returning how many were moved.
+	Archive(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+// QueryOptions controls which rows repository reads return.
+type QueryOptions struct {
+	IncludeDeleted bool
+	ForUpdate      bool
+}
+
+type QueryOption func(*QueryOptions)
+
+// IncludeDeleted makes reads return soft-deleted rows as well.
+func IncludeDeleted() QueryOption {
+	return func(o *QueryOptions) {
+		o.IncludeDeleted = true
+	}
+}
+
+// ForUpdate locks the selected rows until the surrounding transaction ends.
+func ForUpdate() QueryOption {
+	return func(o *QueryOptions) {
+		o.ForUpdate = true
+	}
+}
+
+// ApplyQueryOptions folds opts into a QueryOptions value.
+func ApplyQueryOptions(opts ...QueryOption) QueryOptions {
+	var o QueryOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
 }
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+// ExportRepository reads bookings in bulk without holding them all in memory.
+type ExportRepository interface {
+	// Stream calls fn for every live booking matching filter, oldest first,
+	// with its passengers loaded. It stops at the first error fn returns.
+	Stream(ctx context.Context, filter entity.BookingFilter, fn func(*entity.Booking) error) error
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type BookingHistoryRepository interface {
+	Append(ctx context.Context, entry *entity.BookingHistoryEntry) error
+	ListByBookingID(ctx context.Context, bookingID int64) ([]*entity.BookingHistoryEntry, error)
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+// HoldStore keeps holds until their ExpiresAt, after which they and their
+// seats are released without further calls.
+type HoldStore interface {
+	// Create stores hold, returning ErrSeatUnavailable if another live hold
+	// has any of its seats on the same departure of the route.
+	Create(ctx context.Context, hold *entity.Hold) error
+	// Get returns a live hold, or ErrHoldNotFound once it expired or was released.
+	Get(ctx context.Context, id string) (*entity.Hold, error)
+	// Extend moves a live hold's expiry to expiresAt and marks it extended,
+	// returning ErrHoldAlreadyExtended if it already was.
+	Extend(ctx context.Context, id string, expiresAt time.Time) (*entity.Hold, error)
+	// Release deletes the hold and frees its seats.
+	Release(ctx context.Context, id string) error
+}
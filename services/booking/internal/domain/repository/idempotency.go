@@ -0,0 +1,10 @@
+package repository
+
+import "context"
+
+type IdempotencyRepository interface {
+	// GetBookingID returns the booking created under key, or ErrBookingNotFound.
+	GetBookingID(ctx context.Context, key string) (int64, error)
+	// Save binds key to bookingID, returning ErrIdempotencyKeyExists if the key is taken.
+	Save(ctx context.Context, key string, bookingID int64) error
+}
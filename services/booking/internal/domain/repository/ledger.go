@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/pkg/ledger"
+)
+
+// LedgerRepository is implemented by ledger.Store.
+type LedgerRepository interface {
+	Open(ctx context.Context, accounts ...ledger.Account) error
+	Post(ctx context.Context, entry *ledger.Entry) error
+	Entries(ctx context.Context, filter ledger.Filter, limit, offset int) ([]*ledger.Entry, error)
+	Balances(ctx context.Context, filter ledger.Filter) ([]*ledger.Balance, error)
+}
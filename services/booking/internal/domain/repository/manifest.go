@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type ManifestRepository interface {
+	// Stream calls fn for each active passenger of the departure's confirmed
+	// bookings, ordered by seat, without loading the whole manifest. An error
+	// from fn stops the stream and is returned.
+	Stream(ctx context.Context, filter entity.ManifestFilter, fn func(*entity.ManifestEntry) error) error
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type OutboxRepository interface {
+	Append(ctx context.Context, event *entity.OutboxEvent) error
+	List(ctx context.Context, filter entity.OutboxFilter, limit int) ([]*entity.OutboxEvent, error)
+	// FetchPending locks up to limit unpublished events that are due, oldest
+	// first. It must run inside a transaction; rows locked by other relays and
+	// dead-lettered events are skipped.
+	FetchPending(ctx context.Context, limit int) ([]*entity.OutboxEvent, error)
+	MarkPublished(ctx context.Context, ids []int64) error
+	// RecordFailure stores the publishers that handled event so far and its
+	// attempts, next attempt, last error and dead-letter time.
+	RecordFailure(ctx context.Context, event *entity.OutboxEvent) error
+	// MarkPending makes matching published or dead-lettered events pending
+	// again with a fresh retry budget, returning how many were reset.
+	MarkPending(ctx context.Context, filter entity.OutboxFilter) (int, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type PaymentEventRepository interface {
+	// Save records a processed event, returning ErrDuplicatePaymentEvent if
+	// the provider already delivered it.
+	Save(ctx context.Context, event *entity.PaymentEvent) error
+	// Get returns a recorded event, or ErrPaymentEventNotFound.
+	Get(ctx context.Context, provider, eventID string) (*entity.PaymentEvent, error)
+}
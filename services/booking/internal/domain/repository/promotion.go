@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type PromotionRepository interface {
+	// Create stores the promotion, or returns ErrPromotionCodeExists.
+	Create(ctx context.Context, promotion *entity.Promotion) error
+	// GetByID returns the promotion, or ErrPromotionNotFound.
+	GetByID(ctx context.Context, id int64) (*entity.Promotion, error)
+	// GetByCode returns the promotion of tenantID with code, or
+	// ErrPromotionNotFound. ForUpdate holds its redemption count until the
+	// transaction ends.
+	GetByCode(ctx context.Context, tenantID, code string, opts ...QueryOption) (*entity.Promotion, error)
+	List(ctx context.Context, limit, offset int) ([]*entity.Promotion, error)
+	// Update stores the settings of a promotion; its code and redemption
+	// count are kept.
+	Update(ctx context.Context, promotion *entity.Promotion) error
+	// CountRedemptions returns how many bookings of userID hold the promotion.
+	CountRedemptions(ctx context.Context, promotionID, userID int64) (int, error)
+	// Redeem records the redemption and counts it on its promotion.
+	Redeem(ctx context.Context, redemption *entity.PromotionRedemption) error
+	// Release removes the redemption of a booking, if any, and hands it back
+	// to its promotion.
+	Release(ctx context.Context, bookingID int64) (bool, error)
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type ReconciliationRepository interface {
+	// SaveFile records a settlement file with its settlements, filling in
+	// their IDs. It returns false and saves nothing if a file with the same
+	// checksum was ingested before.
+	SaveFile(ctx context.Context, file *entity.SettlementFile, settlements []*entity.Settlement) (bool, error)
+	// Reconcile matches settlements against paid bookings, opening
+	// discrepancies it finds and resolving open ones it no longer finds.
+	// Bookings paid from paidFrom until paidBefore must be settled; older
+	// ones are only checked while they have an open discrepancy, and so are
+	// settlements ingested before paidFrom. Concurrent calls wait for each
+	// other.
+	Reconcile(ctx context.Context, paidFrom, paidBefore time.Time) (opened, resolved int, err error)
+	SaveRun(ctx context.Context, run *entity.ReconciliationRun) error
+	// LastRun returns the latest run, nil before the first.
+	LastRun(ctx context.Context) (*entity.ReconciliationRun, error)
+	ListDiscrepancies(ctx context.Context, filter entity.DiscrepancyFilter, limit, offset int) ([]*entity.Discrepancy, error)
+	CountOpen(ctx context.Context) (map[entity.DiscrepancyKind]int, error)
+}
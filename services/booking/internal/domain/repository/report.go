@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type ReportRepository interface {
+	// Refresh recomputes the rollup buckets of bookings updated since the
+	// last refresh, reaching back overlap further for transactions that
+	// committed late, or every bucket when full is set. It returns how many
+	// buckets it recomputed. Concurrent refreshes wait for each other.
+	Refresh(ctx context.Context, full bool, overlap time.Duration) (int, error)
+	// RefreshedUntil returns when the last refresh started, nil before the first.
+	RefreshedUntil(ctx context.Context) (*time.Time, error)
+
+	Revenue(ctx context.Context, query *entity.ReportQuery) ([]*entity.RevenueRow, error)
+	Funnel(ctx context.Context, query *entity.ReportQuery) ([]*entity.FunnelRow, error)
+	Statuses(ctx context.Context, query *entity.ReportQuery) ([]*entity.StatusRow, error)
+}
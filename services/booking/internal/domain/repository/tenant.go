@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type TenantRepository interface {
+	// Create stores the tenant, or returns ErrTenantExists.
+	Create(ctx context.Context, tenant *entity.Tenant) error
+	// GetByID returns the tenant, or ErrTenantNotFound.
+	GetByID(ctx context.Context, id string) (*entity.Tenant, error)
+	List(ctx context.Context, limit, offset int) ([]*entity.Tenant, error)
+	// Update stores the name and settings of the tenant.
+	Update(ctx context.Context, tenant *entity.Tenant) error
+}
+
+type RouteRepository interface {
+	// Claim registers the route to tenantID unless it is registered already,
+	// and returns ErrForeignRoute if it belongs to another tenant.
+	Claim(ctx context.Context, routeID int64, tenantID string) error
+	// Save registers the route to its tenant or renames it, and returns
+	// ErrForeignRoute if it belongs to another tenant.
+	Save(ctx context.Context, route *entity.Route) error
+	// List returns the routes of tenantID by ID.
+	List(ctx context.Context, tenantID string, limit, offset int) ([]*entity.Route, error)
+}
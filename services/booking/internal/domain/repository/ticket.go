@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type TicketRepository interface {
+	// Create stores the ticket unless its booking already has one with the
+	// same Seq, reporting whether it was stored.
+	Create(ctx context.Context, ticket *entity.Ticket) (bool, error)
+	// GetByID returns the ticket, or ErrTicketNotFound when it is unknown or
+	// its booking belongs to another tenant than the one in ctx.
+	GetByID(ctx context.Context, id string) (*entity.Ticket, error)
+	ListByBookingID(ctx context.Context, bookingID int64) ([]*entity.Ticket, error)
+	// MarkUsed records the first use of an issued ticket of a CONFIRMED
+	// booking and checks in its passenger, in one statement. It returns
+	// ErrTicketNotFound when the ticket is unknown, revoked or already used,
+	// or its booking is not confirmed or of another tenant.
+	MarkUsed(ctx context.Context, id string, at time.Time, by string) (*entity.Ticket, error)
+	// RevokeByBooking revokes every issued ticket of the booking.
+	RevokeByBooking(ctx context.Context, bookingID int64, at time.Time) (int, error)
+	// RevokeByPassenger revokes the issued ticket of one passenger.
+	RevokeByPassenger(ctx context.Context, passengerID int64, at time.Time) (int, error)
+}
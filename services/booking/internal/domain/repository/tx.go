@@ -0,0 +1,11 @@
+package repository
+
+import "context"
+
+// Transactor groups repository calls into a single atomic unit of work.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// WithinSavepoint runs fn so that its error undoes only its own writes,
+	// leaving an enclosing transaction usable.
+	WithinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error
+}
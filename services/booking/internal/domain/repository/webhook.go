@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type WebhookSubscriptionRepository interface {
+	Create(ctx context.Context, subscription *entity.WebhookSubscription) error
+	// GetByID returns the subscription, or ErrWebhookSubscriptionNotFound.
+	GetByID(ctx context.Context, id int64) (*entity.WebhookSubscription, error)
+	List(ctx context.Context, limit, offset int) ([]*entity.WebhookSubscription, error)
+	// ListActive returns every active subscription of the tenant wanting
+	// eventType.
+	ListActive(ctx context.Context, tenantID, eventType string) ([]*entity.WebhookSubscription, error)
+	// Deactivate stops deliveries to the subscription; history is kept.
+	Deactivate(ctx context.Context, id int64) error
+}
+
+type WebhookDeliveryRepository interface {
+	// Enqueue schedules delivery of an event to a subscription. An existing
+	// delivery of the same event is reset to pending with fresh attempts.
+	Enqueue(ctx context.Context, delivery *entity.WebhookDelivery) error
+	// ClaimDue returns up to limit pending deliveries whose next attempt is
+	// due and pushes their next attempt out by lease, so concurrent workers
+	// do not pick them up while they are in flight.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookDelivery, error)
+	// RecordAttempt logs attempt and stores the delivery's resulting status,
+	// attempt count, next attempt time and last error.
+	RecordAttempt(ctx context.Context, delivery *entity.WebhookDelivery, attempt *entity.WebhookAttempt) error
+	// GetByID returns the delivery with its attempt log, or ErrWebhookDeliveryNotFound.
+	GetByID(ctx context.Context, id int64) (*entity.WebhookDelivery, error)
+	List(ctx context.Context, filter entity.WebhookDeliveryFilter, limit, offset int) ([]*entity.WebhookDelivery, error)
+	// Redeliver makes a delivery pending and due now with a fresh attempt budget.
+	Redeliver(ctx context.Context, id int64) error
+}
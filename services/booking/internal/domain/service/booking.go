@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
 )
@@ -10,6 +11,17 @@ type BookingService interface {
 	CreateBooking(ctx context.Context, booking *entity.Booking) error
 	GetBooking(ctx context.Context, id int64) (*entity.Booking, error)
 	UpdateBooking(ctx context.Context, booking *entity.Booking) error
-	CancelBooking(ctx context.Context, id int64) error
-	ListBookings(ctx context.Context, limit, offset int) ([]*entity.Booking, error)
+	CancelBooking(ctx context.Context, id int64, reason entity.CancellationReason) (*entity.Cancellation, error)
+	// CancelPassenger takes one passenger off a booking, reducing qty and price
+	// by the passenger's share and refunding it under the cancellation policy.
+	// Cancelling the last active passenger cancels the booking.
+	CancelPassenger(ctx context.Context, bookingID, passengerID int64, reason entity.CancellationReason) (*entity.Cancellation, error)
+	ListBookings(ctx context.Context, filter entity.BookingFilter, limit, offset int) ([]*entity.Booking, error)
+	GetBookingHistory(ctx context.Context, id int64) ([]*entity.BookingHistoryEntry, error)
+	// TransitionStatus moves a booking to status if the lifecycle allows it,
+	// recording history and emitting the matching status event.
+	TransitionStatus(ctx context.Context, id int64, status entity.BookingStatus) (*entity.Booking, error)
+	// ExpireBookings expires up to limit unpaid bookings created before the
+	// cutoff and returns how many were expired.
+	ExpireBookings(ctx context.Context, createdBefore time.Time, limit int) (int, error)
 }
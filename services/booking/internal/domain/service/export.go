@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type ExportService interface {
+	// ExportBookings calls fn for every booking matching filter, oldest first.
+	ExportBookings(ctx context.Context, filter entity.BookingFilter, fn func(*entity.Booking) error) error
+}
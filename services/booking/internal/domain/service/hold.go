@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type HoldService interface {
+	// CreateHold reserves the hold's seats for the configured TTL.
+	CreateHold(ctx context.Context, hold *entity.Hold) error
+	GetHold(ctx context.Context, id string) (*entity.Hold, error)
+	// ExtendHold pushes a hold's expiry out once; later calls fail with
+	// ErrHoldAlreadyExtended.
+	ExtendHold(ctx context.Context, id string) (*entity.Hold, error)
+	// ConvertHold creates the booking for a live hold and releases it. Held
+	// seats are handed to passengers that have none. Converting the same hold
+	// again returns the same booking.
+	ConvertHold(ctx context.Context, id string, passengers []*entity.Passenger) (*entity.Booking, error)
+	ReleaseHold(ctx context.Context, id string) error
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type ImportService interface {
+	// ImportBookings creates the bookings of rows with the same rules as
+	// CreateBooking and reports the outcome of each row.
+	ImportBookings(ctx context.Context, rows []*entity.ImportRow, mode entity.ImportMode) (*entity.ImportResult, error)
+}
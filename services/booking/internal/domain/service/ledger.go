@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/pkg/ledger"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+// LedgerService books money movements in the double-entry ledger. Each
+// movement is posted once however often it is recorded, so callers may
+// retry. As an EventPublisher it books the refunds owed on cancellation.
+type LedgerService interface {
+	EventPublisher
+
+	// RecordPayment books money a provider collected for a booking.
+	RecordPayment(ctx context.Context, event *entity.PaymentEvent) error
+	// RecordRefund books money a provider paid back to a customer.
+	RecordRefund(ctx context.Context, event *entity.PaymentEvent) error
+	// RecordSettlement books a provider payout to the bank and the fee the
+	// provider kept from it.
+	RecordSettlement(ctx context.Context, settlement *entity.Settlement) error
+
+	ListEntries(ctx context.Context, filter ledger.Filter, limit, offset int) ([]*ledger.Entry, error)
+	Balances(ctx context.Context, filter ledger.Filter) ([]*ledger.Balance, error)
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type ManifestService interface {
+	// StreamManifest passes the departure's manifest to fn line by line.
+	StreamManifest(ctx context.Context, filter entity.ManifestFilter, fn func(*entity.ManifestEntry) error) error
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type PaymentService interface {
+	// HandlePaymentEvent applies a provider notification to its booking and
+	// records the outcome. A redelivered event changes nothing and returns the
+	// recorded event together with ErrDuplicatePaymentEvent.
+	HandlePaymentEvent(ctx context.Context, event *entity.PaymentEvent) (*entity.PaymentEvent, error)
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type PromotionService interface {
+	// Publish hands the redemption of an expired booking back to its
+	// promotion.
+	EventPublisher
+
+	CreatePromotion(ctx context.Context, promotion *entity.Promotion) error
+	GetPromotion(ctx context.Context, id int64) (*entity.Promotion, error)
+	ListPromotions(ctx context.Context, limit, offset int) ([]*entity.Promotion, error)
+	// UpdatePromotion replaces the settings of a promotion; its code cannot
+	// change.
+	UpdatePromotion(ctx context.Context, promotion *entity.Promotion) (*entity.Promotion, error)
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+// EventPublisher receives outbox events once the change that produced them
+// has committed. Publish runs in the relay's transaction.
+type EventPublisher interface {
+	Publish(ctx context.Context, event *entity.OutboxEvent) error
+}
+
+// NamedPublisher is an EventPublisher the outbox relay tracks by Name. Names
+// are stored with each event, so renaming one publishes old events again.
+type NamedPublisher struct {
+	Name      string
+	Publisher EventPublisher
+}
+
+// Publishers are handed each event in order. The relay isolates them: one
+// failing publisher neither rolls back nor repeats the others.
+type Publishers []NamedPublisher
@@ -0,0 +1,15 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type ReconciliationService interface {
+	// Reconcile ingests settlement files not seen before and reconciles them
+	// with paid bookings. Files that cannot be read are reported in the run
+	// and retried next time.
+	Reconcile(ctx context.Context) (*entity.ReconciliationRun, error)
+	ReconciliationReport(ctx context.Context, filter entity.DiscrepancyFilter, limit, offset int) (*entity.ReconciliationReport, error)
+}
@@ -0,0 +1,17 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type ReportService interface {
+	RevenueReport(ctx context.Context, query *entity.ReportQuery) (*entity.Report, error)
+	FunnelReport(ctx context.Context, query *entity.ReportQuery) (*entity.Report, error)
+	StatusReport(ctx context.Context, query *entity.ReportQuery) (*entity.Report, error)
+	// RefreshReports brings the rollups up to date with bookings changed
+	// since the last refresh, or rebuilds them all when full is set, and
+	// returns how many hourly buckets were recomputed.
+	RefreshReports(ctx context.Context, full bool) (int, error)
+}
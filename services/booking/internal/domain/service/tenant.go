@@ -0,0 +1,21 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type TenantService interface {
+	CreateTenant(ctx context.Context, tenant *entity.Tenant) error
+	// GetTenant returns the tenant, possibly as it was up to the cache TTL
+	// ago, or ErrTenantNotFound.
+	GetTenant(ctx context.Context, id string) (*entity.Tenant, error)
+	ListTenants(ctx context.Context, limit, offset int) ([]*entity.Tenant, error)
+	// UpdateTenant replaces the name and settings of a tenant.
+	UpdateTenant(ctx context.Context, tenant *entity.Tenant) (*entity.Tenant, error)
+
+	// RegisterRoute registers a route to the tenant in ctx, or renames it.
+	RegisterRoute(ctx context.Context, route *entity.Route) error
+	ListRoutes(ctx context.Context, limit, offset int) ([]*entity.Route, error)
+}
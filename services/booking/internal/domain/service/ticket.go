@@ -0,0 +1,26 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type TicketService interface {
+	// Publish issues tickets when a booking is confirmed and revokes them
+	// when the booking or one of its passengers is cancelled.
+	EventPublisher
+
+	// ListTickets returns the booking's tickets, issued ones with their QR code.
+	ListTickets(ctx context.Context, bookingID int64) ([]*entity.Ticket, error)
+	// TicketQRCode renders an issued ticket of the booking as a PNG.
+	TicketQRCode(ctx context.Context, bookingID int64, ticketID string) ([]byte, error)
+
+	// CheckIn validates a scanned token for a departure and uses the ticket
+	// up. Denials are results with a reason, not errors.
+	CheckIn(ctx context.Context, checkIn *entity.CheckIn) (*entity.CheckInResult, error)
+	// VerificationKeys returns the public keys tokens are verified against,
+	// for scanners that check tickets offline.
+	VerificationKeys() []ed25519.PublicKey
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+type WebhookService interface {
+	// Publish fans the event out into a delivery per matching subscription.
+	EventPublisher
+
+	// CreateSubscription stores the subscription, generating a secret when
+	// none is given. Only its return value carries the secret.
+	CreateSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error
+	GetSubscription(ctx context.Context, id int64) (*entity.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, limit, offset int) ([]*entity.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+
+	ListDeliveries(ctx context.Context, filter entity.WebhookDeliveryFilter, limit, offset int) ([]*entity.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, id int64) (*entity.WebhookDelivery, error)
+	// Redeliver schedules a delivery again, e.g. after it was dead-lettered.
+	Redeliver(ctx context.Context, id int64) (*entity.WebhookDelivery, error)
+}
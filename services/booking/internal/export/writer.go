@@ -0,0 +1,124 @@
+// Package export renders booking exports for reconciliation, one line per
+// booking, as they are read from the database.
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// Writer renders bookings. Nothing is written before the first booking or
+// Close, so a failure before then can still be reported otherwise.
+type Writer interface {
+	Write(booking *entity.Booking) error
+	// Close finishes the export and flushes it; it must be called even when
+	// there were no bookings.
+	Close() error
+}
+
+// ContentType returns the media type of an export format.
+func ContentType(format string) string {
+	if format == FormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// NewWriter returns a Writer for format ("csv" or "ndjson") over w.
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{w: bufio.NewWriter(w)}, nil
+	default:
+		return nil, apperrors.ErrInvalidExportFormat
+	}
+}
+
+// Amounts are in minor units so totals add up without rounding.
+var csvHeader = []string{
+	"id", "user_id", "route_id", "qty", "passengers", "status", "price_total", "currency",
+	"departure_at", "cancel_reason", "cancelled_at", "created_at", "updated_at",
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	started bool
+}
+
+func (c *csvWriter) Write(booking *entity.Booking) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+
+	return c.w.Write([]string{
+		strconv.FormatInt(booking.ID, 10),
+		strconv.FormatInt(booking.UserID, 10),
+		strconv.FormatInt(booking.RouteID, 10),
+		strconv.Itoa(booking.Qty),
+		strconv.Itoa(len(booking.ActivePassengers())),
+		string(booking.Status),
+		strconv.FormatInt(booking.PriceTotal.Amount(), 10),
+		string(booking.PriceTotal.Currency()),
+		formatTime(booking.DepartureAt),
+		string(booking.CancelReason),
+		formatTime(booking.CancelledAt),
+		formatTime(&booking.CreatedAt),
+		formatTime(&booking.UpdatedAt),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	return c.w.Write(csvHeader)
+}
+
+// ndjsonWriter writes each booking as it is returned by the API, passengers
+// included, on a line of its own.
+type ndjsonWriter struct {
+	w *bufio.Writer
+}
+
+func (n *ndjsonWriter) Write(booking *entity.Booking) error {
+	data, err := json.Marshal(booking)
+	if err != nil {
+		return err
+	}
+	n.w.Write(data)
+	return n.w.WriteByte('\n')
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,86 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+func TestWriterFormats(t *testing.T) {
+	created := time.Date(2025, 10, 1, 9, 30, 0, 0, time.UTC)
+	departure := time.Date(2025, 10, 10, 8, 0, 0, 0, time.UTC)
+	cancelled := created.Add(time.Hour)
+	bookings := []*entity.Booking{
+		{ID: 1, UserID: 9, RouteID: 7, Qty: 2, Status: entity.StatusConfirmed,
+			PriceTotal: money.MustNew(300000, "IDR"), DepartureAt: &departure,
+			CreatedAt: created, UpdatedAt: created,
+			Passengers: []*entity.Passenger{
+				{ID: 3, FullName: "Siti Rahma", Status: entity.PassengerActive},
+				{ID: 4, FullName: "Budi Santoso", Status: entity.PassengerCancelled},
+			}},
+		{ID: 2, UserID: 9, RouteID: 7, Qty: 1, Status: entity.StatusCancelled,
+			PriceTotal: money.MustNew(150000, "IDR"), CancelReason: entity.CancelReasonCustomerRequest,
+			CancelledAt: &cancelled, CreatedAt: created, UpdatedAt: cancelled},
+	}
+
+	render := func(format string) string {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		for _, booking := range bookings {
+			if err := w.Write(booking); err != nil {
+				t.Fatalf("%s write: %v", format, err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%s close: %v", format, err)
+		}
+		return buf.String()
+	}
+
+	wantCSV := "id,user_id,route_id,qty,passengers,status,price_total,currency,departure_at,cancel_reason,cancelled_at,created_at,updated_at\n" +
+		"1,9,7,2,1,CONFIRMED,300000,IDR,2025-10-10T08:00:00Z,,,2025-10-01T09:30:00Z,2025-10-01T09:30:00Z\n" +
+		"2,9,7,1,0,CANCELLED,150000,IDR,,CUSTOMER_REQUEST,2025-10-01T10:30:00Z,2025-10-01T09:30:00Z,2025-10-01T10:30:00Z\n"
+	if got := render(FormatCSV); got != wantCSV {
+		t.Errorf("csv:\n%s\nwant:\n%s", got, wantCSV)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewBufferString(render(FormatNDJSON)))
+	var lines []*entity.Booking
+	for scanner.Scan() {
+		var booking entity.Booking
+		if err := json.Unmarshal(scanner.Bytes(), &booking); err != nil {
+			t.Fatalf("ndjson line %d: %v", len(lines)+1, err)
+		}
+		lines = append(lines, &booking)
+	}
+	if len(lines) != 2 || lines[0].ID != 1 || len(lines[0].Passengers) != 2 || lines[1].Status != entity.StatusCancelled {
+		t.Errorf("ndjson: unexpected bookings %+v", lines)
+	}
+
+	if _, err := NewWriter(&bytes.Buffer{}, "xlsx"); err == nil {
+		t.Error("xlsx: expected an unknown format error")
+	}
+}
+
+func TestWriterEmptyExport(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, FormatCSV)
+	if buf.Len() != 0 {
+		t.Fatal("writer wrote before the first booking")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	if want := "id,user_id,route_id,qty,passengers,status,price_total,currency,departure_at,cancel_reason,cancelled_at,created_at,updated_at\n"; buf.String() != want {
+		t.Errorf("got %q want %q", buf.String(), want)
+	}
+}
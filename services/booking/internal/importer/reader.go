@@ -0,0 +1,243 @@
+// Package importer reads bulk booking imports, CSV or NDJSON, into rows that
+// are then created one booking at a time.
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// maxLineSize bounds one NDJSON line, a booking with all its passengers.
+const maxLineSize = 1 << 20
+
+// Read returns the rows of an import in file order. Problems confined to one
+// row are set on that row; a file that cannot be read as format fails with
+// ErrInvalidImport.
+//
+// CSV files have a header row naming their columns. Lines sharing a ref
+// make one booking with a passenger per line; booking columns only need
+// values on the first of them. Prices are decimal amounts in major units,
+// e.g. 1250.50. NDJSON files hold one booking per line, shaped like the
+// create booking request, with an optional ref.
+func Read(r io.Reader, format string) ([]*entity.ImportRow, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatNDJSON:
+		return readNDJSON(r)
+	default:
+		return nil, apperrors.ErrInvalidImportFormat
+	}
+}
+
+var (
+	bookingColumns   = []string{"user_id", "route_id", "qty", "price_total", "currency", "departure_at"}
+	passengerColumns = []string{"full_name", "document_type", "document_number", "date_of_birth", "seat"}
+	requiredColumns  = []string{"user_id", "route_id", "price_total", "currency"}
+)
+
+// csvGroup collects the lines of one booking.
+type csvGroup struct {
+	row     *entity.ImportRow
+	first   map[string]string
+	firstAt int
+}
+
+func readCSV(r io.Reader) ([]*entity.ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, apperrors.ErrInvalidImport
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, apperrors.ErrInvalidImport
+		}
+	}
+
+	var (
+		rows   []*entity.ImportRow
+		groups = map[string]*csvGroup{}
+	)
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, apperrors.ErrInvalidImport
+		}
+		line, _ := cr.FieldPos(0)
+
+		values := make(map[string]string, len(columns))
+		blank := true
+		for name, i := range columns {
+			if i < len(record) {
+				values[name] = strings.TrimSpace(record[i])
+				blank = blank && values[name] == ""
+			}
+		}
+		if blank {
+			continue
+		}
+
+		ref := values["ref"]
+		group := groups[ref]
+		if group == nil || ref == "" {
+			group = &csvGroup{row: &entity.ImportRow{Ref: ref}, first: values, firstAt: line}
+			rows = append(rows, group.row)
+			if ref != "" {
+				groups[ref] = group
+			}
+		}
+		group.row.Lines = append(group.row.Lines, line)
+
+		// Only the first problem of a booking is reported
+		fail := func(err error) {
+			if err != nil && group.row.Err == nil {
+				group.row.Err = fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		if line == group.firstAt {
+			booking, err := parseBooking(values)
+			group.row.Booking = booking
+			fail(err)
+		} else {
+			fail(sameBooking(group, values))
+		}
+
+		passenger, err := parsePassenger(values)
+		fail(err)
+		if passenger != nil && group.row.Booking != nil {
+			group.row.Booking.Passengers = append(group.row.Booking.Passengers, passenger)
+		}
+	}
+}
+
+// parseBooking reads the booking columns of the first line of a booking.
+func parseBooking(values map[string]string) (*entity.Booking, error) {
+	booking := &entity.Booking{}
+
+	var err error
+	if booking.UserID, err = strconv.ParseInt(values["user_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid user_id %q", values["user_id"])
+	}
+	if booking.RouteID, err = strconv.ParseInt(values["route_id"], 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid route_id %q", values["route_id"])
+	}
+	if v := values["qty"]; v != "" {
+		if booking.Qty, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("invalid qty %q", v)
+		}
+	}
+	currency, err := money.ParseCurrency(values["currency"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid currency %q", values["currency"])
+	}
+	if booking.PriceTotal, err = money.Parse(values["price_total"], currency); err != nil {
+		return nil, fmt.Errorf("invalid price_total %q", values["price_total"])
+	}
+	if v := values["departure_at"]; v != "" {
+		departureAt, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid departure_at %q: expected RFC 3339 timestamp", v)
+		}
+		booking.DepartureAt = &departureAt
+	}
+
+	return booking, nil
+}
+
+// sameBooking checks that a later line of a booking repeats its booking
+// columns, if it fills them at all.
+func sameBooking(group *csvGroup, values map[string]string) error {
+	for _, name := range bookingColumns {
+		if v := values[name]; v != "" && v != group.first[name] {
+			return fmt.Errorf("%s %q differs from line %d of ref %q", name, v, group.firstAt, group.row.Ref)
+		}
+	}
+	return nil
+}
+
+// parsePassenger reads the passenger columns of a line, nil if they are empty.
+func parsePassenger(values map[string]string) (*entity.Passenger, error) {
+	empty := true
+	for _, name := range passengerColumns {
+		empty = empty && values[name] == ""
+	}
+	if empty {
+		return nil, nil
+	}
+
+	passenger := &entity.Passenger{
+		FullName:       values["full_name"],
+		DocumentType:   entity.DocumentType(strings.ToUpper(values["document_type"])),
+		DocumentNumber: values["document_number"],
+		Seat:           values["seat"],
+	}
+	if v := values["date_of_birth"]; v != "" {
+		dateOfBirth, err := entity.ParseDate(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date_of_birth %q: expected YYYY-MM-DD", v)
+		}
+		passenger.DateOfBirth = dateOfBirth
+	}
+
+	return passenger, nil
+}
+
+func readNDJSON(r io.Reader) ([]*entity.ImportRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	var rows []*entity.ImportRow
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var record struct {
+			Ref string `json:"ref"`
+			entity.Booking
+		}
+		row := &entity.ImportRow{Lines: []int{line}}
+		if err := json.Unmarshal(data, &record); err != nil {
+			row.Err = fmt.Errorf("line %d: invalid JSON: %v", line, err)
+		} else {
+			row.Ref = record.Ref
+			row.Booking = &record.Booking
+		}
+		rows = append(rows, row)
+	}
+	if scanner.Err() != nil {
+		return nil, apperrors.ErrInvalidImport
+	}
+
+	return rows, nil
+}
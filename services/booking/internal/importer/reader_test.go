@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+func TestReadCSVGroupsPassengersByRef(t *testing.T) {
+	file := "\ufeffref,user_id,route_id,price_total,currency,departure_at,full_name,document_type,document_number,date_of_birth,seat\n" +
+		"A1,9,7,300000,IDR,2030-01-02T08:00:00+07:00,Siti Rahma,passport,C1234567,1990-04-12,3A\n" +
+		"A1,,,,,,Budi Santoso,NATIONAL_ID,3171,1988-02-01,3B\n" +
+		",9,8,150000.50,idr,,,,,,\n" +
+		"\n" +
+		"B2,x,7,1,IDR,,,,,,\n" +
+		"A1,9,9,,,,Ani,PASSPORT,C7,2001-01-01,3C\n"
+
+	rows, err := Read(strings.NewReader(file), FormatCSV)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 3", len(rows))
+	}
+
+	group := rows[0]
+	if group.Ref != "A1" || len(group.Lines) != 3 || group.Lines[0] != 2 || group.Lines[2] != 7 {
+		t.Errorf("group lines: %+v", group)
+	}
+	if group.Err == nil || !strings.Contains(group.Err.Error(), `line 7: route_id "9" differs`) {
+		t.Errorf("group error: %v", group.Err)
+	}
+	if b := group.Booking; b.UserID != 9 || b.RouteID != 7 || len(b.Passengers) != 3 || b.Passengers[0].Seat != "3A" ||
+		b.PriceTotal != money.MustNew(30000000, "IDR") || b.DepartureAt == nil {
+		t.Errorf("group booking: %+v", b)
+	}
+
+	if single := rows[1]; single.Err != nil || single.Booking.PriceTotal != money.MustNew(15000050, "IDR") || len(single.Booking.Passengers) != 0 {
+		t.Errorf("single row: %+v err %v", single.Booking, single.Err)
+	}
+	if bad := rows[2]; bad.Err == nil || !strings.HasPrefix(bad.Err.Error(), `line 6: invalid user_id "x"`) {
+		t.Errorf("bad row error: %v", bad.Err)
+	}
+}
+
+func TestReadCSVNeedsRequiredColumns(t *testing.T) {
+	if _, err := Read(strings.NewReader("user_id,route_id,currency\n1,2,IDR\n"), FormatCSV); err == nil {
+		t.Error("expected a missing price_total column to fail the file")
+	}
+}
+
+func TestReadNDJSON(t *testing.T) {
+	file := `{"ref":"x","user_id":9,"route_id":7,"qty":1,"price_total":{"amount":100,"currency":"IDR"}}` + "\n\n" +
+		`{"user_id":` + "\n"
+
+	rows, err := Read(strings.NewReader(file), FormatNDJSON)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(rows) != 2 || rows[0].Ref != "x" || rows[0].Booking.RouteID != 7 || rows[0].Err != nil {
+		t.Fatalf("rows: %+v", rows)
+	}
+	if rows[1].Err == nil || rows[1].Lines[0] != 3 {
+		t.Errorf("bad line: %+v", rows[1])
+	}
+
+	if _, err := Read(strings.NewReader(""), "xlsx"); err == nil {
+		t.Error("xlsx: expected an unknown format error")
+	}
+}
@@ -0,0 +1,71 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+// Archiver periodically moves old terminal and soft-deleted bookings into the
+// partitioned archive table.
+type Archiver struct {
+	archiveRepo repository.ArchiveRepository
+	retention   time.Duration
+	interval    time.Duration
+	batchSize   int
+	log         zerolog.Logger
+}
+
+func NewArchiver(archiveRepo repository.ArchiveRepository, retention, interval time.Duration, batchSize int, log zerolog.Logger) *Archiver {
+	return &Archiver{
+		archiveRepo: archiveRepo,
+		retention:   retention,
+		interval:    interval,
+		batchSize:   batchSize,
+		log:         log,
+	}
+}
+
+// Run archives on every tick until ctx is cancelled.
+func (a *Archiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := a.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			a.log.Error().Err(err).Msg("Booking archival failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce archives in batches until nothing older than the retention is left.
+func (a *Archiver) RunOnce(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-a.retention)
+
+	total := 0
+	for {
+		n, err := a.archiveRepo.Archive(ctx, cutoff, a.batchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < a.batchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		a.log.Info().Int("count", total).Time("cutoff", cutoff).Msg("Archived bookings")
+	}
+
+	return total, nil
+}
@@ -0,0 +1,72 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+// Expirer periodically expires unpaid bookings once their payment window has
+// passed.
+type Expirer struct {
+	bookingService service.BookingService
+	window         time.Duration
+	interval       time.Duration
+	batchSize      int
+	log            zerolog.Logger
+}
+
+func NewExpirer(bookingService service.BookingService, window, interval time.Duration, batchSize int, log zerolog.Logger) *Expirer {
+	return &Expirer{
+		bookingService: bookingService,
+		window:         window,
+		interval:       interval,
+		batchSize:      batchSize,
+		log:            log,
+	}
+}
+
+// Run sweeps on every tick until ctx is cancelled.
+func (e *Expirer) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := e.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			e.log.Error().Err(err).Msg("Booking expiry sweep failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce expires bookings in batches until none past the window are left.
+func (e *Expirer) RunOnce(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-e.window)
+
+	total := 0
+	for {
+		n, err := e.bookingService.ExpireBookings(ctx, cutoff, e.batchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		// Bookings paid in the meantime are skipped, so stop on a short batch
+		if n < e.batchSize {
+			break
+		}
+	}
+
+	if total > 0 {
+		e.log.Info().Int("count", total).Time("cutoff", cutoff).Msg("Expired bookings")
+	}
+
+	return total, nil
+}
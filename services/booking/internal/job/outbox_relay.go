@@ -0,0 +1,173 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+// OutboxRelay hands committed outbox events to publishers and marks them
+// published in the same transaction, so an event is never lost or
+// half-published. Each publisher runs in a savepoint of its own: a failure
+// undoes only its writes, the event is retried later for the publishers that
+// have not handled it yet, and after MaxAttempts it is dead-lettered instead
+// of holding up the events behind it. Several relays may run at once.
+type OutboxRelay struct {
+	outboxRepo repository.OutboxRepository
+	transactor repository.Transactor
+	publishers service.Publishers
+	retry      RetryPolicy
+	interval   time.Duration
+	batchSize  int
+	log        zerolog.Logger
+}
+
+func NewOutboxRelay(
+	outboxRepo repository.OutboxRepository,
+	transactor repository.Transactor,
+	publishers service.Publishers,
+	retry RetryPolicy,
+	interval time.Duration,
+	batchSize int,
+	log zerolog.Logger,
+) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepo: outboxRepo,
+		transactor: transactor,
+		publishers: publishers,
+		retry:      retry,
+		interval:   interval,
+		batchSize:  batchSize,
+		log:        log,
+	}
+}
+
+// Run relays on every tick until ctx is cancelled.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			r.log.Error().Err(err).Msg("Outbox relay failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce relays batches until no due events are left and returns how many
+// events were published.
+func (r *OutboxRelay) RunOnce(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		fetched, published, err := r.relayBatch(ctx)
+		total += published
+		if err != nil {
+			return total, err
+		}
+		if fetched < r.batchSize {
+			return total, nil
+		}
+	}
+}
+
+func (r *OutboxRelay) relayBatch(ctx context.Context) (int, int, error) {
+	fetched, published := 0, 0
+	err := r.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		events, err := r.outboxRepo.FetchPending(ctx, r.batchSize)
+		if err != nil {
+			return err
+		}
+		fetched = len(events)
+
+		ids := make([]int64, 0, len(events))
+		for _, event := range events {
+			failure := r.publish(ctx, event)
+			// Shutting down is not the event's fault; roll back without
+			// using up its attempts.
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if failure == nil {
+				ids = append(ids, event.ID)
+				continue
+			}
+			if err := r.recordFailure(ctx, event, failure); err != nil {
+				return err
+			}
+		}
+		published = len(ids)
+
+		return r.outboxRepo.MarkPublished(ctx, ids)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return fetched, published, nil
+}
+
+// publish hands event to every publisher that has not handled it yet and
+// returns their errors joined.
+func (r *OutboxRelay) publish(ctx context.Context, event *entity.OutboxEvent) error {
+	var failures []error
+	for _, p := range r.publishers {
+		if event.PublishedBy(p.Name) {
+			continue
+		}
+		err := r.transactor.WithinSavepoint(ctx, func(ctx context.Context) error {
+			return p.Publisher.Publish(ctx, event)
+		})
+		if err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", p.Name, err))
+			continue
+		}
+		event.PublishedTo = append(event.PublishedTo, p.Name)
+	}
+
+	return errors.Join(failures...)
+}
+
+func (r *OutboxRelay) recordFailure(ctx context.Context, event *entity.OutboxEvent, failure error) error {
+	now := time.Now()
+	event.Attempts++
+	event.LastError = failure.Error()
+	if event.Attempts >= r.retry.MaxAttempts {
+		event.DeadAt = &now
+		event.NextAttemptAt = nil
+	} else {
+		next := now.Add(r.retry.Delay(event.Attempts))
+		event.NextAttemptAt = &next
+	}
+
+	if err := r.outboxRepo.RecordFailure(ctx, event); err != nil {
+		return err
+	}
+
+	log := r.log.Warn()
+	msg := "Outbox event failed, will retry"
+	if event.DeadAt != nil {
+		log = r.log.Error()
+		msg = "Outbox event dead-lettered"
+	}
+	log.Int64("event_id", event.ID).
+		Str("event_type", event.EventType).
+		Int("attempts", event.Attempts).
+		Strs("published_to", event.PublishedTo).
+		Str("error", event.LastError).
+		Msg(msg)
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+// memoryOutbox keeps events in memory and, like FetchPending, skips published,
+// dead and not yet due ones.
+type memoryOutbox struct {
+	repository.OutboxRepository
+
+	events map[int64]*entity.OutboxEvent
+}
+
+func newMemoryOutbox(types ...string) *memoryOutbox {
+	o := &memoryOutbox{events: make(map[int64]*entity.OutboxEvent)}
+	for i, eventType := range types {
+		id := int64(i + 1)
+		o.events[id] = &entity.OutboxEvent{ID: id, EventType: eventType}
+	}
+	return o
+}
+
+func (o *memoryOutbox) FetchPending(ctx context.Context, limit int) ([]*entity.OutboxEvent, error) {
+	var events []*entity.OutboxEvent
+	for _, e := range o.events {
+		if e.PublishedAt != nil || e.DeadAt != nil || (e.NextAttemptAt != nil && e.NextAttemptAt.After(time.Now())) {
+			continue
+		}
+		c := *e
+		c.PublishedTo = append([]string(nil), e.PublishedTo...)
+		events = append(events, &c)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (o *memoryOutbox) MarkPublished(ctx context.Context, ids []int64) error {
+	now := time.Now()
+	for _, id := range ids {
+		o.events[id].PublishedAt = &now
+	}
+	return nil
+}
+
+func (o *memoryOutbox) RecordFailure(ctx context.Context, event *entity.OutboxEvent) error {
+	c := *event
+	o.events[event.ID] = &c
+	return nil
+}
+
+// dueNow lets the next run retry every failed event.
+func (o *memoryOutbox) dueNow() {
+	for _, e := range o.events {
+		e.NextAttemptAt = nil
+	}
+}
+
+type directTransactor struct{}
+
+func (directTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (directTransactor) WithinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// recordingPublisher fails events of the types in fail and counts the others.
+type recordingPublisher struct {
+	fail      map[string]bool
+	published map[int64]int
+}
+
+func newRecordingPublisher(fail ...string) *recordingPublisher {
+	p := &recordingPublisher{fail: make(map[string]bool), published: make(map[int64]int)}
+	for _, eventType := range fail {
+		p.fail[eventType] = true
+	}
+	return p
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event *entity.OutboxEvent) error {
+	if p.fail[event.EventType] {
+		return errors.New("boom")
+	}
+	p.published[event.ID]++
+	return nil
+}
+
+func TestOutboxRelayIsolatesFailures(t *testing.T) {
+	ctx := context.Background()
+	outbox := newMemoryOutbox("booking.paid", "poison", "booking.confirmed")
+	tickets := newRecordingPublisher()
+	ledger := newRecordingPublisher("poison")
+	retry := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour}
+	relay := NewOutboxRelay(outbox, directTransactor{}, service.Publishers{
+		{Name: "tickets", Publisher: tickets},
+		{Name: "ledger", Publisher: ledger},
+	}, retry, time.Second, 2, zerolog.Nop())
+
+	published, err := relay.RunOnce(ctx)
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if published != 2 || outbox.events[1].PublishedAt == nil || outbox.events[3].PublishedAt == nil {
+		t.Fatalf("published %d, events %+v %+v", published, outbox.events[1], outbox.events[3])
+	}
+
+	poison := outbox.events[2]
+	if poison.PublishedAt != nil || poison.Attempts != 1 || poison.LastError != "ledger: boom" || poison.DeadAt != nil {
+		t.Fatalf("poison after first run: %+v", poison)
+	}
+	if !reflect.DeepEqual(poison.PublishedTo, []string{"tickets"}) {
+		t.Errorf("published to %v, want [tickets]", poison.PublishedTo)
+	}
+	if poison.NextAttemptAt == nil || time.Until(*poison.NextAttemptAt) < 50*time.Second {
+		t.Errorf("next attempt %v, want about a minute from now", poison.NextAttemptAt)
+	}
+
+	// Not due yet, so the next run leaves it alone
+	if _, err := relay.RunOnce(ctx); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if outbox.events[2].Attempts != 1 {
+		t.Errorf("attempts before due: %d", outbox.events[2].Attempts)
+	}
+
+	for range 2 {
+		outbox.dueNow()
+		if _, err := relay.RunOnce(ctx); err != nil {
+			t.Fatalf("run: %v", err)
+		}
+	}
+	poison = outbox.events[2]
+	if poison.Attempts != 3 || poison.DeadAt == nil || poison.NextAttemptAt != nil {
+		t.Fatalf("poison after %d attempts: %+v", retry.MaxAttempts, poison)
+	}
+	if tickets.published[2] != 1 {
+		t.Errorf("tickets published the poison event %d times, want once", tickets.published[2])
+	}
+
+	// Dead events are not retried
+	if published, err := relay.RunOnce(ctx); err != nil || published != 0 || outbox.events[2].Attempts != 3 {
+		t.Errorf("run after dead-letter: published %d, err %v, attempts %d", published, err, outbox.events[2].Attempts)
+	}
+}
+
+func TestOutboxRelayStopsWithoutUsingAttempts(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	outbox := newMemoryOutbox("booking.paid")
+	relay := NewOutboxRelay(outbox, directTransactor{}, service.Publishers{
+		{Name: "cancelling", Publisher: cancellingPublisher(cancel)},
+	}, RetryPolicy{MaxAttempts: 1, BaseDelay: time.Second, MaxDelay: time.Second}, time.Second, 10, zerolog.Nop())
+
+	if _, err := relay.RunOnce(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if e := outbox.events[1]; e.Attempts != 0 || e.DeadAt != nil {
+		t.Errorf("event after shutdown: %+v", e)
+	}
+}
+
+type cancellingPublisher context.CancelFunc
+
+func (p cancellingPublisher) Publish(ctx context.Context, event *entity.OutboxEvent) error {
+	p()
+	return ctx.Err()
+}
@@ -0,0 +1,66 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+// Reconciler periodically ingests settlement files and reconciles them with
+// paid bookings.
+type Reconciler struct {
+	reconciliationService service.ReconciliationService
+	interval              time.Duration
+	log                   zerolog.Logger
+}
+
+func NewReconciler(reconciliationService service.ReconciliationService, interval time.Duration, log zerolog.Logger) *Reconciler {
+	return &Reconciler{
+		reconciliationService: reconciliationService,
+		interval:              interval,
+		log:                   log,
+	}
+}
+
+// Run reconciles on every tick until ctx is cancelled.
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			r.log.Error().Err(err).Msg("Reconciliation failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs one reconciliation, logging files that could not be read.
+func (r *Reconciler) RunOnce(ctx context.Context) error {
+	run, err := r.reconciliationService.Reconcile(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range run.Errors {
+		r.log.Warn().Str("error", msg).Msg("Skipped settlement file")
+	}
+	if run.Files > 0 || run.Opened > 0 || run.Resolved > 0 {
+		r.log.Info().
+			Int("files", run.Files).
+			Int("settlements", run.Settlements).
+			Int("opened", run.Opened).
+			Int("resolved", run.Resolved).
+			Msg("Reconciled settlements")
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+// ReportRefresher periodically folds changed bookings into the reporting
+// rollups.
+type ReportRefresher struct {
+	reportService service.ReportService
+	interval      time.Duration
+	log           zerolog.Logger
+}
+
+func NewReportRefresher(reportService service.ReportService, interval time.Duration, log zerolog.Logger) *ReportRefresher {
+	return &ReportRefresher{
+		reportService: reportService,
+		interval:      interval,
+		log:           log,
+	}
+}
+
+// Run refreshes on every tick until ctx is cancelled.
+func (r *ReportRefresher) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			r.log.Error().Err(err).Msg("Report refresh failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce refreshes the rollups of bookings changed since the last refresh.
+func (r *ReportRefresher) RunOnce(ctx context.Context) (int, error) {
+	n, err := r.reportService.RefreshReports(ctx, false)
+	if err != nil {
+		return n, err
+	}
+
+	if n > 0 {
+		r.log.Info().Int("buckets", n).Msg("Refreshed report rollups")
+	}
+
+	return n, nil
+}
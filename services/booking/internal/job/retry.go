@@ -0,0 +1,24 @@
+package job
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy bounds attempts. Delays grow exponentially from BaseDelay up to
+// MaxDelay, with up to 20% jitter so retries of one outage spread out.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Delay returns how long to wait after the given failed attempt (1-based).
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	return time.Duration(delay * (1 + 0.2*rand.Float64()))
+}
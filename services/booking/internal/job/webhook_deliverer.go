@@ -0,0 +1,189 @@
+package job
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ibnuzaman/porta-pay/pkg/webhook"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+// WebhookDeliverer sends pending webhook deliveries to partners, signing each
+// body with the subscription secret, and dead-letters deliveries that fail
+// MaxAttempts times.
+type WebhookDeliverer struct {
+	subscriptionRepo repository.WebhookSubscriptionRepository
+	deliveryRepo     repository.WebhookDeliveryRepository
+	client           *http.Client
+	retry            RetryPolicy
+	interval         time.Duration
+	batchSize        int
+	concurrency      int
+	log              zerolog.Logger
+}
+
+func NewWebhookDeliverer(
+	subscriptionRepo repository.WebhookSubscriptionRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	client *http.Client,
+	retry RetryPolicy,
+	interval time.Duration,
+	batchSize, concurrency int,
+	log zerolog.Logger,
+) *WebhookDeliverer {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &WebhookDeliverer{
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		client:           client,
+		retry:            retry,
+		interval:         interval,
+		batchSize:        batchSize,
+		concurrency:      concurrency,
+		log:              log,
+	}
+}
+
+// Run delivers on every tick until ctx is cancelled.
+func (d *WebhookDeliverer) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			d.log.Error().Err(err).Msg("Webhook delivery failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce attempts one batch of due deliveries and returns how many were made.
+func (d *WebhookDeliverer) RunOnce(ctx context.Context) (int, error) {
+	// The lease outlives the slowest possible attempt so no other worker
+	// claims a delivery that is still in flight.
+	lease := d.client.Timeout + time.Minute
+	deliveries, err := d.deliveryRepo.ClaimDue(ctx, d.batchSize, lease)
+	if err != nil || len(deliveries) == 0 {
+		return 0, err
+	}
+
+	subscriptions := make(map[int64]*entity.WebhookSubscription)
+	for _, delivery := range deliveries {
+		if _, ok := subscriptions[delivery.SubscriptionID]; ok {
+			continue
+		}
+		subscription, err := d.subscriptionRepo.GetByID(ctx, delivery.SubscriptionID)
+		if err != nil {
+			return 0, err
+		}
+		subscriptions[delivery.SubscriptionID] = subscription
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, d.concurrency)
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(delivery *entity.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			d.deliver(ctx, subscriptions[delivery.SubscriptionID], delivery)
+		}(delivery)
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+func (d *WebhookDeliverer) deliver(ctx context.Context, subscription *entity.WebhookSubscription, delivery *entity.WebhookDelivery) {
+	attempt := &entity.WebhookAttempt{
+		DeliveryID:  delivery.ID,
+		Attempt:     delivery.Attempts + 1,
+		AttemptedAt: time.Now(),
+	}
+
+	if subscription.Active {
+		attempt.StatusCode, attempt.Error = d.send(ctx, subscription, delivery)
+	} else {
+		attempt.Error = "subscription is inactive"
+	}
+	attempt.DurationMS = time.Since(attempt.AttemptedAt).Milliseconds()
+
+	// Shutting down mid-request is not the partner's fault; the lease expires
+	// and the attempt is retried without using up the budget.
+	if ctx.Err() != nil {
+		return
+	}
+
+	delivery.Attempts = attempt.Attempt
+	delivery.LastError = attempt.Error
+	switch {
+	case attempt.Succeeded():
+		now := time.Now()
+		delivery.Status = entity.DeliveryDelivered
+		delivery.DeliveredAt = &now
+	case !subscription.Active || delivery.Attempts >= d.retry.MaxAttempts:
+		delivery.Status = entity.DeliveryDead
+	default:
+		delivery.NextAttemptAt = time.Now().Add(d.retry.Delay(delivery.Attempts))
+	}
+
+	if err := d.deliveryRepo.RecordAttempt(ctx, delivery, attempt); err != nil {
+		d.log.Error().Err(err).Int64("delivery_id", delivery.ID).Msg("Failed to record webhook attempt")
+		return
+	}
+
+	if delivery.Status == entity.DeliveryDead {
+		d.log.Warn().
+			Int64("delivery_id", delivery.ID).
+			Int64("subscription_id", subscription.ID).
+			Str("event_type", delivery.EventType).
+			Int("attempts", delivery.Attempts).
+			Str("error", delivery.LastError).
+			Msg("Webhook delivery dead-lettered")
+	}
+}
+
+// send posts the delivery and returns the response status or an error text.
+func (d *WebhookDeliverer) send(ctx context.Context, subscription *entity.WebhookSubscription, delivery *entity.WebhookDelivery) (int, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "porta-pay-webhooks/1")
+	req.Header.Set("Webhook-Id", strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set("Webhook-Event", delivery.EventType)
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign([]byte(subscription.Secret), time.Now(), delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return resp.StatusCode, ""
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	return resp.StatusCode, fmt.Sprintf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+}
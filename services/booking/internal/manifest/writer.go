@@ -0,0 +1,230 @@
+// Package manifest renders departure manifests for crew, one line per
+// passenger, as they are read from the database.
+package manifest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Writer renders manifest entries. Nothing is written before the first
+// entry or Close, so a failure before then can still be reported otherwise.
+type Writer interface {
+	Write(entry *entity.ManifestEntry) error
+	// Close finishes the manifest and flushes it; it must be called even
+	// when there were no entries.
+	Close() error
+}
+
+// ContentType returns the media type of a manifest format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatJSON:
+		return "application/json"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// NewWriter returns a Writer for format ("csv", "json" or "text") over w.
+func NewWriter(w io.Writer, format string, filter entity.ManifestFilter) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case FormatJSON:
+		return &jsonWriter{w: bufio.NewWriter(w), filter: filter}, nil
+	case FormatText:
+		return &textWriter{w: bufio.NewWriter(w), filter: filter}, nil
+	default:
+		return nil, apperrors.ErrInvalidManifestFormat
+	}
+}
+
+var csvHeader = []string{
+	"booking_id", "user_id", "passenger_id", "full_name", "document_type",
+	"document_number", "date_of_birth", "seat", "checked_in", "checked_in_at",
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	started bool
+}
+
+func (c *csvWriter) Write(entry *entity.ManifestEntry) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+
+	return c.w.Write([]string{
+		strconv.FormatInt(entry.BookingID, 10),
+		strconv.FormatInt(entry.UserID, 10),
+		formatID(entry.PassengerID),
+		entry.FullName,
+		string(entry.DocumentType),
+		entry.DocumentNumber,
+		formatDate(entry.DateOfBirth),
+		entry.Seat,
+		strconv.FormatBool(entry.CheckedIn),
+		formatTime(entry.CheckedInAt),
+	})
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	return c.w.Write(csvHeader)
+}
+
+// jsonWriter streams a single object whose passengers array is written one
+// element at a time.
+type jsonWriter struct {
+	w       *bufio.Writer
+	filter  entity.ManifestFilter
+	started bool
+	count   int
+}
+
+func (j *jsonWriter) Write(entry *entity.ManifestEntry) error {
+	if err := j.start(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if j.count > 0 {
+		j.w.WriteByte(',')
+	}
+	j.count++
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if err := j.start(); err != nil {
+		return err
+	}
+	fmt.Fprintf(j.w, "],\"total\":%d}\n", j.count)
+	return j.w.Flush()
+}
+
+func (j *jsonWriter) start() error {
+	if j.started {
+		return nil
+	}
+	j.started = true
+
+	departure, err := json.Marshal(j.filter.DepartureAt)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(j.w, "{\"route_id\":%d,\"departure_at\":%s,\"passengers\":[", j.filter.RouteID, departure)
+	return err
+}
+
+// textWriter prints fixed-width columns with a box crew can tick by hand.
+type textWriter struct {
+	w       *bufio.Writer
+	filter  entity.ManifestFilter
+	started bool
+	count   int
+}
+
+const textRow = "%-5s %-4s %-10s %-30s %-17s %-20s %-10s %s\n"
+
+func (t *textWriter) Write(entry *entity.ManifestEntry) error {
+	t.start()
+	t.count++
+
+	checked := "[ ]"
+	if entry.CheckedIn {
+		checked = "[x] " + entry.CheckedInAt.Format("15:04")
+	}
+	name := entry.FullName
+	if entry.PassengerID == nil {
+		name = fmt.Sprintf("(%d passenger(s), no details)", entry.Qty)
+	}
+	_, err := fmt.Fprintf(t.w, textRow,
+		strconv.Itoa(t.count),
+		entry.Seat,
+		strconv.FormatInt(entry.BookingID, 10),
+		truncate(name, 30),
+		string(entry.DocumentType),
+		truncate(entry.DocumentNumber, 20),
+		formatDate(entry.DateOfBirth),
+		checked,
+	)
+	return err
+}
+
+func (t *textWriter) Close() error {
+	t.start()
+	fmt.Fprintf(t.w, "\nTotal lines: %d\n", t.count)
+	return t.w.Flush()
+}
+
+func (t *textWriter) start() {
+	if t.started {
+		return
+	}
+	t.started = true
+
+	fmt.Fprintf(t.w, "PASSENGER MANIFEST\nRoute: %d\nDeparture: %s\n\n",
+		t.filter.RouteID, t.filter.DepartureAt.Format("2006-01-02 15:04 MST"))
+	fmt.Fprintf(t.w, textRow, "#", "SEAT", "BOOKING", "NAME", "DOCUMENT", "NUMBER", "BORN", "CHECKED IN")
+}
+
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
+
+func formatID(id *int64) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatInt(*id, 10)
+}
+
+func formatDate(d *entity.Date) string {
+	if d == nil {
+		return ""
+	}
+	return d.String()
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,86 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+func TestWriterFormats(t *testing.T) {
+	departure := time.Date(2025, 10, 10, 8, 0, 0, 0, time.UTC)
+	filter := entity.ManifestFilter{RouteID: 7, DepartureAt: departure}
+	passengerID := int64(3)
+	born := entity.NewDate(1990, time.April, 12)
+	checkedIn := departure.Add(-30 * time.Minute)
+	entries := []*entity.ManifestEntry{
+		{BookingID: 1, UserID: 9, Qty: 1, PassengerID: &passengerID, FullName: "Siti Rahma",
+			DocumentType: entity.DocumentPassport, DocumentNumber: "C1234567", DateOfBirth: &born,
+			Seat: "3A", CheckedIn: true, CheckedInAt: &checkedIn},
+		{BookingID: 2, UserID: 9, Qty: 2},
+	}
+
+	render := func(format string) string {
+		var buf bytes.Buffer
+		w, err := NewWriter(&buf, format, filter)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		for _, entry := range entries {
+			if err := w.Write(entry); err != nil {
+				t.Fatalf("%s write: %v", format, err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%s close: %v", format, err)
+		}
+		return buf.String()
+	}
+
+	wantCSV := "booking_id,user_id,passenger_id,full_name,document_type,document_number,date_of_birth,seat,checked_in,checked_in_at\n" +
+		"1,9,3,Siti Rahma,PASSPORT,C1234567,1990-04-12,3A,true,2025-10-10T07:30:00Z\n" +
+		"2,9,,,,,,,false,\n"
+	if got := render(FormatCSV); got != wantCSV {
+		t.Errorf("csv:\n%s\nwant:\n%s", got, wantCSV)
+	}
+
+	var doc struct {
+		RouteID    int64                   `json:"route_id"`
+		Passengers []*entity.ManifestEntry `json:"passengers"`
+		Total      int                     `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(render(FormatJSON)), &doc); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if doc.RouteID != 7 || doc.Total != 2 || len(doc.Passengers) != 2 || doc.Passengers[0].FullName != "Siti Rahma" {
+		t.Errorf("json: unexpected manifest %+v", doc)
+	}
+
+	text := render(FormatText)
+	if !strings.Contains(text, "[x] 07:30") || !strings.Contains(text, "(2 passenger(s), no details)") {
+		t.Errorf("text: missing check-in or placeholder line:\n%s", text)
+	}
+
+	if _, err := NewWriter(&bytes.Buffer{}, "pdf", filter); err == nil {
+		t.Error("pdf: expected an unknown format error")
+	}
+}
+
+func TestWriterEmptyManifest(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, FormatJSON, entity.ManifestFilter{RouteID: 7, DepartureAt: time.Date(2025, 10, 10, 8, 0, 0, 0, time.UTC)})
+	if buf.Len() != 0 {
+		t.Fatal("writer wrote before the first entry")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	want := `{"route_id":7,"departure_at":"2025-10-10T08:00:00Z","passengers":[],"total":0}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got %s want %s", buf.String(), want)
+	}
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/cache"
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type postgresArchiveRepository struct {
+	db         *sqlx.DB
+	transactor *database.Transactor
+	cache      cache.Cache
+}
+
+// NewPostgresArchiveRepository returns the archive. Archived bookings are
+// evicted from bookingCache, the cache behind NewCachedBookingRepository, or
+// nil when bookings are not cached.
+func NewPostgresArchiveRepository(db *sqlx.DB, bookingCache cache.Cache) repository.ArchiveRepository {
+	return &postgresArchiveRepository{
+		db:         db,
+		transactor: database.NewTransactor(db),
+		cache:      bookingCache,
+	}
+}
+
+const archiveCandidates = `
+		FROM bookings
+		WHERE (status = ANY($1) OR deleted_at IS NOT NULL) AND updated_at < $2`
+
+func (r *postgresArchiveRepository) Archive(ctx context.Context, before time.Time, limit int) (int, error) {
+	statuses := make([]string, len(entity.TerminalStatuses))
+	for i, status := range entity.TerminalStatuses {
+		statuses[i] = string(status)
+	}
+
+	var moved int
+	err := r.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+
+		var months []time.Time
+		monthsQuery := `SELECT DISTINCT date_trunc('month', created_at AT TIME ZONE 'UTC')` + archiveCandidates
+		if err := conn.SelectContext(ctx, &months, monthsQuery, pq.Array(statuses), before); err != nil {
+			return err
+		}
+		for _, month := range months {
+			if err := ensureArchivePartition(ctx, conn, month); err != nil {
+				return err
+			}
+		}
+
+		// Deleting from bookings and inserting into the archive in one statement
+		// keeps the move atomic; SKIP LOCKED lets concurrent runs split the work.
+		// The snapshot still sees the passengers and tickets the delete
+		// cascades to, so nothing is lost with the booking row. Idempotency
+		// keys stay behind, so a retried request still finds its booking.
+		moveQuery := `
+			WITH moved AS (
+				DELETE FROM bookings
+				WHERE id IN (
+					SELECT id` + archiveCandidates + `
+					ORDER BY id
+					LIMIT $3
+					FOR UPDATE SKIP LOCKED
+				)
+				RETURNING *
+			)
+			INSERT INTO bookings_archive (id, user_id, route_id, status, created_at, deleted_at, data)
+			SELECT id, user_id, route_id, status, created_at, deleted_at,
+				to_jsonb(moved) || jsonb_build_object(
+					'passengers', (
+						SELECT COALESCE(jsonb_agg(to_jsonb(p) ORDER BY p.id), '[]'::jsonb)
+						FROM booking_passengers p
+						WHERE p.booking_id = moved.id
+					),
+					'tickets', (
+						SELECT COALESCE(jsonb_agg(to_jsonb(t) ORDER BY t.seq), '[]'::jsonb)
+						FROM tickets t
+						WHERE t.booking_id = moved.id
+					)
+				)
+			FROM moved
+			RETURNING id`
+
+		var ids []int64
+		if err := conn.SelectContext(ctx, &ids, moveQuery, pq.Array(statuses), before, limit); err != nil {
+			return err
+		}
+		moved = len(ids)
+
+		if r.cache != nil {
+			database.AfterCommit(ctx, func() {
+				for _, id := range ids {
+					r.cache.Delete(context.WithoutCancel(ctx), bookingCacheKey(id))
+				}
+			})
+		}
+		return nil
+	})
+
+	return moved, err
+}
+
+// ensureArchivePartition creates the monthly partition covering month.
+func ensureArchivePartition(ctx context.Context, conn database.Executor, month time.Time) error {
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	query := fmt.Sprintf(
+		`CREATE TABLE IF NOT EXISTS bookings_archive_%s PARTITION OF bookings_archive FOR VALUES FROM ('%s') TO ('%s')`,
+		from.Format("2006_01"), from.Format(time.RFC3339), to.Format(time.RFC3339),
+	)
+	_, err := conn.ExecContext(ctx, query)
+	return err
+}
+
+// archivedBooking decodes the snapshot written by Archive, which holds the
+// booking columns and its passengers under their column names.
+type archivedBooking struct {
+	ID           int64                     `json:"id"`
+	TenantID     string                    `json:"tenant_id"`
+	UserID       int64                     `json:"user_id"`
+	RouteID      int64                     `json:"route_id"`
+	Qty          int                       `json:"qty"`
+	Status       entity.BookingStatus      `json:"status"`
+	PriceTotal   int64                     `json:"price_total"`
+	Currency     money.Currency            `json:"currency"`
+	PromoCode    string                    `json:"promo_code"`
+	Discount     *int64                    `json:"discount"`
+	DepartureAt  *time.Time                `json:"departure_at"`
+	CancelReason entity.CancellationReason `json:"cancel_reason"`
+	CancelledAt  *time.Time                `json:"cancelled_at"`
+	CreatedAt    time.Time                 `json:"created_at"`
+	UpdatedAt    time.Time                 `json:"updated_at"`
+	DeletedAt    *time.Time                `json:"deleted_at"`
+	Passengers   []struct {
+		ID             int64                     `json:"id"`
+		FullName       string                    `json:"full_name"`
+		DocumentType   entity.DocumentType       `json:"document_type"`
+		DocumentNumber string                    `json:"document_number"`
+		DateOfBirth    entity.Date               `json:"date_of_birth"`
+		Seat           string                    `json:"seat"`
+		Status         entity.PassengerStatus    `json:"status"`
+		CancelReason   entity.CancellationReason `json:"cancel_reason"`
+		CancelledAt    *time.Time                `json:"cancelled_at"`
+		CheckedInAt    *time.Time                `json:"checked_in_at"`
+		CreatedAt      time.Time                 `json:"created_at"`
+	} `json:"passengers"`
+}
+
+// getArchived reads a booking from the archive, for reads that miss the live
+// table. Archived bookings are terminal or deleted, so they are read-only.
+func getArchived(ctx context.Context, conn database.Executor, id int64, opts []repository.QueryOption) (*entity.Booking, error) {
+	tenant, args := tenantFilter(ctx, "data->>'tenant_id'", []interface{}{id})
+	query := `
+		SELECT data
+		FROM bookings_archive
+		WHERE id = $1 AND ` + tenant + ` AND ` + liveFilter(opts)
+
+	var data []byte
+	err := conn.QueryRowContext(ctx, query, args...).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrBookingNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var a archivedBooking
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("archived booking %d: %w", id, err)
+	}
+
+	booking := &entity.Booking{
+		ID:           a.ID,
+		TenantID:     a.TenantID,
+		UserID:       a.UserID,
+		RouteID:      a.RouteID,
+		Qty:          a.Qty,
+		Status:       a.Status,
+		PromoCode:    a.PromoCode,
+		DepartureAt:  a.DepartureAt,
+		CancelReason: a.CancelReason,
+		CancelledAt:  a.CancelledAt,
+		CreatedAt:    a.CreatedAt,
+		UpdatedAt:    a.UpdatedAt,
+		DeletedAt:    a.DeletedAt,
+	}
+	if booking.PriceTotal, err = money.New(a.PriceTotal, a.Currency); err != nil {
+		return nil, fmt.Errorf("archived booking %d: %w", id, err)
+	}
+	if a.Discount != nil {
+		discount, err := money.New(*a.Discount, a.Currency)
+		if err != nil {
+			return nil, err
+		}
+		booking.Discount = &discount
+	}
+	for _, p := range a.Passengers {
+		booking.Passengers = append(booking.Passengers, &entity.Passenger{
+			ID:             p.ID,
+			BookingID:      a.ID,
+			FullName:       p.FullName,
+			DocumentType:   p.DocumentType,
+			DocumentNumber: p.DocumentNumber,
+			DateOfBirth:    p.DateOfBirth,
+			Seat:           p.Seat,
+			Status:         p.Status,
+			CancelReason:   p.CancelReason,
+			CancelledAt:    p.CancelledAt,
+			CheckedInAt:    p.CheckedInAt,
+			CreatedAt:      p.CreatedAt,
+		})
+	}
+
+	return booking, nil
+}
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/ibnuzaman/porta-pay/pkg/cache"
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+// BookingCacheTTLs sets how long GetByID results are cached. A zero
+// NotFoundTTL disables caching of missing bookings.
+type BookingCacheTTLs struct {
+	Found    time.Duration
+	NotFound time.Duration
+}
+
+// cachedBookingRepository serves GetByID from a cache in front of next.
+// Writes invalidate the booking once their transaction commits, so readers
+// never repopulate the cache from a row that is about to change.
+type cachedBookingRepository struct {
+	repository.BookingRepository
+
+	cache    cache.Cache
+	ttls     BookingCacheTTLs
+	group    singleflight.Group
+	requests metric.Int64Counter
+	backend  attribute.KeyValue
+}
+
+// NewCachedBookingRepository wraps next with a read-through cache. backend
+// names the cache in metrics.
+func NewCachedBookingRepository(next repository.BookingRepository, c cache.Cache, ttls BookingCacheTTLs, backend string) repository.BookingRepository {
+	requests, err := otel.Meter("github.com/ibnuzaman/porta-pay/services/booking").Int64Counter(
+		"booking.cache.requests",
+		metric.WithDescription("Booking lookups by cache result: hit, miss or error"),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return &cachedBookingRepository{
+		BookingRepository: next,
+		cache:             c,
+		ttls:              ttls,
+		requests:          requests,
+		backend:           attribute.String("backend", backend),
+	}
+}
+
+func (r *cachedBookingRepository) GetByID(ctx context.Context, id int64, opts ...repository.QueryOption) (*entity.Booking, error) {
+	// Locking, deleted-inclusive and transactional reads must see the database
+	o := repository.ApplyQueryOptions(opts...)
+	if o.ForUpdate || o.IncludeDeleted || database.InTx(ctx) {
+		return r.BookingRepository.GetByID(ctx, id, opts...)
+	}
+
+	key := bookingCacheKey(id)
+	data, err := r.cache.Get(ctx, key)
+	if err == nil {
+		if booking, err := decodeCachedBooking(data); err == nil || errors.Is(err, apperrors.ErrBookingNotFound) {
+			r.count(ctx, "hit")
+			return ownBooking(ctx, booking, err)
+		}
+	}
+	if err != nil && !errors.Is(err, cache.ErrMiss) {
+		// A failing cache degrades to reading the database
+		r.count(ctx, "error")
+	} else {
+		r.count(ctx, "miss")
+	}
+
+	// Concurrent misses for one booking share a single query. It runs without
+	// the caller's cancellation so one impatient caller cannot fail the rest,
+	// and without its tenant as the cache is shared by all tenants.
+	v, err, _ := r.group.Do(key, func() (interface{}, error) {
+		loadCtx := reqctx.WithTenant(context.WithoutCancel(ctx), reqctx.AllTenants)
+		// The flight we missed may have filled the cache just now
+		if data, err := r.cache.Get(loadCtx, key); err == nil {
+			if booking, err := decodeCachedBooking(data); err == nil || errors.Is(err, apperrors.ErrBookingNotFound) {
+				return booking, err
+			}
+		}
+
+		booking, err := r.BookingRepository.GetByID(loadCtx, id)
+		switch {
+		case err == nil:
+			r.store(loadCtx, key, booking, r.ttls.Found)
+		case errors.Is(err, apperrors.ErrBookingNotFound) && r.ttls.NotFound > 0:
+			r.store(loadCtx, key, nil, r.ttls.NotFound)
+		}
+		return booking, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Callers may modify what they get, so each receives its own copy
+	booking := *v.(*entity.Booking)
+	return ownBooking(ctx, &booking, nil)
+}
+
+// ownBooking hides a cached booking of another tenant than the one in ctx.
+func ownBooking(ctx context.Context, booking *entity.Booking, err error) (*entity.Booking, error) {
+	if tenant := reqctx.Tenant(ctx); err == nil && tenant != reqctx.AllTenants && booking.TenantID != tenant {
+		return nil, apperrors.ErrBookingNotFound
+	}
+	return booking, err
+}
+
+func (r *cachedBookingRepository) Create(ctx context.Context, booking *entity.Booking) error {
+	if err := r.BookingRepository.Create(ctx, booking); err != nil {
+		return err
+	}
+
+	// Drops a cached "not found" for the new ID
+	r.invalidate(ctx, booking.ID)
+	return nil
+}
+
+func (r *cachedBookingRepository) Update(ctx context.Context, booking *entity.Booking) error {
+	if err := r.BookingRepository.Update(ctx, booking); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, booking.ID)
+	return nil
+}
+
+func (r *cachedBookingRepository) CancelPassenger(ctx context.Context, passenger *entity.Passenger) error {
+	if err := r.BookingRepository.CancelPassenger(ctx, passenger); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, passenger.BookingID)
+	return nil
+}
+
+func (r *cachedBookingRepository) Delete(ctx context.Context, id int64) error {
+	if err := r.BookingRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, id)
+	return nil
+}
+
+// invalidate evicts the booking after the surrounding transaction commits.
+// A load that read the old row just before the commit can still write it
+// back; the TTL bounds how long that stale entry lives.
+func (r *cachedBookingRepository) invalidate(ctx context.Context, id int64) {
+	key := bookingCacheKey(id)
+	database.AfterCommit(ctx, func() {
+		r.group.Forget(key)
+		if err := r.cache.Delete(context.WithoutCancel(ctx), key); err != nil {
+			r.count(ctx, "error")
+		}
+	})
+}
+
+func (r *cachedBookingRepository) store(ctx context.Context, key string, booking *entity.Booking, ttl time.Duration) {
+	// A nil booking encodes as null and marks the ID as not found
+	data, err := json.Marshal(booking)
+	if err == nil {
+		err = r.cache.Set(ctx, key, data, ttl)
+	}
+	if err != nil {
+		r.count(ctx, "error")
+	}
+}
+
+func (r *cachedBookingRepository) count(ctx context.Context, result string) {
+	r.requests.Add(ctx, 1, metric.WithAttributes(r.backend, attribute.String("result", result)))
+}
+
+func decodeCachedBooking(data []byte) (*entity.Booking, error) {
+	var booking *entity.Booking
+	if err := json.Unmarshal(data, &booking); err != nil {
+		return nil, err
+	}
+	if booking == nil {
+		return nil, apperrors.ErrBookingNotFound
+	}
+
+	return booking, nil
+}
+
+func bookingCacheKey(id int64) string {
+	return "booking:" + strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+// exportFetchSize is how many bookings each FETCH from the cursor returns.
+const exportFetchSize = 500
+
+type postgresExportRepository struct {
+	db         *sqlx.DB
+	transactor *database.Transactor
+}
+
+func NewPostgresExportRepository(db *sqlx.DB) repository.ExportRepository {
+	return &postgresExportRepository{
+		db:         db,
+		transactor: database.NewTransactor(db),
+	}
+}
+
+// Stream walks a server-side cursor, so only one batch of bookings is held at
+// a time. The cursor lives in a read-only transaction that sees one snapshot
+// for the whole export.
+func (r *postgresExportRepository) Stream(ctx context.Context, filter entity.BookingFilter, fn func(*entity.Booking) error) error {
+	return r.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+
+		if _, err := conn.ExecContext(ctx, `SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY`); err != nil {
+			return err
+		}
+
+		where, args := filterClause(filter, nil)
+		tenant, args := tenantFilter(ctx, "tenant_id", args)
+		query := `
+			DECLARE booking_export NO SCROLL CURSOR FOR
+			SELECT ` + bookingColumns + `
+			FROM bookings
+			WHERE deleted_at IS NULL AND ` + tenant + ` AND ` + where + `
+			ORDER BY created_at, id`
+		if _, err := conn.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+
+		fetch := `FETCH FORWARD ` + strconv.Itoa(exportFetchSize) + ` FROM booking_export`
+		for {
+			batch, err := fetchBookings(ctx, conn, fetch)
+			if err != nil {
+				return err
+			}
+			if err := loadPassengers(ctx, conn, batch...); err != nil {
+				return err
+			}
+
+			for _, booking := range batch {
+				if err := fn(booking); err != nil {
+					return err
+				}
+			}
+			if len(batch) < exportFetchSize {
+				return nil
+			}
+		}
+	})
+}
+
+func fetchBookings(ctx context.Context, conn database.Executor, fetch string) ([]*entity.Booking, error) {
+	rows, err := conn.QueryContext(ctx, fetch)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookings []*entity.Booking
+	for rows.Next() {
+		booking, err := scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+
+	return bookings, rows.Err()
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+type postgresBookingHistoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresBookingHistoryRepository(db *sqlx.DB) repository.BookingHistoryRepository {
+	return &postgresBookingHistoryRepository{
+		db: db,
+	}
+}
+
+func (r *postgresBookingHistoryRepository) Append(ctx context.Context, entry *entity.BookingHistoryEntry) error {
+	changes, err := json.Marshal(entry.Changes)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO booking_events (booking_id, action, old_status, new_status, changes, actor, request_id, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, NULLIF($7, ''), $8)
+		RETURNING id`
+
+	return database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		entry.BookingID,
+		entry.Action,
+		entry.OldStatus,
+		entry.NewStatus,
+		changes,
+		entry.Actor,
+		entry.RequestID,
+		entry.CreatedAt,
+	).Scan(&entry.ID)
+}
+
+func (r *postgresBookingHistoryRepository) ListByBookingID(ctx context.Context, bookingID int64) ([]*entity.BookingHistoryEntry, error) {
+	query := `
+		SELECT id, booking_id, action, COALESCE(old_status, ''), new_status, changes, actor,
+		       COALESCE(request_id, ''), created_at
+		FROM booking_events
+		WHERE booking_id = $1
+		ORDER BY id`
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*entity.BookingHistoryEntry{}
+	for rows.Next() {
+		entry := &entity.BookingHistoryEntry{}
+		var changes []byte
+		err := rows.Scan(
+			&entry.ID,
+			&entry.BookingID,
+			&entry.Action,
+			&entry.OldStatus,
+			&entry.NewStatus,
+			&changes,
+			&entry.Actor,
+			&entry.RequestID,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+type seatKey struct {
+	routeID   int64
+	departure string
+	seat      string
+}
+
+// MemoryHoldStore is an in-process HoldStore for tests and single-instance
+// development. Expired holds are dropped lazily on access.
+type MemoryHoldStore struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	holds map[string]*entity.Hold
+	seats map[seatKey]string
+}
+
+var _ repository.HoldStore = (*MemoryHoldStore)(nil)
+
+// NewMemoryHoldStore returns an empty store. now defaults to time.Now and can
+// be replaced to control expiry in tests.
+func NewMemoryHoldStore(now func() time.Time) *MemoryHoldStore {
+	if now == nil {
+		now = time.Now
+	}
+
+	return &MemoryHoldStore{
+		now:   now,
+		holds: make(map[string]*entity.Hold),
+		seats: make(map[seatKey]string),
+	}
+}
+
+func (s *MemoryHoldStore) Create(ctx context.Context, hold *entity.Hold) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, seat := range hold.Seats {
+		if owner, ok := s.seats[seatKey{hold.RouteID, departureKey(hold.DepartureAt), seat}]; ok && s.live(owner) != nil {
+			return apperrors.ErrSeatUnavailable
+		}
+	}
+
+	stored := copyHold(hold)
+	s.holds[hold.ID] = stored
+	for _, seat := range hold.Seats {
+		s.seats[seatKey{hold.RouteID, departureKey(hold.DepartureAt), seat}] = hold.ID
+	}
+
+	return nil
+}
+
+func (s *MemoryHoldStore) Get(ctx context.Context, id string) (*entity.Hold, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hold := s.live(id)
+	if hold == nil {
+		return nil, apperrors.ErrHoldNotFound
+	}
+
+	return copyHold(hold), nil
+}
+
+func (s *MemoryHoldStore) Extend(ctx context.Context, id string, expiresAt time.Time) (*entity.Hold, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hold := s.live(id)
+	if hold == nil {
+		return nil, apperrors.ErrHoldNotFound
+	}
+	if hold.Extended {
+		return nil, apperrors.ErrHoldAlreadyExtended
+	}
+
+	hold.Extended = true
+	hold.ExpiresAt = expiresAt
+
+	return copyHold(hold), nil
+}
+
+func (s *MemoryHoldStore) Release(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.live(id) == nil {
+		return apperrors.ErrHoldNotFound
+	}
+	s.drop(id)
+
+	return nil
+}
+
+// live returns the hold if it exists and has not expired, dropping it otherwise.
+func (s *MemoryHoldStore) live(id string) *entity.Hold {
+	hold, ok := s.holds[id]
+	if !ok {
+		return nil
+	}
+	if !s.now().Before(hold.ExpiresAt) {
+		s.drop(id)
+		return nil
+	}
+
+	return hold
+}
+
+func (s *MemoryHoldStore) drop(id string) {
+	hold := s.holds[id]
+	delete(s.holds, id)
+	for _, seat := range hold.Seats {
+		key := seatKey{hold.RouteID, departureKey(hold.DepartureAt), seat}
+		if s.seats[key] == id {
+			delete(s.seats, key)
+		}
+	}
+}
+
+func copyHold(hold *entity.Hold) *entity.Hold {
+	c := *hold
+	c.Seats = append([]string(nil), hold.Seats...)
+	return &c
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+func TestMemoryHoldStoreLifecycle(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 10, 1, 8, 0, 0, 0, time.UTC)
+	store := NewMemoryHoldStore(func() time.Time { return now })
+
+	first := &entity.Hold{ID: "a", RouteID: 7, Qty: 2, Seats: []string{"1A", "1B"}, ExpiresAt: now.Add(10 * time.Minute)}
+	if err := store.Create(ctx, first); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	second := &entity.Hold{ID: "b", RouteID: 7, Qty: 1, Seats: []string{"1B"}, ExpiresAt: now.Add(10 * time.Minute)}
+	if err := store.Create(ctx, second); !errors.Is(err, apperrors.ErrSeatUnavailable) {
+		t.Fatalf("overlapping create: got %v, want ErrSeatUnavailable", err)
+	}
+
+	if _, err := store.Extend(ctx, "a", now.Add(15*time.Minute)); err != nil {
+		t.Fatalf("extend: %v", err)
+	}
+	if _, err := store.Extend(ctx, "a", now.Add(20*time.Minute)); !errors.Is(err, apperrors.ErrHoldAlreadyExtended) {
+		t.Fatalf("second extend: got %v, want ErrHoldAlreadyExtended", err)
+	}
+
+	now = now.Add(12 * time.Minute)
+	if _, err := store.Get(ctx, "a"); err != nil {
+		t.Fatalf("get after original expiry: %v", err)
+	}
+
+	// Once expired the hold is gone and its seats can be held again
+	now = now.Add(5 * time.Minute)
+	if _, err := store.Get(ctx, "a"); !errors.Is(err, apperrors.ErrHoldNotFound) {
+		t.Fatalf("get after expiry: got %v, want ErrHoldNotFound", err)
+	}
+	second.ExpiresAt = now.Add(10 * time.Minute)
+	if err := store.Create(ctx, second); err != nil {
+		t.Fatalf("create after expiry: %v", err)
+	}
+
+	if err := store.Release(ctx, "b"); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if err := store.Release(ctx, "b"); !errors.Is(err, apperrors.ErrHoldNotFound) {
+		t.Fatalf("second release: got %v, want ErrHoldNotFound", err)
+	}
+}
+
+func TestMemoryHoldStoreSeatsPerDeparture(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 10, 1, 8, 0, 0, 0, time.UTC)
+	store := NewMemoryHoldStore(func() time.Time { return now })
+	morning, evening := now.Add(24*time.Hour), now.Add(32*time.Hour)
+
+	hold := func(id string, departureAt *time.Time) *entity.Hold {
+		return &entity.Hold{ID: id, RouteID: 7, Qty: 1, Seats: []string{"3A"}, DepartureAt: departureAt, ExpiresAt: now.Add(10 * time.Minute)}
+	}
+	if err := store.Create(ctx, hold("a", &morning)); err != nil {
+		t.Fatalf("morning: %v", err)
+	}
+	if err := store.Create(ctx, hold("b", &evening)); err != nil {
+		t.Fatalf("same seat on another departure: %v", err)
+	}
+	if err := store.Create(ctx, hold("c", nil)); err != nil {
+		t.Fatalf("same seat without departure: %v", err)
+	}
+	sameTime := morning.In(time.FixedZone("WIB", 7*60*60))
+	if err := store.Create(ctx, hold("d", &sameTime)); !errors.Is(err, apperrors.ErrSeatUnavailable) {
+		t.Fatalf("same departure in another zone: got %v, want ErrSeatUnavailable", err)
+	}
+}
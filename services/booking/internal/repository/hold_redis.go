@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+// A hold is stored as JSON under holdKey and claims each of its seats on its
+// departure with a seatKey holding the hold ID. All keys share the hold's
+// expiry, so Redis releases everything on its own when the hold runs out.
+const holdKeyPrefix = "booking:hold:"
+
+// createHoldScript claims every seat or none. KEYS are the hold key followed
+// by the seat keys; ARGV are the hold ID, its JSON and the expiry in Unix ms.
+var createHoldScript = redis.NewScript(`
+for i = 2, #KEYS do
+	if redis.call('EXISTS', KEYS[i]) == 1 then
+		return 0
+	end
+end
+for i = 2, #KEYS do
+	redis.call('SET', KEYS[i], ARGV[1], 'PXAT', ARGV[3])
+end
+redis.call('SET', KEYS[1], ARGV[2], 'PXAT', ARGV[3])
+return 1
+`)
+
+// releaseHoldScript deletes the hold and only those seat keys it still owns.
+var releaseHoldScript = redis.NewScript(`
+redis.call('DEL', KEYS[1])
+for i = 2, #KEYS do
+	if redis.call('GET', KEYS[i]) == ARGV[1] then
+		redis.call('DEL', KEYS[i])
+	end
+end
+return 1
+`)
+
+type redisHoldStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisHoldStore(client redis.UniversalClient) repository.HoldStore {
+	return &redisHoldStore{
+		client: client,
+	}
+}
+
+func (s *redisHoldStore) Create(ctx context.Context, hold *entity.Hold) error {
+	data, err := json.Marshal(hold)
+	if err != nil {
+		return err
+	}
+
+	keys := holdKeys(hold)
+	claimed, err := createHoldScript.Run(ctx, s.client, keys, hold.ID, data, hold.ExpiresAt.UnixMilli()).Int()
+	if err != nil {
+		return err
+	}
+	if claimed == 0 {
+		return apperrors.ErrSeatUnavailable
+	}
+
+	return nil
+}
+
+func (s *redisHoldStore) Get(ctx context.Context, id string) (*entity.Hold, error) {
+	return s.get(ctx, s.client, id)
+}
+
+func (s *redisHoldStore) Extend(ctx context.Context, id string, expiresAt time.Time) (*entity.Hold, error) {
+	var extended *entity.Hold
+
+	// Optimistic transaction: a concurrent extend or release aborts the
+	// EXEC and is retried against the new state.
+	extend := func(tx *redis.Tx) error {
+		hold, err := s.get(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		if hold.Extended {
+			return apperrors.ErrHoldAlreadyExtended
+		}
+
+		hold.Extended = true
+		hold.ExpiresAt = expiresAt
+		data, err := json.Marshal(hold)
+		if err != nil {
+			return err
+		}
+
+		keys := holdKeys(hold)
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.SetArgs(ctx, keys[0], data, redis.SetArgs{ExpireAt: expiresAt})
+			for _, seatKey := range keys[1:] {
+				pipe.PExpireAt(ctx, seatKey, expiresAt)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		extended = hold
+		return nil
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		err := s.client.Watch(ctx, extend, holdKeyPrefix+id)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return extended, err
+		}
+	}
+
+	return nil, redis.TxFailedErr
+}
+
+func (s *redisHoldStore) Release(ctx context.Context, id string) error {
+	hold, err := s.get(ctx, s.client, id)
+	if err != nil {
+		return err
+	}
+
+	return releaseHoldScript.Run(ctx, s.client, holdKeys(hold), hold.ID).Err()
+}
+
+func (s *redisHoldStore) get(ctx context.Context, client redis.Cmdable, id string) (*entity.Hold, error) {
+	data, err := client.Get(ctx, holdKeyPrefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, apperrors.ErrHoldNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var hold entity.Hold
+	if err := json.Unmarshal(data, &hold); err != nil {
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+// holdKeys returns the hold key followed by one key per seat.
+func holdKeys(hold *entity.Hold) []string {
+	keys := make([]string, 0, len(hold.Seats)+1)
+	keys = append(keys, holdKeyPrefix+hold.ID)
+	route := strconv.FormatInt(hold.RouteID, 10)
+	departure := departureKey(hold.DepartureAt)
+	for _, seat := range hold.Seats {
+		keys = append(keys, holdKeyPrefix+"seat:"+route+":"+departure+":"+seat)
+	}
+	return keys
+}
+
+// departureKey names the departure a seat belongs to, so the same seat can be
+// held on different departures of a route.
+func departureKey(departureAt *time.Time) string {
+	if departureAt == nil {
+		return "open"
+	}
+	return strconv.FormatInt(departureAt.Unix(), 10)
+}
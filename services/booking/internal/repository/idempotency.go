@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+// uniqueViolation is the Postgres SQLSTATE for duplicate keys.
+const uniqueViolation = "23505"
+
+type postgresIdempotencyRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresIdempotencyRepository(db *sqlx.DB) repository.IdempotencyRepository {
+	return &postgresIdempotencyRepository{
+		db: db,
+	}
+}
+
+func (r *postgresIdempotencyRepository) GetBookingID(ctx context.Context, key string) (int64, error) {
+	var bookingID int64
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT booking_id FROM idempotency_keys WHERE key = $1`, key,
+	).Scan(&bookingID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, apperrors.ErrBookingNotFound
+	}
+
+	return bookingID, err
+}
+
+func (r *postgresIdempotencyRepository) Save(ctx context.Context, key string, bookingID int64) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO idempotency_keys (key, booking_id) VALUES ($1, $2)`, key, bookingID,
+	)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return apperrors.ErrIdempotencyKeyExists
+	}
+
+	return err
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+type postgresManifestRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresManifestRepository(db *sqlx.DB) repository.ManifestRepository {
+	return &postgresManifestRepository{
+		db: db,
+	}
+}
+
+func (r *postgresManifestRepository) Stream(ctx context.Context, filter entity.ManifestFilter, fn func(*entity.ManifestEntry) error) error {
+	// Bookings without passengers still get a line through the outer join
+	tenant, args := tenantFilter(ctx, "b.tenant_id", []interface{}{filter.RouteID, filter.DepartureAt})
+	query := `
+		SELECT b.id, b.user_id, b.qty, p.id, COALESCE(p.full_name, ''), COALESCE(p.document_type, ''),
+		       COALESCE(p.document_number, ''), p.date_of_birth, COALESCE(p.seat, ''), p.checked_in_at
+		FROM bookings b
+		LEFT JOIN booking_passengers p ON p.booking_id = b.id AND p.status = 'ACTIVE'
+		WHERE b.route_id = $1 AND b.departure_at = $2
+		  AND b.status = 'CONFIRMED' AND b.deleted_at IS NULL AND ` + tenant + `
+		ORDER BY p.seat NULLS LAST, b.id, p.id`
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			entry       entity.ManifestEntry
+			dateOfBirth entity.Date
+		)
+		err := rows.Scan(
+			&entry.BookingID,
+			&entry.UserID,
+			&entry.Qty,
+			&entry.PassengerID,
+			&entry.FullName,
+			&entry.DocumentType,
+			&entry.DocumentNumber,
+			&dateOfBirth,
+			&entry.Seat,
+			&entry.CheckedInAt,
+		)
+		if err != nil {
+			return err
+		}
+		if !dateOfBirth.IsZero() {
+			entry.DateOfBirth = &dateOfBirth
+		}
+		entry.CheckedIn = entry.CheckedInAt != nil
+
+		if err := fn(&entry); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+const outboxColumns = `id, aggregate_type, aggregate_id, event_type, payload, created_at, published_at,
+	published_to, attempts, next_attempt_at, COALESCE(last_error, ''), dead_at`
+
+type postgresOutboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresOutboxRepository(db *sqlx.DB) repository.OutboxRepository {
+	return &postgresOutboxRepository{
+		db: db,
+	}
+}
+
+func (r *postgresOutboxRepository) Append(ctx context.Context, event *entity.OutboxEvent) error {
+	query := `
+		INSERT INTO outbox_events (aggregate_type, aggregate_id, event_type, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	return database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		event.AggregateType,
+		event.AggregateID,
+		event.EventType,
+		[]byte(event.Payload),
+		event.CreatedAt,
+	).Scan(&event.ID)
+}
+
+func (r *postgresOutboxRepository) List(ctx context.Context, filter entity.OutboxFilter, limit int) ([]*entity.OutboxEvent, error) {
+	where, args := outboxFilterClause(filter, []interface{}{limit})
+	query := `
+		SELECT ` + outboxColumns + `
+		FROM outbox_events
+		WHERE ` + where + `
+		ORDER BY id
+		LIMIT $1`
+
+	return r.query(ctx, query, args...)
+}
+
+func (r *postgresOutboxRepository) FetchPending(ctx context.Context, limit int) ([]*entity.OutboxEvent, error) {
+	query := `
+		SELECT ` + outboxColumns + `
+		FROM outbox_events
+		WHERE published_at IS NULL AND dead_at IS NULL AND (next_attempt_at IS NULL OR next_attempt_at <= now())
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+
+	return r.query(ctx, query, limit)
+}
+
+func (r *postgresOutboxRepository) MarkPublished(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `UPDATE outbox_events SET published_at = now() WHERE id = ANY($1)`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, pq.Array(ids))
+	return err
+}
+
+func (r *postgresOutboxRepository) RecordFailure(ctx context.Context, event *entity.OutboxEvent) error {
+	query := `
+		UPDATE outbox_events
+		SET published_to = $2, attempts = $3, next_attempt_at = $4, last_error = NULLIF($5, ''), dead_at = $6
+		WHERE id = $1`
+
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		event.ID,
+		pq.Array(event.PublishedTo),
+		event.Attempts,
+		event.NextAttemptAt,
+		event.LastError,
+		event.DeadAt,
+	)
+	return err
+}
+
+func (r *postgresOutboxRepository) MarkPending(ctx context.Context, filter entity.OutboxFilter) (int, error) {
+	where, args := outboxFilterClause(filter, nil)
+	query := `
+		UPDATE outbox_events
+		SET published_at = NULL, published_to = '{}', attempts = 0, next_attempt_at = NULL, last_error = NULL, dead_at = NULL
+		WHERE (published_at IS NOT NULL OR dead_at IS NOT NULL) AND ` + where
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := result.RowsAffected()
+	return int(n), err
+}
+
+func (r *postgresOutboxRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entity.OutboxEvent, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*entity.OutboxEvent
+	for rows.Next() {
+		event := &entity.OutboxEvent{}
+		var payload []byte
+		err := rows.Scan(
+			&event.ID,
+			&event.AggregateType,
+			&event.AggregateID,
+			&event.EventType,
+			&payload,
+			&event.CreatedAt,
+			&event.PublishedAt,
+			pq.Array(&event.PublishedTo),
+			&event.Attempts,
+			&event.NextAttemptAt,
+			&event.LastError,
+			&event.DeadAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func outboxFilterClause(filter entity.OutboxFilter, args []interface{}) (string, []interface{}) {
+	var conds []string
+	add := func(cond string, value interface{}) {
+		args = append(args, value)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.FromID != 0 {
+		add("id >= $%d", filter.FromID)
+	}
+	if filter.ToID != 0 {
+		add("id <= $%d", filter.ToID)
+	}
+	if filter.AggregateID != 0 {
+		add("aggregate_id = $%d", filter.AggregateID)
+	}
+	if filter.EventType != "" {
+		add("event_type = $%d", filter.EventType)
+	}
+	if filter.PendingOnly {
+		conds = append(conds, "published_at IS NULL")
+	}
+
+	if len(conds) == 0 {
+		return "TRUE", args
+	}
+	return strings.Join(conds, " AND "), args
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+const passengerColumns = `id, booking_id, full_name, document_type, document_number, date_of_birth,
+		COALESCE(seat, ''), status, COALESCE(cancel_reason, ''), cancelled_at, checked_in_at, created_at`
+
+// insertPassengers stores the passengers of a just created booking.
+func insertPassengers(ctx context.Context, conn database.Executor, booking *entity.Booking) error {
+	query := `
+		INSERT INTO booking_passengers (booking_id, full_name, document_type, document_number, date_of_birth, seat, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7, $8)
+		RETURNING id`
+
+	for _, p := range booking.Passengers {
+		p.BookingID = booking.ID
+		err := conn.QueryRowContext(ctx, query,
+			p.BookingID,
+			p.FullName,
+			p.DocumentType,
+			p.DocumentNumber,
+			p.DateOfBirth,
+			p.Seat,
+			p.Status,
+			p.CreatedAt,
+		).Scan(&p.ID)
+		if err != nil {
+			return seatConflict(err)
+		}
+	}
+
+	return nil
+}
+
+// loadPassengers attaches their passengers to bookings with one query.
+func loadPassengers(ctx context.Context, conn database.Executor, bookings ...*entity.Booking) error {
+	if len(bookings) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*entity.Booking, len(bookings))
+	ids := make([]int64, len(bookings))
+	for i, b := range bookings {
+		byID[b.ID] = b
+		ids[i] = b.ID
+	}
+
+	query := `
+		SELECT ` + passengerColumns + `
+		FROM booking_passengers
+		WHERE booking_id = ANY($1)
+		ORDER BY booking_id, id`
+
+	rows, err := conn.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p := &entity.Passenger{}
+		err := rows.Scan(
+			&p.ID,
+			&p.BookingID,
+			&p.FullName,
+			&p.DocumentType,
+			&p.DocumentNumber,
+			&p.DateOfBirth,
+			&p.Seat,
+			&p.Status,
+			&p.CancelReason,
+			&p.CancelledAt,
+			&p.CheckedInAt,
+			&p.CreatedAt,
+		)
+		if err != nil {
+			return err
+		}
+		b := byID[p.BookingID]
+		b.Passengers = append(b.Passengers, p)
+	}
+
+	return rows.Err()
+}
+
+func (r *postgresBookingRepository) CancelPassenger(ctx context.Context, passenger *entity.Passenger) error {
+	query := `
+		UPDATE booking_passengers
+		SET status = $3, cancel_reason = NULLIF($4, ''), cancelled_at = $5
+		WHERE id = $1 AND booking_id = $2 AND status = 'ACTIVE'`
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		passenger.ID,
+		passenger.BookingID,
+		passenger.Status,
+		passenger.CancelReason,
+		passenger.CancelledAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result, apperrors.ErrPassengerNotFound)
+}
+
+func (r *postgresBookingRepository) BookedSeats(ctx context.Context, routeID int64, departureAt *time.Time, seats []string) ([]string, error) {
+	query := `
+		SELECT seat
+		FROM booked_seats
+		WHERE route_id = $1 AND departure_at IS NOT DISTINCT FROM $2 AND seat = ANY($3)
+		ORDER BY seat`
+
+	var booked []string
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &booked, query, routeID, departureAt, pq.Array(seats)); err != nil {
+		return nil, err
+	}
+
+	return booked, nil
+}
+
+// seatConflict reports a clash on the booked seats index as ErrSeatUnavailable.
+func seatConflict(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation && pqErr.Constraint == "idx_booked_seats_seat" {
+		return apperrors.ErrSeatUnavailable
+	}
+	return err
+}
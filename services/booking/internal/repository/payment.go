@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+type postgresPaymentEventRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresPaymentEventRepository(db *sqlx.DB) repository.PaymentEventRepository {
+	return &postgresPaymentEventRepository{
+		db: db,
+	}
+}
+
+func (r *postgresPaymentEventRepository) Save(ctx context.Context, event *entity.PaymentEvent) error {
+	query := `
+		INSERT INTO payment_events (provider, event_id, event_type, booking_id, amount, currency, occurred_at, outcome, payload)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING received_at`
+
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		event.Provider,
+		event.EventID,
+		event.Type,
+		event.BookingID,
+		event.Amount.Amount(),
+		event.Amount.Currency(),
+		event.OccurredAt,
+		event.Outcome,
+		[]byte(event.Payload),
+	).Scan(&event.ReceivedAt)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return apperrors.ErrDuplicatePaymentEvent
+	}
+
+	return err
+}
+
+func (r *postgresPaymentEventRepository) Get(ctx context.Context, provider, eventID string) (*entity.PaymentEvent, error) {
+	query := `
+		SELECT provider, event_id, event_type, booking_id, amount, currency, occurred_at, outcome, payload, received_at
+		FROM payment_events
+		WHERE provider = $1 AND event_id = $2`
+
+	var (
+		event    entity.PaymentEvent
+		amount   int64
+		currency money.Currency
+		payload  []byte
+	)
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, provider, eventID).Scan(
+		&event.Provider,
+		&event.EventID,
+		&event.Type,
+		&event.BookingID,
+		&amount,
+		&currency,
+		&event.OccurredAt,
+		&event.Outcome,
+		&payload,
+		&event.ReceivedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrPaymentEventNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if event.Amount, err = money.New(amount, currency); err != nil {
+		return nil, err
+	}
+	event.Payload = payload
+
+	return &event, nil
+}
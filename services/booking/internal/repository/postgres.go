@@ -2,12 +2,23 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
 	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
 	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
 	"github.com/jmoiron/sqlx"
 )
 
+const bookingColumns = `id, tenant_id, user_id, route_id, qty, status, price_total, currency, departure_at,
+		COALESCE(cancel_reason, ''), cancelled_at, created_at, updated_at, deleted_at, COALESCE(promo_code, ''), discount`
+
 type postgresBookingRepository struct {
 	db *sqlx.DB
 }
@@ -18,83 +29,247 @@ func NewPostgresBookingRepository(db *sqlx.DB) repository.BookingRepository {
 	}
 }
 
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanBooking(row rowScanner) (*entity.Booking, error) {
+	booking := &entity.Booking{}
+	var (
+		amount   int64
+		currency money.Currency
+		discount sql.NullInt64
+	)
+	err := row.Scan(
+		&booking.ID,
+		&booking.TenantID,
+		&booking.UserID,
+		&booking.RouteID,
+		&booking.Qty,
+		&booking.Status,
+		&amount,
+		&currency,
+		&booking.DepartureAt,
+		&booking.CancelReason,
+		&booking.CancelledAt,
+		&booking.CreatedAt,
+		&booking.UpdatedAt,
+		&booking.DeletedAt,
+		&booking.PromoCode,
+		&discount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.PriceTotal, err = money.New(amount, currency); err != nil {
+		return nil, err
+	}
+	if discount.Valid {
+		d, err := money.New(discount.Int64, currency)
+		if err != nil {
+			return nil, err
+		}
+		booking.Discount = &d
+	}
+
+	return booking, nil
+}
+
 func (r *postgresBookingRepository) Create(ctx context.Context, booking *entity.Booking) error {
 	query := `
-		INSERT INTO bookings (user_id, route_id, qty, status, price_total, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO bookings (user_id, route_id, qty, status, price_total, currency, departure_at, created_at, updated_at,
+		                      promo_code, discount, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11, $12)
 		RETURNING id`
 
-	err := r.db.QueryRowContext(ctx, query,
+	var discount sql.NullInt64
+	if booking.Discount != nil {
+		discount = sql.NullInt64{Int64: booking.Discount.Amount(), Valid: true}
+	}
+
+	conn := database.Conn(ctx, r.db)
+	err := conn.QueryRowContext(ctx, query,
 		booking.UserID,
 		booking.RouteID,
 		booking.Qty,
 		booking.Status,
-		booking.PriceTotal,
+		booking.PriceTotal.Amount(),
+		booking.PriceTotal.Currency(),
+		booking.DepartureAt,
 		booking.CreatedAt,
 		booking.UpdatedAt,
+		booking.PromoCode,
+		discount,
+		booking.TenantID,
 	).Scan(&booking.ID)
+	if err != nil {
+		return err
+	}
+
+	// Callers create bookings with passengers inside a transaction
+	return insertPassengers(ctx, conn, booking)
+}
+
+// liveFilter returns the predicate hiding soft-deleted rows unless opts ask for them.
+func liveFilter(opts []repository.QueryOption) string {
+	if repository.ApplyQueryOptions(opts...).IncludeDeleted {
+		return "TRUE"
+	}
+	return "deleted_at IS NULL"
+}
+
+// lockClause returns the row-locking suffix requested by opts.
+func lockClause(opts []repository.QueryOption) string {
+	if repository.ApplyQueryOptions(opts...).ForUpdate {
+		return " FOR UPDATE"
+	}
+	return ""
+}
+
+// tenantFilter returns the predicate scoping column to the tenant in ctx,
+// numbering its argument after args. Callers acting across tenants get TRUE,
+// and callers never scoped to a tenant FALSE.
+func tenantFilter(ctx context.Context, column string, args []interface{}) (string, []interface{}) {
+	tenant := reqctx.Tenant(ctx)
+	switch tenant {
+	case reqctx.AllTenants:
+		return "TRUE", args
+	case "":
+		return "FALSE", args
+	}
+
+	args = append(args, tenant)
+	return fmt.Sprintf("%s = $%d", column, len(args)), args
+}
+
+// filterClause renders filter as SQL predicates with positional arguments
+// numbered after the args already collected.
+func filterClause(filter entity.BookingFilter, args []interface{}) (string, []interface{}) {
+	var conds []string
+	add := func(cond string, value interface{}) {
+		args = append(args, value)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.UserID != 0 {
+		add("user_id = $%d", filter.UserID)
+	}
+	if filter.RouteID != 0 {
+		add("route_id = $%d", filter.RouteID)
+	}
+	if filter.Status != "" {
+		add("status = $%d", filter.Status)
+	}
+	if filter.CreatedFrom != nil {
+		add("created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		add("created_at < $%d", *filter.CreatedTo)
+	}
 
-	return err
+	if len(conds) == 0 {
+		return "TRUE", args
+	}
+	return strings.Join(conds, " AND "), args
 }
 
-func (r *postgresBookingRepository) GetByID(ctx context.Context, id int64) (*entity.Booking, error) {
+func (r *postgresBookingRepository) GetByID(ctx context.Context, id int64, opts ...repository.QueryOption) (*entity.Booking, error) {
+	tenant, args := tenantFilter(ctx, "tenant_id", []interface{}{id})
 	query := `
-		SELECT id, user_id, route_id, qty, status, price_total, created_at, updated_at
+		SELECT ` + bookingColumns + `
 		FROM bookings
-		WHERE id = $1`
-
-	booking := &entity.Booking{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&booking.ID,
-		&booking.UserID,
-		&booking.RouteID,
-		&booking.Qty,
-		&booking.Status,
-		&booking.PriceTotal,
-		&booking.CreatedAt,
-		&booking.UpdatedAt,
-	)
+		WHERE id = $1 AND ` + tenant + ` AND ` + liveFilter(opts) + lockClause(opts)
 
+	conn := database.Conn(ctx, r.db)
+	booking, err := scanBooking(conn.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		// Archived bookings stay readable, but cannot be locked for a change
+		archived, err := getArchived(ctx, conn, id, opts)
+		if err == nil && repository.ApplyQueryOptions(opts...).ForUpdate {
+			return nil, apperrors.ErrBookingArchived
+		}
+		return archived, err
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	if err := loadPassengers(ctx, conn, booking); err != nil {
+		return nil, err
+	}
+
 	return booking, nil
 }
 
 func (r *postgresBookingRepository) Update(ctx context.Context, booking *entity.Booking) error {
-	query := `
-		UPDATE bookings
-		SET user_id = $2, route_id = $3, qty = $4, status = $5, price_total = $6, updated_at = $7
-		WHERE id = $1`
-
-	_, err := r.db.ExecContext(ctx, query,
+	tenant, args := tenantFilter(ctx, "tenant_id", []interface{}{
 		booking.ID,
 		booking.UserID,
 		booking.RouteID,
 		booking.Qty,
 		booking.Status,
-		booking.PriceTotal,
+		booking.PriceTotal.Amount(),
+		booking.PriceTotal.Currency(),
+		booking.DepartureAt,
+		booking.CancelReason,
+		booking.CancelledAt,
 		booking.UpdatedAt,
-	)
+	})
+	query := `
+		UPDATE bookings
+		SET user_id = $2, route_id = $3, qty = $4, status = $5, price_total = $6, currency = $7,
+		    departure_at = $8, cancel_reason = NULLIF($9, ''), cancelled_at = $10, updated_at = $11
+		WHERE id = $1 AND deleted_at IS NULL AND ` + tenant
+
+	// Moving a booking to another departure can clash with its seats there
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return seatConflict(err)
+	}
 
-	return err
+	return expectAffected(result, apperrors.ErrBookingNotFound)
 }
 
 func (r *postgresBookingRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM bookings WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
-	return err
+	tenant, args := tenantFilter(ctx, "tenant_id", []interface{}{id})
+	query := `
+		UPDATE bookings
+		SET deleted_at = now(), updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL AND ` + tenant
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result, apperrors.ErrBookingNotFound)
+}
+
+// expectAffected turns an UPDATE that matched no row into notFound.
+func expectAffected(result sql.Result, notFound error) error {
+	n, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
 }
 
-func (r *postgresBookingRepository) List(ctx context.Context, limit, offset int) ([]*entity.Booking, error) {
+func (r *postgresBookingRepository) List(ctx context.Context, filter entity.BookingFilter, limit, offset int, opts ...repository.QueryOption) ([]*entity.Booking, error) {
+	where, args := filterClause(filter, []interface{}{limit, offset})
+	tenant, args := tenantFilter(ctx, "tenant_id", args)
 	query := `
-		SELECT id, user_id, route_id, qty, status, price_total, created_at, updated_at
+		SELECT ` + bookingColumns + `
 		FROM bookings
+		WHERE ` + liveFilter(opts) + ` AND ` + tenant + ` AND ` + where + `
 		ORDER BY created_at DESC
-		LIMIT $1 OFFSET $2`
+		LIMIT $1 OFFSET $2` + lockClause(opts)
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	conn := database.Conn(ctx, r.db)
+	rows, err := conn.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -102,22 +277,19 @@ func (r *postgresBookingRepository) List(ctx context.Context, limit, offset int)
 
 	var bookings []*entity.Booking
 	for rows.Next() {
-		booking := &entity.Booking{}
-		err := rows.Scan(
-			&booking.ID,
-			&booking.UserID,
-			&booking.RouteID,
-			&booking.Qty,
-			&booking.Status,
-			&booking.PriceTotal,
-			&booking.CreatedAt,
-			&booking.UpdatedAt,
-		)
+		booking, err := scanBooking(rows)
 		if err != nil {
 			return nil, err
 		}
 		bookings = append(bookings, booking)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := loadPassengers(ctx, conn, bookings...); err != nil {
+		return nil, err
+	}
 
 	return bookings, nil
 }
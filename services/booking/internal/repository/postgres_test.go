@@ -0,0 +1,434 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/pkg/testutil"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/migrations"
+)
+
+// The Postgres tests run against a throwaway server with every migration
+// applied, and are skipped where Postgres is not installed or runs as root.
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunWithPostgres(m))
+}
+
+func newTestBooking(userID, routeID int64, createdAt time.Time) *entity.Booking {
+	return &entity.Booking{
+		TenantID:   entity.DefaultTenantID,
+		UserID:     userID,
+		RouteID:    routeID,
+		Qty:        1,
+		Status:     entity.StatusCreated,
+		PriceTotal: money.MustNew(150000, "IDR"),
+		CreatedAt:  createdAt,
+		UpdatedAt:  createdAt,
+	}
+}
+
+// allTenants is the context of platform code, which sees every tenant's rows.
+func allTenants() context.Context {
+	return reqctx.WithTenant(context.Background(), reqctx.AllTenants)
+}
+
+func TestPostgresBookingRepositoryLifecycle(t *testing.T) {
+	db := testutil.NewPostgresSchema(t, migrations.FS)
+	repo := NewPostgresBookingRepository(db)
+	ctx := allTenants()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	booking := newTestBooking(9, 7, now)
+	departure := now.Add(48 * time.Hour)
+	booking.DepartureAt = &departure
+	booking.Passengers = []*entity.Passenger{{
+		FullName:       "Siti Rahma",
+		DocumentType:   entity.DocumentPassport,
+		DocumentNumber: "C1234567",
+		DateOfBirth:    entity.NewDate(1990, time.April, 12),
+		Seat:           "3A",
+		Status:         entity.PassengerActive,
+		CreatedAt:      now,
+	}}
+	if err := repo.Create(ctx, booking); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, booking.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.UserID != 9 || got.RouteID != 7 || got.PriceTotal != booking.PriceTotal || got.TenantID != entity.DefaultTenantID ||
+		got.DepartureAt == nil || !got.DepartureAt.Equal(departure) {
+		t.Errorf("got %+v, want %+v", got, booking)
+	}
+	if len(got.Passengers) != 1 || got.Passengers[0].Seat != "3A" || got.Passengers[0].DateOfBirth.String() != "1990-04-12" {
+		t.Errorf("passengers: %+v", got.Passengers)
+	}
+
+	got.Status = entity.StatusPaid
+	got.UpdatedAt = now.Add(time.Minute)
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got, err = repo.GetByID(ctx, booking.ID); err != nil || got.Status != entity.StatusPaid {
+		t.Fatalf("after update: %+v, %v", got, err)
+	}
+
+	if err := repo.Delete(ctx, booking.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, booking.ID); !errors.Is(err, apperrors.ErrBookingNotFound) {
+		t.Errorf("get deleted: got %v, want ErrBookingNotFound", err)
+	}
+	if got, err := repo.GetByID(ctx, booking.ID, repository.IncludeDeleted()); err != nil || got.DeletedAt == nil {
+		t.Errorf("get deleted with IncludeDeleted: %+v, %v", got, err)
+	}
+	if err := repo.Delete(ctx, booking.ID); !errors.Is(err, apperrors.ErrBookingNotFound) {
+		t.Errorf("second delete: got %v, want ErrBookingNotFound", err)
+	}
+	if err := repo.Update(ctx, got); !errors.Is(err, apperrors.ErrBookingNotFound) {
+		t.Errorf("update deleted: got %v, want ErrBookingNotFound", err)
+	}
+}
+
+func TestPostgresBookingRepositoryList(t *testing.T) {
+	db := testutil.NewPostgresSchema(t, migrations.FS)
+	repo := NewPostgresBookingRepository(db)
+	ctx := allTenants()
+	start := time.Now().UTC().Truncate(time.Microsecond)
+
+	var ids []int64
+	for i, b := range []*entity.Booking{
+		newTestBooking(1, 7, start),
+		newTestBooking(1, 8, start.Add(time.Minute)),
+		newTestBooking(2, 7, start.Add(2*time.Minute)),
+		newTestBooking(1, 7, start.Add(3*time.Minute)),
+	} {
+		if err := repo.Create(ctx, b); err != nil {
+			t.Fatalf("create %d: %v", i, err)
+		}
+		ids = append(ids, b.ID)
+	}
+	if err := repo.Delete(ctx, ids[3]); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	got, err := repo.List(ctx, entity.BookingFilter{UserID: 1}, 10, 0)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(got) != 2 || got[0].ID != ids[1] || got[1].ID != ids[0] {
+		t.Errorf("user 1: got %d bookings, want %d and %d newest first", len(got), ids[1], ids[0])
+	}
+
+	got, err = repo.List(ctx, entity.BookingFilter{RouteID: 7}, 10, 0, repository.IncludeDeleted())
+	if err != nil {
+		t.Fatalf("list deleted: %v", err)
+	}
+	if len(got) != 3 || got[0].ID != ids[3] {
+		t.Errorf("route 7 with deleted: got %d bookings", len(got))
+	}
+
+	from := start.Add(time.Minute)
+	got, err = repo.List(ctx, entity.BookingFilter{CreatedFrom: &from}, 1, 1)
+	if err != nil {
+		t.Fatalf("list page: %v", err)
+	}
+	if len(got) != 1 || got[0].ID != ids[1] {
+		t.Errorf("second page from %s: %+v", from, got)
+	}
+}
+
+func TestPostgresArchiveKeepsDependentRows(t *testing.T) {
+	db := testutil.NewPostgresSchema(t, migrations.FS)
+	repo := NewPostgresBookingRepository(db)
+	ctx := allTenants()
+	old := time.Now().UTC().Add(-200 * 24 * time.Hour).Truncate(time.Microsecond)
+
+	booking := newTestBooking(9, 7, old)
+	booking.Passengers = []*entity.Passenger{{
+		FullName:       "Siti Rahma",
+		DocumentType:   entity.DocumentPassport,
+		DocumentNumber: "C1234567",
+		DateOfBirth:    entity.NewDate(1990, time.April, 12),
+		Status:         entity.PassengerActive,
+		CreatedAt:      old,
+	}}
+	if err := repo.Create(ctx, booking); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	booking.Status = entity.StatusCancelled
+	if err := repo.Update(ctx, booking); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO tickets (id, booking_id, seq, passenger_id, token, used_at) VALUES ('t1', $1, 1, $2, 'token', now())`,
+		booking.ID, booking.Passengers[0].ID); err != nil {
+		t.Fatalf("ticket: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO idempotency_keys (key, booking_id) VALUES ('k1', $1)`, booking.ID); err != nil {
+		t.Fatalf("idempotency key: %v", err)
+	}
+
+	n, err := NewPostgresArchiveRepository(db, nil).Archive(ctx, time.Now().Add(-24*time.Hour), 10)
+	if err != nil || n != 1 {
+		t.Fatalf("archive: moved %d, %v", n, err)
+	}
+
+	var tickets int
+	err = db.QueryRow(`SELECT jsonb_array_length(data->'tickets') FROM bookings_archive WHERE id = $1`, booking.ID).Scan(&tickets)
+	if err != nil || tickets != 1 {
+		t.Errorf("snapshot holds %d tickets, %v", tickets, err)
+	}
+	// A retried create must still find the archived booking by its key
+	if id, err := NewPostgresIdempotencyRepository(db).GetBookingID(ctx, "k1"); err != nil || id != booking.ID {
+		t.Errorf("idempotency key after archival: got %d, %v", id, err)
+	}
+
+	got, err := repo.GetByID(ctx, booking.ID)
+	if err != nil {
+		t.Fatalf("get archived: %v", err)
+	}
+	if got.Status != entity.StatusCancelled || got.PriceTotal != booking.PriceTotal || len(got.Passengers) != 1 ||
+		got.Passengers[0].DateOfBirth.String() != "1990-04-12" {
+		t.Errorf("archived booking: %+v", got)
+	}
+	if _, err := repo.GetByID(ctx, booking.ID, repository.ForUpdate()); !errors.Is(err, apperrors.ErrBookingArchived) {
+		t.Errorf("locking an archived booking: got %v, want ErrBookingArchived", err)
+	}
+}
+
+func TestPostgresBookedSeats(t *testing.T) {
+	db := testutil.NewPostgresSchema(t, migrations.FS)
+	repo := NewPostgresBookingRepository(db)
+	ctx := allTenants()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	morning, evening := now.Add(24*time.Hour), now.Add(32*time.Hour)
+
+	seated := func(departureAt time.Time, seats ...string) *entity.Booking {
+		booking := newTestBooking(9, 7, now)
+		booking.DepartureAt = &departureAt
+		for _, seat := range seats {
+			booking.Passengers = append(booking.Passengers, &entity.Passenger{
+				FullName:       "Siti Rahma",
+				DocumentType:   entity.DocumentPassport,
+				DocumentNumber: "C1234567",
+				DateOfBirth:    entity.NewDate(1990, time.April, 12),
+				Seat:           seat,
+				Status:         entity.PassengerActive,
+				CreatedAt:      now,
+			})
+		}
+		booking.Qty = len(seats)
+		return booking
+	}
+
+	first := seated(morning, "3A", "3B")
+	if err := repo.Create(ctx, first); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Create(ctx, seated(morning, "3B")); !errors.Is(err, apperrors.ErrSeatUnavailable) {
+		t.Errorf("same seat and departure: got %v, want ErrSeatUnavailable", err)
+	}
+	if err := repo.Create(ctx, seated(evening, "3B")); err != nil {
+		t.Errorf("same seat on another departure: %v", err)
+	}
+
+	booked, err := repo.BookedSeats(ctx, 7, &morning, []string{"3A", "3C"})
+	if err != nil || len(booked) != 1 || booked[0] != "3A" {
+		t.Errorf("booked seats: %v, %v", booked, err)
+	}
+
+	// Cancelled passengers and bookings give their seats back
+	passenger := first.Passengers[0]
+	cancelledAt := now
+	passenger.Status, passenger.CancelledAt = entity.PassengerCancelled, &cancelledAt
+	if err := repo.CancelPassenger(ctx, passenger); err != nil {
+		t.Fatalf("cancel passenger: %v", err)
+	}
+	if err := repo.Create(ctx, seated(morning, "3A")); err != nil {
+		t.Errorf("seat of a cancelled passenger: %v", err)
+	}
+
+	first.Status = entity.StatusCancelled
+	if err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("cancel booking: %v", err)
+	}
+	if err := repo.Create(ctx, seated(morning, "3B")); err != nil {
+		t.Errorf("seat of a cancelled booking: %v", err)
+	}
+}
+
+func TestPostgresTicketMarkUsedNeedsConfirmedBooking(t *testing.T) {
+	db := testutil.NewPostgresSchema(t, migrations.FS)
+	repo := NewPostgresBookingRepository(db)
+	tickets := NewPostgresTicketRepository(db, nil)
+	ctx := allTenants()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	booking := newTestBooking(9, 7, now)
+	booking.Status = entity.StatusPaid
+	if err := repo.Create(ctx, booking); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tickets.Create(ctx, &entity.Ticket{ID: "t1", BookingID: booking.ID, Seq: 1, Status: entity.TicketIssued, Token: "token", IssuedAt: now}); err != nil {
+		t.Fatalf("ticket: %v", err)
+	}
+
+	if _, err := tickets.MarkUsed(ctx, "t1", now, "pier-1"); !errors.Is(err, apperrors.ErrTicketNotFound) {
+		t.Errorf("unconfirmed booking: got %v, want ErrTicketNotFound", err)
+	}
+
+	booking.Status = entity.StatusConfirmed
+	if err := repo.Update(ctx, booking); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if _, err := tickets.MarkUsed(reqctx.WithTenant(ctx, "nusa-ferry"), "t1", now, "pier-1"); !errors.Is(err, apperrors.ErrTicketNotFound) {
+		t.Errorf("gate of another tenant: got %v, want ErrTicketNotFound", err)
+	}
+	used, err := tickets.MarkUsed(ctx, "t1", now, "pier-1")
+	if err != nil || used.UsedAt == nil || used.UsedBy != "pier-1" {
+		t.Fatalf("confirmed booking: %+v, %v", used, err)
+	}
+	if _, err := tickets.MarkUsed(ctx, "t1", now, "pier-2"); !errors.Is(err, apperrors.ErrTicketNotFound) {
+		t.Errorf("second scan: got %v, want ErrTicketNotFound", err)
+	}
+}
+
+func TestPostgresReportsPerTenant(t *testing.T) {
+	db := testutil.NewPostgresSchema(t, migrations.FS)
+	repo := NewPostgresBookingRepository(db)
+	reports := NewPostgresReportRepository(db)
+	ctx := allTenants()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	if _, err := db.Exec(`INSERT INTO tenants (id, name) VALUES ('nusa-ferry', 'Nusa Ferry')`); err != nil {
+		t.Fatalf("tenant: %v", err)
+	}
+	for _, tenant := range []string{entity.DefaultTenantID, "nusa-ferry", "nusa-ferry"} {
+		booking := newTestBooking(9, 7, now)
+		booking.TenantID = tenant
+		if err := repo.Create(ctx, booking); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	// Refreshing as one tenant still covers the others
+	if _, err := reports.Refresh(reqctx.WithTenant(ctx, "nusa-ferry"), true, time.Hour); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	today := entity.NewDate(now.Year(), now.Month(), now.Day())
+	query := &entity.ReportQuery{From: today, To: today, Location: time.UTC, Interval: entity.ReportDaily}
+	for tenant, want := range map[string]int{entity.DefaultTenantID: 1, "nusa-ferry": 2, reqctx.AllTenants: 3, "": 0} {
+		rows, err := reports.Statuses(reqctx.WithTenant(ctx, tenant), query)
+		if err != nil {
+			t.Fatalf("statuses of %q: %v", tenant, err)
+		}
+		got := 0
+		for _, row := range rows {
+			got += row.Bookings
+		}
+		if got != want {
+			t.Errorf("tenant %q sees %d bookings, want %d", tenant, got, want)
+		}
+	}
+}
+
+func TestPostgresRowLevelSecurityIsolatesTenants(t *testing.T) {
+	db := testutil.NewPostgresSchema(t, migrations.FS)
+	ctx := allTenants()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	if _, err := db.Exec(`INSERT INTO tenants (id, name) VALUES ('nusa-ferry', 'Nusa Ferry')`); err != nil {
+		t.Fatalf("tenant: %v", err)
+	}
+	for i, tenant := range []string{entity.DefaultTenantID, "nusa-ferry"} {
+		routeID := int64(i + 1)
+		if err := NewPostgresRouteRepository(db).Claim(ctx, routeID, tenant); err != nil {
+			t.Fatalf("route: %v", err)
+		}
+		booking := newTestBooking(9, routeID, now)
+		booking.TenantID = tenant
+		if err := NewPostgresBookingRepository(db).Create(ctx, booking); err != nil {
+			t.Fatalf("booking: %v", err)
+		}
+		subscription := &entity.WebhookSubscription{
+			TenantID:   tenant,
+			Partner:    "partner",
+			URL:        "https://partner.example.com/hooks",
+			EventTypes: []string{entity.WebhookAllEvents},
+			Active:     true,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+		if err := NewPostgresWebhookSubscriptionRepository(db).Create(ctx, subscription); err != nil {
+			t.Fatalf("subscription: %v", err)
+		}
+	}
+
+	// The superuser of db bypasses the policies, the application role does
+	// not. One connection makes every scope reuse it.
+	app := testutil.NewPostgresRole(t, db)
+	app.SetMaxOpenConns(1)
+
+	scopes := map[string][]string{
+		entity.DefaultTenantID: {entity.DefaultTenantID},
+		"nusa-ferry":           {"nusa-ferry"},
+		reqctx.AllTenants:      {entity.DefaultTenantID, "nusa-ferry"},
+		"":                     nil,
+	}
+	for _, table := range []string{"bookings", "routes", "webhook_subscriptions"} {
+		for tenant, want := range scopes {
+			var got []string
+			query := `SELECT tenant_id FROM ` + table + ` ORDER BY tenant_id`
+			if err := app.SelectContext(reqctx.WithTenant(context.Background(), tenant), &got, query); err != nil {
+				t.Fatalf("%s as %q: %v", table, tenant, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s as %q: got %v, want %v", table, tenant, got, want)
+			}
+		}
+	}
+
+	nusa := reqctx.WithTenant(context.Background(), "nusa-ferry")
+	err := database.NewTransactor(app).WithinTx(nusa, func(ctx context.Context) error {
+		var tenants []string
+		if err := database.Conn(ctx, app).SelectContext(ctx, &tenants, `SELECT tenant_id FROM bookings`); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(tenants, []string{"nusa-ferry"}) {
+			t.Errorf("bookings in a transaction: %v", tenants)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("transaction: %v", err)
+	}
+
+	// Even a query naming the other tenant finds nothing of it
+	subscriptions, err := NewPostgresWebhookSubscriptionRepository(app).ListActive(nusa, entity.DefaultTenantID, entity.EventBookingPaid)
+	if err != nil || len(subscriptions) != 0 {
+		t.Errorf("subscriptions of another tenant: %+v, %v", subscriptions, err)
+	}
+	result, err := app.ExecContext(nusa, `UPDATE bookings SET user_id = 10 WHERE tenant_id = 'default'`)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if n, _ := result.RowsAffected(); n != 0 {
+		t.Errorf("updated %d bookings of another tenant", n)
+	}
+	if _, err := app.ExecContext(nusa, `INSERT INTO routes (id, tenant_id) VALUES (3, 'default')`); err == nil {
+		t.Error("inserted a route for another tenant")
+	}
+}
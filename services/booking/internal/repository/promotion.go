@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+const promotionColumns = `id, tenant_id, code, description, kind, COALESCE(percent, 0), amount, currency, route_ids, min_qty,
+	max_redemptions, max_per_user, redemptions, starts_at, ends_at, disabled, created_at, updated_at`
+
+type postgresPromotionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresPromotionRepository(db *sqlx.DB) repository.PromotionRepository {
+	return &postgresPromotionRepository{
+		db: db,
+	}
+}
+
+func (r *postgresPromotionRepository) Create(ctx context.Context, p *entity.Promotion) error {
+	query := `
+		INSERT INTO promotions (code, description, kind, percent, amount, currency, route_ids, min_qty,
+		                        max_redemptions, max_per_user, starts_at, ends_at, disabled, created_at, updated_at, tenant_id)
+		VALUES ($1, $2, $3, NULLIF($4, 0), $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		RETURNING id`
+
+	amount, currency := promotionAmount(p)
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		p.Code,
+		p.Description,
+		p.Kind,
+		p.Percent,
+		amount,
+		currency,
+		pq.Array(p.RouteIDs),
+		p.MinQty,
+		p.MaxRedemptions,
+		p.MaxPerUser,
+		p.StartsAt,
+		p.EndsAt,
+		p.Disabled,
+		p.CreatedAt,
+		p.UpdatedAt,
+		p.TenantID,
+	).Scan(&p.ID)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return apperrors.ErrPromotionCodeExists
+	}
+
+	return err
+}
+
+func (r *postgresPromotionRepository) GetByID(ctx context.Context, id int64) (*entity.Promotion, error) {
+	tenant, args := tenantFilter(ctx, "tenant_id", []interface{}{id})
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE id = $1 AND ` + tenant
+
+	p, err := scanPromotion(database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrPromotionNotFound
+	}
+
+	return p, err
+}
+
+func (r *postgresPromotionRepository) GetByCode(ctx context.Context, tenantID, code string, opts ...repository.QueryOption) (*entity.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE tenant_id = $1 AND code = $2` + lockClause(opts)
+
+	p, err := scanPromotion(database.Conn(ctx, r.db).QueryRowContext(ctx, query, tenantID, code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrPromotionNotFound
+	}
+
+	return p, err
+}
+
+func (r *postgresPromotionRepository) List(ctx context.Context, limit, offset int) ([]*entity.Promotion, error) {
+	tenant, args := tenantFilter(ctx, "tenant_id", []interface{}{limit, offset})
+	query := `
+		SELECT ` + promotionColumns + `
+		FROM promotions
+		WHERE ` + tenant + `
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2`
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var promotions []*entity.Promotion
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+
+	return promotions, rows.Err()
+}
+
+func (r *postgresPromotionRepository) Update(ctx context.Context, p *entity.Promotion) error {
+	amount, currency := promotionAmount(p)
+	tenant, args := tenantFilter(ctx, "tenant_id", []interface{}{
+		p.ID,
+		p.Description,
+		p.Kind,
+		p.Percent,
+		amount,
+		currency,
+		pq.Array(p.RouteIDs),
+		p.MinQty,
+		p.MaxRedemptions,
+		p.MaxPerUser,
+		p.StartsAt,
+		p.EndsAt,
+		p.Disabled,
+		p.UpdatedAt,
+	})
+	query := `
+		UPDATE promotions
+		SET description = $2, kind = $3, percent = NULLIF($4, 0), amount = $5, currency = $6, route_ids = $7,
+		    min_qty = $8, max_redemptions = $9, max_per_user = $10, starts_at = $11, ends_at = $12,
+		    disabled = $13, updated_at = $14
+		WHERE id = $1 AND ` + tenant
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result, apperrors.ErrPromotionNotFound)
+}
+
+func (r *postgresPromotionRepository) CountRedemptions(ctx context.Context, promotionID, userID int64) (int, error) {
+	var n int
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT count(*) FROM promotion_redemptions WHERE promotion_id = $1 AND user_id = $2`, promotionID, userID,
+	).Scan(&n)
+
+	return n, err
+}
+
+func (r *postgresPromotionRepository) Redeem(ctx context.Context, redemption *entity.PromotionRedemption) error {
+	// One statement keeps the redemption and the count in step
+	query := `
+		WITH counted AS (
+			UPDATE promotions SET redemptions = redemptions + 1
+			WHERE id = $1
+			RETURNING id
+		)
+		INSERT INTO promotion_redemptions (promotion_id, booking_id, user_id, discount, currency, created_at)
+		SELECT id, $2, $3, $4, $5, $6 FROM counted
+		RETURNING id`
+
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		redemption.PromotionID,
+		redemption.BookingID,
+		redemption.UserID,
+		redemption.Discount.Amount(),
+		redemption.Discount.Currency(),
+		redemption.CreatedAt,
+	).Scan(&redemption.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperrors.ErrPromotionNotFound
+	}
+
+	return err
+}
+
+func (r *postgresPromotionRepository) Release(ctx context.Context, bookingID int64) (bool, error) {
+	query := `
+		WITH released AS (
+			DELETE FROM promotion_redemptions
+			WHERE booking_id = $1
+			RETURNING promotion_id
+		)
+		UPDATE promotions SET redemptions = redemptions - 1
+		WHERE id = (SELECT promotion_id FROM released)`
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, bookingID)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// promotionAmount returns the stored form of a fixed promotion's amount,
+// NULLs for percentages.
+func promotionAmount(p *entity.Promotion) (sql.NullInt64, sql.NullString) {
+	if p.Amount == nil {
+		return sql.NullInt64{}, sql.NullString{}
+	}
+	return sql.NullInt64{Int64: p.Amount.Amount(), Valid: true},
+		sql.NullString{String: string(p.Amount.Currency()), Valid: true}
+}
+
+func scanPromotion(row rowScanner) (*entity.Promotion, error) {
+	p := &entity.Promotion{}
+	var (
+		amount   sql.NullInt64
+		currency sql.NullString
+		routeIDs pq.Int64Array
+	)
+	err := row.Scan(
+		&p.ID,
+		&p.TenantID,
+		&p.Code,
+		&p.Description,
+		&p.Kind,
+		&p.Percent,
+		&amount,
+		&currency,
+		&routeIDs,
+		&p.MinQty,
+		&p.MaxRedemptions,
+		&p.MaxPerUser,
+		&p.Redemptions,
+		&p.StartsAt,
+		&p.EndsAt,
+		&p.Disabled,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	p.RouteIDs = routeIDs
+
+	if amount.Valid {
+		m, err := money.New(amount.Int64, money.Currency(currency.String))
+		if err != nil {
+			return nil, err
+		}
+		p.Amount = &m
+	}
+
+	return p, nil
+}
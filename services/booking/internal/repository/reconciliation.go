@@ -0,0 +1,339 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+// reconcileCurrent finds every discrepancy there is now, for the statements
+// that open and resolve them to share. matches pairs each settlement in
+// scope with the paid booking it settles: the booking of the provider's
+// succeeded payment with that reference, else the booking the provider
+// passed through. Amounts compare with their currencies; bookings paid
+// without a payment event expect their price.
+// Settlements ingested since $1 are in scope, as are those already flagged;
+// bookings paid in [$1, $2) must have a settlement, as must those already
+// flagged.
+const reconcileCurrent = `
+	WITH matches AS (
+		SELECT s.id AS settlement_id, s.provider, s.reference, s.amount, s.currency,
+			m.booking_id, m.expected, m.expected_currency,
+			EXISTS (
+				SELECT 1 FROM settlements d
+				WHERE d.provider = s.provider AND d.reference = s.reference AND d.id < s.id
+			) AS duplicate
+		FROM settlements s
+		LEFT JOIN LATERAL (
+			SELECT x.booking_id, x.expected, x.expected_currency
+			FROM (
+				SELECT pe.booking_id, pe.amount AS expected, pe.currency AS expected_currency, 0 AS rank
+				FROM payment_events pe
+				WHERE pe.provider = s.provider AND pe.event_id = s.reference AND pe.event_type = 'payment.succeeded'
+				UNION ALL
+				SELECT b.id, b.price_total, b.currency, 1
+				FROM bookings b
+				WHERE b.id = s.booking_id
+			) x
+			WHERE EXISTS (SELECT 1 FROM booking_events e WHERE e.booking_id = x.booking_id AND e.new_status = 'PAID')
+			ORDER BY x.rank
+			LIMIT 1
+		) m ON TRUE
+		WHERE s.ingested_at >= $1 OR s.id IN (
+			SELECT settlement_id FROM reconciliation_discrepancies
+			WHERE resolved_at IS NULL AND settlement_id IS NOT NULL
+		)
+	),
+	found AS (
+		SELECT 'DUPLICATE_SETTLEMENT' AS kind, settlement_id, booking_id, provider, reference,
+			expected, expected_currency, amount AS settled, currency AS settled_currency
+		FROM matches
+		WHERE duplicate
+		UNION ALL
+		SELECT 'UNMATCHED_SETTLEMENT', settlement_id, NULL, provider, reference, NULL, NULL, amount, currency
+		FROM matches
+		WHERE NOT duplicate AND booking_id IS NULL
+		UNION ALL
+		SELECT 'AMOUNT_MISMATCH', settlement_id, booking_id, provider, reference, expected, expected_currency, amount, currency
+		FROM matches
+		WHERE NOT duplicate AND booking_id IS NOT NULL AND (amount <> expected OR currency <> expected_currency)
+		UNION ALL
+		SELECT 'UNSETTLED_PAYMENT', NULL, b.id, pe.provider, pe.event_id,
+			COALESCE(pe.amount, b.price_total), COALESCE(pe.currency, b.currency), NULL, NULL
+		FROM bookings b
+		LEFT JOIN LATERAL (
+			SELECT provider, event_id, amount, currency
+			FROM payment_events
+			WHERE booking_id = b.id AND event_type = 'payment.succeeded' AND outcome = 'APPLIED'
+			ORDER BY occurred_at DESC
+			LIMIT 1
+		) pe ON TRUE
+		WHERE b.id IN (
+			SELECT booking_id FROM booking_events
+			WHERE new_status = 'PAID' AND created_at >= $1 AND created_at < $2
+			UNION
+			SELECT booking_id FROM reconciliation_discrepancies
+			WHERE resolved_at IS NULL AND kind = 'UNSETTLED_PAYMENT'
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM settlements s
+			WHERE s.booking_id = b.id OR (s.provider = pe.provider AND s.reference = pe.event_id)
+		)
+	)`
+
+type postgresReconciliationRepository struct {
+	db         *sqlx.DB
+	transactor *database.Transactor
+}
+
+func NewPostgresReconciliationRepository(db *sqlx.DB) repository.ReconciliationRepository {
+	return &postgresReconciliationRepository{
+		db:         db,
+		transactor: database.NewTransactor(db),
+	}
+}
+
+func (r *postgresReconciliationRepository) SaveFile(ctx context.Context, file *entity.SettlementFile, settlements []*entity.Settlement) (bool, error) {
+	saved := false
+	err := r.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+
+		query := `
+			INSERT INTO settlement_files (provider, name, checksum, rows)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (checksum) DO NOTHING
+			RETURNING id, ingested_at`
+		err := conn.QueryRowContext(ctx, query, file.Provider, file.Name, file.Checksum, len(settlements)).
+			Scan(&file.ID, &file.IngestedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		file.Rows = len(settlements)
+
+		query = `
+			INSERT INTO settlements (file_id, line, provider, reference, booking_id, amount, currency, fee, settled_at, ingested_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			RETURNING id`
+		for _, s := range settlements {
+			s.FileID = file.ID
+			s.Provider = file.Provider
+			err := conn.QueryRowContext(ctx, query,
+				s.FileID, s.Line, s.Provider, s.Reference, s.BookingID, s.Amount.Amount(), s.Amount.Currency(), s.Fee.Amount(),
+				s.SettledAt, file.IngestedAt,
+			).Scan(&s.ID)
+			if err != nil {
+				return err
+			}
+		}
+
+		saved = true
+		return nil
+	})
+
+	return saved, err
+}
+
+func (r *postgresReconciliationRepository) Reconcile(ctx context.Context, paidFrom, paidBefore time.Time) (int, int, error) {
+	var opened, resolved int
+	err := r.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+
+		// Serialise runs; the lock is released with the transaction
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('reconciliation_discrepancies'))`); err != nil {
+			return err
+		}
+
+		query := reconcileCurrent + `
+			INSERT INTO reconciliation_discrepancies (kind, provider, reference, booking_id, settlement_id,
+				expected, expected_currency, settled, settled_currency)
+			SELECT kind, provider, reference, booking_id, settlement_id,
+				expected, expected_currency, settled, settled_currency
+			FROM found
+			ON CONFLICT (kind, COALESCE(settlement_id, 0), COALESCE(booking_id, 0)) WHERE resolved_at IS NULL
+			DO NOTHING`
+		result, err := conn.ExecContext(ctx, query, paidFrom, paidBefore)
+		if err != nil {
+			return err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		opened = int(n)
+
+		// Discrepancies opened above are found again, so stay open
+		query = reconcileCurrent + `
+			UPDATE reconciliation_discrepancies d
+			SET resolved_at = now()
+			WHERE d.resolved_at IS NULL
+				AND NOT EXISTS (
+					SELECT 1 FROM found c
+					WHERE c.kind = d.kind
+						AND COALESCE(c.settlement_id, 0) = COALESCE(d.settlement_id, 0)
+						AND COALESCE(c.booking_id, 0) = COALESCE(d.booking_id, 0)
+				)`
+		if result, err = conn.ExecContext(ctx, query, paidFrom, paidBefore); err != nil {
+			return err
+		}
+		if n, err = result.RowsAffected(); err != nil {
+			return err
+		}
+		resolved = int(n)
+
+		return nil
+	})
+
+	return opened, resolved, err
+}
+
+func (r *postgresReconciliationRepository) SaveRun(ctx context.Context, run *entity.ReconciliationRun) error {
+	query := `
+		INSERT INTO reconciliation_runs (started_at, finished_at, files, settlements, opened, resolved, errors)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id`
+
+	return database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		run.StartedAt, run.FinishedAt, run.Files, run.Settlements, run.Opened, run.Resolved, pq.Array(run.Errors),
+	).Scan(&run.ID)
+}
+
+func (r *postgresReconciliationRepository) LastRun(ctx context.Context) (*entity.ReconciliationRun, error) {
+	query := `
+		SELECT id, started_at, finished_at, files, settlements, opened, resolved, errors
+		FROM reconciliation_runs
+		ORDER BY id DESC
+		LIMIT 1`
+
+	var run entity.ReconciliationRun
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query).Scan(
+		&run.ID, &run.StartedAt, &run.FinishedAt, &run.Files, &run.Settlements, &run.Opened, &run.Resolved, pq.Array(&run.Errors),
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+func (r *postgresReconciliationRepository) ListDiscrepancies(ctx context.Context, filter entity.DiscrepancyFilter, limit, offset int) ([]*entity.Discrepancy, error) {
+	where, args := discrepancyFilterClause(filter, nil)
+	query := fmt.Sprintf(`
+		SELECT id, kind, COALESCE(provider, ''), COALESCE(reference, ''), booking_id, settlement_id,
+			expected, expected_currency, settled, settled_currency, detected_at, resolved_at
+		FROM reconciliation_discrepancies
+		WHERE %s
+		ORDER BY id DESC
+		LIMIT $%d OFFSET $%d`, where, len(args)+1, len(args)+2)
+	args = append(args, limit, offset)
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*entity.Discrepancy{}
+	for rows.Next() {
+		var (
+			d                                 entity.Discrepancy
+			expected, settled                 sql.NullInt64
+			expectedCurrency, settledCurrency sql.NullString
+		)
+		err := rows.Scan(&d.ID, &d.Kind, &d.Provider, &d.Reference, &d.BookingID, &d.SettlementID,
+			&expected, &expectedCurrency, &settled, &settledCurrency, &d.DetectedAt, &d.ResolvedAt)
+		if err != nil {
+			return nil, err
+		}
+		if d.Expected, err = nullMoney(expected, expectedCurrency); err != nil {
+			return nil, err
+		}
+		if d.Settled, err = nullMoney(settled, settledCurrency); err != nil {
+			return nil, err
+		}
+		result = append(result, &d)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *postgresReconciliationRepository) CountOpen(ctx context.Context) (map[entity.DiscrepancyKind]int, error) {
+	query := `
+		SELECT kind, count(*)
+		FROM reconciliation_discrepancies
+		WHERE resolved_at IS NULL
+		GROUP BY kind`
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[entity.DiscrepancyKind]int{}
+	for rows.Next() {
+		var kind entity.DiscrepancyKind
+		var n int
+		if err := rows.Scan(&kind, &n); err != nil {
+			return nil, err
+		}
+		counts[kind] = n
+	}
+
+	return counts, rows.Err()
+}
+
+// nullMoney reads an optional amount stored with its currency.
+func nullMoney(amount sql.NullInt64, currency sql.NullString) (*money.Money, error) {
+	if !amount.Valid {
+		return nil, nil
+	}
+	m, err := money.New(amount.Int64, money.Currency(currency.String))
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func discrepancyFilterClause(filter entity.DiscrepancyFilter, args []interface{}) (string, []interface{}) {
+	var conds []string
+	add := func(cond string, value interface{}) {
+		args = append(args, value)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.Kind != "" {
+		add("kind = $%d", filter.Kind)
+	}
+	if filter.Provider != "" {
+		add("provider = $%d", filter.Provider)
+	}
+	if filter.Resolved != nil {
+		if *filter.Resolved {
+			conds = append(conds, "resolved_at IS NOT NULL")
+		} else {
+			conds = append(conds, "resolved_at IS NULL")
+		}
+	}
+
+	if len(conds) == 0 {
+		return "TRUE", args
+	}
+	return strings.Join(conds, " AND "), args
+}
@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+// hourBucket truncates created_at to the UTC hour regardless of the session
+// time zone.
+const hourBucket = `date_trunc('hour', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'`
+
+// rollupSource is every booking that counts towards reports, live or
+// archived, with its hourly bucket.
+const rollupSource = `
+	SELECT id, tenant_id, route_id, currency, status, qty, price_total, ` + hourBucket + ` AS bucket
+	FROM bookings
+	WHERE deleted_at IS NULL
+	UNION ALL
+	SELECT id, COALESCE(data->>'tenant_id', 'default'), route_id, data->>'currency', status, (data->>'qty')::int,
+		(data->>'price_total')::bigint, ` + hourBucket + `
+	FROM bookings_archive
+	WHERE deleted_at IS NULL`
+
+// rollupInsert recomputes the buckets matched by the %s placeholder. A
+// booking counts as paid or confirmed if it ever reached that status, which
+// the history records even after it moved on; bookings older than the
+// history fall back to their current status. Refunds of single passengers
+// are left out as their share already left the booking total.
+const rollupInsert = `
+	INSERT INTO report_booking_rollups (bucket_start, tenant_id, route_id, currency, status, bookings, seats, paid, confirmed, revenue, refunds)
+	SELECT s.bucket, s.tenant_id, s.route_id, s.currency, s.status,
+		count(*),
+		sum(s.qty),
+		count(*) FILTER (WHERE s.paid),
+		count(*) FILTER (WHERE s.confirmed),
+		COALESCE(sum(s.price_total) FILTER (WHERE s.paid), 0),
+		COALESCE(sum(s.refunds), 0)
+	FROM (
+		SELECT src.*,
+			src.status IN ('PAID', 'CONFIRMED') OR EXISTS (
+				SELECT 1 FROM booking_events e WHERE e.booking_id = src.id AND e.new_status = 'PAID'
+			) AS paid,
+			src.status = 'CONFIRMED' OR EXISTS (
+				SELECT 1 FROM booking_events e WHERE e.booking_id = src.id AND e.new_status = 'CONFIRMED'
+			) AS confirmed,
+			(
+				SELECT sum((o.payload->'amount'->>'amount')::bigint)
+				FROM outbox_events o
+				WHERE o.aggregate_id = src.id
+					AND o.event_type = 'booking.refund_requested'
+					AND o.payload->'passenger_id' IS NULL
+			) AS refunds
+		FROM (` + rollupSource + `) src
+		WHERE %s
+	) s
+	GROUP BY s.bucket, s.tenant_id, s.route_id, s.currency, s.status`
+
+type postgresReportRepository struct {
+	db         *sqlx.DB
+	transactor *database.Transactor
+}
+
+func NewPostgresReportRepository(db *sqlx.DB) repository.ReportRepository {
+	return &postgresReportRepository{
+		db:         db,
+		transactor: database.NewTransactor(db),
+	}
+}
+
+func (r *postgresReportRepository) Refresh(ctx context.Context, full bool, overlap time.Duration) (int, error) {
+	// The rollups of every tenant share their buckets, so a refresh asked for
+	// by one tenant's admin still recomputes them all
+	ctx = reqctx.WithTenant(ctx, reqctx.AllTenants)
+
+	var recomputed int
+	err := r.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		conn := database.Conn(ctx, r.db)
+
+		// Serialise refreshes; the lock is released with the transaction
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext('report_booking_rollups'))`); err != nil {
+			return err
+		}
+		started := time.Now()
+
+		var refreshedUntil *time.Time
+		if !full {
+			var err error
+			if refreshedUntil, err = r.RefreshedUntil(ctx); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if refreshedUntil == nil {
+			recomputed, err = r.rebuild(ctx, conn)
+		} else {
+			recomputed, err = r.recompute(ctx, conn, refreshedUntil.Add(-overlap))
+		}
+		if err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO report_refresh_state (id, refreshed_until)
+			VALUES (TRUE, $1)
+			ON CONFLICT (id) DO UPDATE SET refreshed_until = EXCLUDED.refreshed_until`
+		_, err = conn.ExecContext(ctx, query, started)
+		return err
+	})
+
+	return recomputed, err
+}
+
+// rebuild recomputes every bucket.
+func (r *postgresReportRepository) rebuild(ctx context.Context, conn database.Executor) (int, error) {
+	if _, err := conn.ExecContext(ctx, `DELETE FROM report_booking_rollups`); err != nil {
+		return 0, err
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf(rollupInsert, "TRUE")); err != nil {
+		return 0, err
+	}
+
+	var n int
+	err := conn.QueryRowContext(ctx, `SELECT count(DISTINCT bucket_start) FROM report_booking_rollups`).Scan(&n)
+	return n, err
+}
+
+// recompute recomputes the buckets of bookings updated since since.
+func (r *postgresReportRepository) recompute(ctx context.Context, conn database.Executor, since time.Time) (int, error) {
+	var buckets []time.Time
+	touched := `SELECT DISTINCT ` + hourBucket + ` FROM bookings WHERE updated_at >= $1`
+	if err := conn.SelectContext(ctx, &buckets, touched, since); err != nil {
+		return 0, err
+	}
+	if len(buckets) == 0 {
+		return 0, nil
+	}
+
+	// Buckets travel as text so the array needs no driver support for times
+	encoded := make([]string, len(buckets))
+	for i, bucket := range buckets {
+		encoded[i] = bucket.UTC().Format(time.RFC3339)
+	}
+	if _, err := conn.ExecContext(ctx, `DELETE FROM report_booking_rollups WHERE bucket_start = ANY($1::timestamptz[])`, pq.Array(encoded)); err != nil {
+		return 0, err
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf(rollupInsert, "src.bucket = ANY($1::timestamptz[])"), pq.Array(encoded)); err != nil {
+		return 0, err
+	}
+
+	return len(buckets), nil
+}
+
+func (r *postgresReportRepository) RefreshedUntil(ctx context.Context) (*time.Time, error) {
+	var at time.Time
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, `SELECT refreshed_until FROM report_refresh_state`).Scan(&at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &at, nil
+}
+
+func (r *postgresReportRepository) Revenue(ctx context.Context, q *entity.ReportQuery) ([]*entity.RevenueRow, error) {
+	where, args := reportWhere(ctx, q)
+	query := `
+		SELECT ` + reportPeriod + `, ` + reportRoute(q) + `, currency,
+			sum(bookings), sum(seats), sum(paid), sum(revenue), sum(refunds)
+		FROM report_booking_rollups
+		WHERE ` + where + `
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3`
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*entity.RevenueRow{}
+	for rows.Next() {
+		row := &entity.RevenueRow{}
+		var period, currency string
+		var revenue, refunds int64
+		if err := rows.Scan(&period, &row.RouteID, &currency, &row.Bookings, &row.Seats, &row.PaidBookings, &revenue, &refunds); err != nil {
+			return nil, err
+		}
+		if row.Period, err = entity.ParseDate(period); err != nil {
+			return nil, err
+		}
+		if row.Revenue, err = money.New(revenue, money.Currency(currency)); err != nil {
+			return nil, err
+		}
+		if row.Refunds, err = money.New(refunds, money.Currency(currency)); err != nil {
+			return nil, err
+		}
+		if row.NetRevenue, err = row.Revenue.Sub(row.Refunds); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *postgresReportRepository) Funnel(ctx context.Context, q *entity.ReportQuery) ([]*entity.FunnelRow, error) {
+	where, args := reportWhere(ctx, q)
+	query := `
+		SELECT ` + reportPeriod + `, ` + reportRoute(q) + `,
+			sum(bookings), sum(paid), sum(confirmed),
+			COALESCE(sum(bookings) FILTER (WHERE status = 'EXPIRED'), 0),
+			COALESCE(sum(bookings) FILTER (WHERE status = 'CANCELLED'), 0),
+			COALESCE(sum(bookings) FILTER (WHERE status = 'REFUNDED'), 0)
+		FROM report_booking_rollups
+		WHERE ` + where + `
+		GROUP BY 1, 2
+		ORDER BY 1, 2`
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*entity.FunnelRow{}
+	for rows.Next() {
+		row := &entity.FunnelRow{}
+		var period string
+		if err := rows.Scan(&period, &row.RouteID, &row.Created, &row.Paid, &row.Confirmed, &row.Expired, &row.Cancelled, &row.Refunded); err != nil {
+			return nil, err
+		}
+		if row.Period, err = entity.ParseDate(period); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+func (r *postgresReportRepository) Statuses(ctx context.Context, q *entity.ReportQuery) ([]*entity.StatusRow, error) {
+	where, args := reportWhere(ctx, q)
+	query := `
+		SELECT ` + reportPeriod + `, ` + reportRoute(q) + `, status, sum(bookings), sum(seats)
+		FROM report_booking_rollups
+		WHERE ` + where + `
+		GROUP BY 1, 2, 3
+		ORDER BY 1, 2, 3`
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []*entity.StatusRow{}
+	for rows.Next() {
+		row := &entity.StatusRow{}
+		var period string
+		if err := rows.Scan(&period, &row.RouteID, &row.Status, &row.Bookings, &row.Seats); err != nil {
+			return nil, err
+		}
+		if row.Period, err = entity.ParseDate(period); err != nil {
+			return nil, err
+		}
+		result = append(result, row)
+	}
+
+	return result, rows.Err()
+}
+
+// reportPeriod is the local start date of the period a bucket falls in.
+// $3 is the interval and $4 the time zone name.
+const reportPeriod = `to_char(date_trunc($3, bucket_start AT TIME ZONE $4), 'YYYY-MM-DD')`
+
+func reportRoute(q *entity.ReportQuery) string {
+	if q.ByRoute {
+		return "route_id"
+	}
+	return "NULL::bigint"
+}
+
+// reportWhere selects the buckets of q within the tenant in ctx. $3 and $4
+// are left to reportPeriod.
+func reportWhere(ctx context.Context, q *entity.ReportQuery) (string, []interface{}) {
+	args := []interface{}{q.Start(), q.End(), string(q.Interval), q.Location.String()}
+	where := "bucket_start >= $1 AND bucket_start < $2"
+	if q.RouteID != 0 {
+		args = append(args, q.RouteID)
+		where += fmt.Sprintf(" AND route_id = $%d", len(args))
+	}
+
+	tenant, args := tenantFilter(ctx, "tenant_id", args)
+	return where + " AND " + tenant, args
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+const tenantColumns = `id, name, settings, created_at, updated_at`
+
+type postgresTenantRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresTenantRepository(db *sqlx.DB) repository.TenantRepository {
+	return &postgresTenantRepository{
+		db: db,
+	}
+}
+
+func (r *postgresTenantRepository) Create(ctx context.Context, t *entity.Tenant) error {
+	settings, err := json.Marshal(t.Settings)
+	if err != nil {
+		return err
+	}
+
+	_, err = database.Conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO tenants (id, name, settings, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)`,
+		t.ID, t.Name, settings, t.CreatedAt, t.UpdatedAt,
+	)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return apperrors.ErrTenantExists
+	}
+
+	return err
+}
+
+func (r *postgresTenantRepository) GetByID(ctx context.Context, id string) (*entity.Tenant, error) {
+	query := `SELECT ` + tenantColumns + ` FROM tenants WHERE id = $1`
+
+	t, err := scanTenant(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrTenantNotFound
+	}
+
+	return t, err
+}
+
+func (r *postgresTenantRepository) List(ctx context.Context, limit, offset int) ([]*entity.Tenant, error) {
+	query := `
+		SELECT ` + tenantColumns + `
+		FROM tenants
+		ORDER BY id
+		LIMIT $1 OFFSET $2`
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tenants []*entity.Tenant
+	for rows.Next() {
+		t, err := scanTenant(rows)
+		if err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, t)
+	}
+
+	return tenants, rows.Err()
+}
+
+func (r *postgresTenantRepository) Update(ctx context.Context, t *entity.Tenant) error {
+	settings, err := json.Marshal(t.Settings)
+	if err != nil {
+		return err
+	}
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE tenants SET name = $2, settings = $3, updated_at = $4 WHERE id = $1`,
+		t.ID, t.Name, settings, t.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result, apperrors.ErrTenantNotFound)
+}
+
+func scanTenant(row rowScanner) (*entity.Tenant, error) {
+	t := &entity.Tenant{}
+	var settings []byte
+	if err := row.Scan(&t.ID, &t.Name, &settings, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(settings, &t.Settings); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+type postgresRouteRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresRouteRepository(db *sqlx.DB) repository.RouteRepository {
+	return &postgresRouteRepository{
+		db: db,
+	}
+}
+
+func (r *postgresRouteRepository) Claim(ctx context.Context, routeID int64, tenantID string) error {
+	return r.Save(ctx, &entity.Route{ID: routeID, TenantID: tenantID})
+}
+
+func (r *postgresRouteRepository) Save(ctx context.Context, route *entity.Route) error {
+	// A route of another tenant is hidden by row-level security when ctx is
+	// scoped, so it surfaces as a missing row rather than a conflicting one
+	query := `
+		WITH inserted AS (
+			INSERT INTO routes (id, tenant_id, name)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (id) DO NOTHING
+			RETURNING tenant_id, name, created_at
+		), renamed AS (
+			UPDATE routes SET name = $3
+			WHERE id = $1 AND tenant_id = $2 AND $3 <> '' AND NOT EXISTS (SELECT 1 FROM inserted)
+			RETURNING tenant_id, name, created_at
+		)
+		SELECT tenant_id, name, created_at FROM inserted
+		UNION ALL
+		SELECT tenant_id, name, created_at FROM renamed
+		UNION ALL
+		SELECT tenant_id, name, created_at FROM routes
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM inserted) AND NOT EXISTS (SELECT 1 FROM renamed)`
+
+	var owner string
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, route.ID, route.TenantID, route.Name).
+		Scan(&owner, &route.Name, &route.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && owner != route.TenantID) {
+		return apperrors.ErrForeignRoute
+	}
+
+	return err
+}
+
+func (r *postgresRouteRepository) List(ctx context.Context, tenantID string, limit, offset int) ([]*entity.Route, error) {
+	query := `
+		SELECT id, tenant_id, name, created_at
+		FROM routes
+		WHERE tenant_id = $1
+		ORDER BY id
+		LIMIT $2 OFFSET $3`
+
+	var routes []*entity.Route
+	err := database.Conn(ctx, r.db).SelectContext(ctx, &routes, query, tenantID, limit, offset)
+	return routes, err
+}
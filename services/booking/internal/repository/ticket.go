@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ibnuzaman/porta-pay/pkg/cache"
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+const ticketColumns = `id, booking_id, seq, passenger_id, COALESCE(holder_name, ''), COALESCE(seat, ''),
+		status, token, issued_at, revoked_at, used_at, COALESCE(used_by, '')`
+
+type postgresTicketRepository struct {
+	db    *sqlx.DB
+	cache cache.Cache
+}
+
+// NewPostgresTicketRepository returns the ticket store. Check-ins evict the
+// booking from bookingCache, the cache behind NewCachedBookingRepository, or
+// nil when bookings are not cached.
+func NewPostgresTicketRepository(db *sqlx.DB, bookingCache cache.Cache) repository.TicketRepository {
+	return &postgresTicketRepository{
+		db:    db,
+		cache: bookingCache,
+	}
+}
+
+func scanTicket(row rowScanner) (*entity.Ticket, error) {
+	ticket := &entity.Ticket{}
+	err := row.Scan(
+		&ticket.ID,
+		&ticket.BookingID,
+		&ticket.Seq,
+		&ticket.PassengerID,
+		&ticket.HolderName,
+		&ticket.Seat,
+		&ticket.Status,
+		&ticket.Token,
+		&ticket.IssuedAt,
+		&ticket.RevokedAt,
+		&ticket.UsedAt,
+		&ticket.UsedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return ticket, nil
+}
+
+func (r *postgresTicketRepository) Create(ctx context.Context, ticket *entity.Ticket) (bool, error) {
+	query := `
+		INSERT INTO tickets (id, booking_id, seq, passenger_id, holder_name, seat, status, token, issued_at)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), $7, $8, $9)
+		ON CONFLICT (booking_id, seq) DO NOTHING`
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		ticket.ID,
+		ticket.BookingID,
+		ticket.Seq,
+		ticket.PassengerID,
+		ticket.HolderName,
+		ticket.Seat,
+		ticket.Status,
+		ticket.Token,
+		ticket.IssuedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+func (r *postgresTicketRepository) GetByID(ctx context.Context, id string) (*entity.Ticket, error) {
+	// Tickets belong to the tenant of their booking
+	tenant, args := tenantFilter(ctx, "tenant_id", []interface{}{id})
+	query := `
+		SELECT ` + ticketColumns + `
+		FROM tickets
+		WHERE id = $1 AND booking_id IN (SELECT id FROM bookings WHERE ` + tenant + `)`
+
+	ticket, err := scanTicket(database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrTicketNotFound
+	}
+	return ticket, err
+}
+
+func (r *postgresTicketRepository) ListByBookingID(ctx context.Context, bookingID int64) ([]*entity.Ticket, error) {
+	query := `
+		SELECT ` + ticketColumns + `
+		FROM tickets
+		WHERE booking_id = $1
+		ORDER BY seq`
+
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tickets := []*entity.Ticket{}
+	for rows.Next() {
+		ticket, err := scanTicket(rows)
+		if err != nil {
+			return nil, err
+		}
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, rows.Err()
+}
+
+func (r *postgresTicketRepository) MarkUsed(ctx context.Context, id string, at time.Time, by string) (*entity.Ticket, error) {
+	// The conditional update is the double-scan guard: of two concurrent
+	// scans only one matches a row with used_at still NULL.
+	tenant, args := tenantFilter(ctx, "b.tenant_id", []interface{}{id, at, by})
+	query := `
+		WITH used AS (
+			UPDATE tickets t
+			SET used_at = $2, used_by = NULLIF($3, '')
+			FROM bookings b
+			WHERE t.id = $1 AND t.status = 'ISSUED' AND t.used_at IS NULL
+				AND b.id = t.booking_id AND b.status = 'CONFIRMED' AND b.deleted_at IS NULL AND ` + tenant + `
+			RETURNING t.*
+		), boarded AS (
+			UPDATE booking_passengers p
+			SET checked_in_at = $2
+			FROM used
+			WHERE p.id = used.passenger_id
+		)
+		SELECT ` + ticketColumns + `
+		FROM used`
+
+	ticket, err := scanTicket(database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrTicketNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The cached booking still shows the passenger as not checked in
+	if r.cache != nil {
+		database.AfterCommit(ctx, func() {
+			r.cache.Delete(context.WithoutCancel(ctx), bookingCacheKey(ticket.BookingID))
+		})
+	}
+	return ticket, nil
+}
+
+func (r *postgresTicketRepository) RevokeByBooking(ctx context.Context, bookingID int64, at time.Time) (int, error) {
+	query := `
+		UPDATE tickets
+		SET status = 'REVOKED', revoked_at = $2
+		WHERE booking_id = $1 AND status = 'ISSUED'`
+
+	return r.revoke(ctx, query, bookingID, at)
+}
+
+func (r *postgresTicketRepository) RevokeByPassenger(ctx context.Context, passengerID int64, at time.Time) (int, error) {
+	query := `
+		UPDATE tickets
+		SET status = 'REVOKED', revoked_at = $2
+		WHERE passenger_id = $1 AND status = 'ISSUED'`
+
+	return r.revoke(ctx, query, passengerID, at)
+}
+
+func (r *postgresTicketRepository) revoke(ctx context.Context, query string, id int64, at time.Time) (int, error) {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, id, at)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := result.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,348 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+const subscriptionColumns = `id, tenant_id, partner, description, url, secret, event_types, user_id, active, created_at, updated_at`
+
+const deliveryColumns = `id, subscription_id, event_id, event_type, booking_id, payload, status, attempts,
+	next_attempt_at, last_error, created_at, delivered_at`
+
+type postgresWebhookSubscriptionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresWebhookSubscriptionRepository(db *sqlx.DB) repository.WebhookSubscriptionRepository {
+	return &postgresWebhookSubscriptionRepository{
+		db: db,
+	}
+}
+
+func (r *postgresWebhookSubscriptionRepository) Create(ctx context.Context, s *entity.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (partner, description, url, secret, event_types, user_id, active, created_at, updated_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id`
+
+	return database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		s.Partner,
+		s.Description,
+		s.URL,
+		s.Secret,
+		pq.Array(s.EventTypes),
+		s.UserID,
+		s.Active,
+		s.CreatedAt,
+		s.UpdatedAt,
+		s.TenantID,
+	).Scan(&s.ID)
+}
+
+func (r *postgresWebhookSubscriptionRepository) GetByID(ctx context.Context, id int64) (*entity.WebhookSubscription, error) {
+	tenant, args := tenantFilter(ctx, "tenant_id", []interface{}{id})
+	query := `SELECT ` + subscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1 AND ` + tenant
+
+	s, err := scanSubscription(database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrWebhookSubscriptionNotFound
+	}
+
+	return s, err
+}
+
+func (r *postgresWebhookSubscriptionRepository) List(ctx context.Context, limit, offset int) ([]*entity.WebhookSubscription, error) {
+	tenant, args := tenantFilter(ctx, "tenant_id", []interface{}{limit, offset})
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE ` + tenant + `
+		ORDER BY id
+		LIMIT $1 OFFSET $2`
+
+	return r.query(ctx, query, args...)
+}
+
+func (r *postgresWebhookSubscriptionRepository) ListActive(ctx context.Context, tenantID, eventType string) ([]*entity.WebhookSubscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE active AND tenant_id = $1 AND (event_types && ARRAY[$2, '` + entity.WebhookAllEvents + `'])
+		ORDER BY id`
+
+	return r.query(ctx, query, tenantID, eventType)
+}
+
+func (r *postgresWebhookSubscriptionRepository) Deactivate(ctx context.Context, id int64) error {
+	tenant, args := tenantFilter(ctx, "tenant_id", []interface{}{id})
+	query := `UPDATE webhook_subscriptions SET active = FALSE, updated_at = now() WHERE id = $1 AND ` + tenant
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result, apperrors.ErrWebhookSubscriptionNotFound)
+}
+
+func (r *postgresWebhookSubscriptionRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entity.WebhookSubscription, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []*entity.WebhookSubscription
+	for rows.Next() {
+		s, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, s)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func scanSubscription(row rowScanner) (*entity.WebhookSubscription, error) {
+	s := &entity.WebhookSubscription{}
+	var eventTypes pq.StringArray
+	err := row.Scan(
+		&s.ID,
+		&s.TenantID,
+		&s.Partner,
+		&s.Description,
+		&s.URL,
+		&s.Secret,
+		&eventTypes,
+		&s.UserID,
+		&s.Active,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	s.EventTypes = eventTypes
+
+	return s, nil
+}
+
+type postgresWebhookDeliveryRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresWebhookDeliveryRepository(db *sqlx.DB) repository.WebhookDeliveryRepository {
+	return &postgresWebhookDeliveryRepository{
+		db: db,
+	}
+}
+
+func (r *postgresWebhookDeliveryRepository) Enqueue(ctx context.Context, d *entity.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, booking_id, payload)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (subscription_id, event_id) DO UPDATE
+		SET status = 'PENDING', attempts = 0, next_attempt_at = now(), last_error = '', delivered_at = NULL
+		RETURNING ` + deliveryColumns
+
+	return scanDelivery(database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		d.SubscriptionID,
+		d.EventID,
+		d.EventType,
+		d.BookingID,
+		[]byte(d.Payload),
+	), d)
+}
+
+func (r *postgresWebhookDeliveryRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = now() + $2 * interval '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+
+	return r.query(ctx, query, limit, lease.Milliseconds())
+}
+
+func (r *postgresWebhookDeliveryRepository) RecordAttempt(ctx context.Context, d *entity.WebhookDelivery, a *entity.WebhookAttempt) error {
+	// One statement keeps the attempt log and the delivery state in step
+	query := `
+		WITH updated AS (
+			UPDATE webhook_deliveries
+			SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5, delivered_at = $6
+			WHERE id = $1
+			RETURNING id
+		)
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, attempted_at)
+		SELECT id, $7, $8, $9, $10, $11 FROM updated
+		RETURNING id`
+
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		d.ID,
+		d.Status,
+		d.Attempts,
+		d.NextAttemptAt,
+		d.LastError,
+		d.DeliveredAt,
+		a.Attempt,
+		a.StatusCode,
+		a.Error,
+		a.DurationMS,
+		a.AttemptedAt,
+	).Scan(&a.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperrors.ErrWebhookDeliveryNotFound
+	}
+
+	return err
+}
+
+func (r *postgresWebhookDeliveryRepository) GetByID(ctx context.Context, id int64) (*entity.WebhookDelivery, error) {
+	tenant, args := subscriptionTenantFilter(ctx, []interface{}{id})
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = $1 AND ` + tenant
+
+	d := &entity.WebhookDelivery{}
+	err := scanDelivery(database.Conn(ctx, r.db).QueryRowContext(ctx, query, args...), d)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	attemptsQuery := `
+		SELECT id, delivery_id, attempt, status_code, error, duration_ms, attempted_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = $1
+		ORDER BY id`
+
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &d.AttemptLog, attemptsQuery, id); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (r *postgresWebhookDeliveryRepository) List(ctx context.Context, filter entity.WebhookDeliveryFilter, limit, offset int) ([]*entity.WebhookDelivery, error) {
+	tenant, args := subscriptionTenantFilter(ctx, []interface{}{limit, offset})
+	where, args := deliveryFilterClause(filter, args)
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE ` + tenant + ` AND ` + where + `
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2`
+
+	return r.query(ctx, query, args...)
+}
+
+func (r *postgresWebhookDeliveryRepository) Redeliver(ctx context.Context, id int64) error {
+	tenant, args := subscriptionTenantFilter(ctx, []interface{}{id})
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'PENDING', attempts = 0, next_attempt_at = now(), delivered_at = NULL
+		WHERE id = $1 AND ` + tenant
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	return expectAffected(result, apperrors.ErrWebhookDeliveryNotFound)
+}
+
+func (r *postgresWebhookDeliveryRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entity.WebhookDelivery, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*entity.WebhookDelivery
+	for rows.Next() {
+		d := &entity.WebhookDelivery{}
+		if err := scanDelivery(rows, d); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func scanDelivery(row rowScanner, d *entity.WebhookDelivery) error {
+	var payload []byte
+	err := row.Scan(
+		&d.ID,
+		&d.SubscriptionID,
+		&d.EventID,
+		&d.EventType,
+		&d.BookingID,
+		&payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastError,
+		&d.CreatedAt,
+		&d.DeliveredAt,
+	)
+	if err != nil {
+		return err
+	}
+	d.Payload = payload
+
+	return nil
+}
+
+// subscriptionTenantFilter scopes deliveries to the subscriptions of the
+// tenant in ctx, like tenantFilter.
+func subscriptionTenantFilter(ctx context.Context, args []interface{}) (string, []interface{}) {
+	tenant, args := tenantFilter(ctx, "tenant_id", args)
+	return `subscription_id IN (SELECT id FROM webhook_subscriptions WHERE ` + tenant + `)`, args
+}
+
+func deliveryFilterClause(filter entity.WebhookDeliveryFilter, args []interface{}) (string, []interface{}) {
+	var conds []string
+	add := func(cond string, value interface{}) {
+		args = append(args, value)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.SubscriptionID != 0 {
+		add("subscription_id = $%d", filter.SubscriptionID)
+	}
+	if filter.BookingID != 0 {
+		add("booking_id = $%d", filter.BookingID)
+	}
+	if filter.EventType != "" {
+		add("event_type = $%d", filter.EventType)
+	}
+	if filter.Status != "" {
+		add("status = $%d", filter.Status)
+	}
+
+	if len(conds) == 0 {
+		return "TRUE", args
+	}
+	return strings.Join(conds, " AND "), args
+}
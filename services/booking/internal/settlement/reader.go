@@ -0,0 +1,118 @@
+// Package settlement reads the settlement files payment providers send, one
+// settled payment per CSV line.
+package settlement
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+var requiredColumns = []string{"reference", "amount", "currency"}
+
+// Read returns the settlements of a file in line order. The header row
+// names the columns: reference, amount and currency are required, fee,
+// booking_id and settled_at (RFC 3339) optional. Amounts and fees are decimal
+// amounts in major units, e.g. 1250.50; the fee is part of the amount. A file is taken whole or not at all, so any bad line
+// fails it with the line number; a bad header fails it with
+// ErrInvalidSettlementFile.
+func Read(r io.Reader) ([]*entity.Settlement, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, apperrors.ErrInvalidSettlementFile
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, name := range requiredColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, apperrors.ErrInvalidSettlementFile
+		}
+	}
+
+	var settlements []*entity.Settlement
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return settlements, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		values := make(map[string]string, len(columns))
+		blank := true
+		for name, i := range columns {
+			if i < len(record) {
+				values[name] = strings.TrimSpace(record[i])
+				blank = blank && values[name] == ""
+			}
+		}
+		if blank {
+			continue
+		}
+
+		s, err := parseSettlement(values)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		s.Line = line
+		settlements = append(settlements, s)
+	}
+}
+
+func parseSettlement(values map[string]string) (*entity.Settlement, error) {
+	s := &entity.Settlement{Reference: values["reference"]}
+	if s.Reference == "" {
+		return nil, errors.New("reference is empty")
+	}
+
+	currency, err := money.ParseCurrency(values["currency"])
+	if err != nil {
+		return nil, err
+	}
+	if s.Amount, err = money.Parse(values["amount"], currency); err != nil {
+		return nil, err
+	}
+	s.Fee = money.Zero(currency)
+	if v := values["fee"]; v != "" {
+		if s.Fee, err = money.Parse(v, currency); err != nil {
+			return nil, err
+		}
+		if s.Fee.IsNegative() || s.Fee.Amount() > s.Amount.Amount() {
+			return nil, fmt.Errorf("fee %s exceeds amount %s", s.Fee, s.Amount)
+		}
+	}
+
+	if v := values["booking_id"]; v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid booking_id %q", v)
+		}
+		s.BookingID = &id
+	}
+	if v := values["settled_at"]; v != "" {
+		at, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid settled_at %q", v)
+		}
+		s.SettledAt = &at
+	}
+
+	return s, nil
+}
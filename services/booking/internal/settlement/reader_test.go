@@ -0,0 +1,63 @@
+package settlement
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+)
+
+func TestReadSettlements(t *testing.T) {
+	file := "\ufeffReference,Amount,Currency,Fee,Booking_ID,Settled_At\n" +
+		"pay_1,150000.50,idr,2500,42,2030-01-02T08:00:00+07:00\n" +
+		"\n" +
+		"pay_2,99,USD,,,\n"
+
+	settlements, err := Read(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if len(settlements) != 2 {
+		t.Fatalf("got %d settlements, want 2", len(settlements))
+	}
+
+	first := settlements[0]
+	if first.Line != 2 || first.Reference != "pay_1" || first.Amount != money.MustNew(15000050, "IDR") || first.Fee != money.MustNew(250000, "IDR") ||
+		first.BookingID == nil || *first.BookingID != 42 || first.SettledAt == nil {
+		t.Errorf("first settlement: %+v", first)
+	}
+	if second := settlements[1]; second.Line != 4 || second.Amount != money.MustNew(9900, "USD") || second.Fee != money.Zero("USD") ||
+		second.BookingID != nil || second.SettledAt != nil {
+		t.Errorf("second settlement: %+v", second)
+	}
+}
+
+func TestReadRejectsWholeFile(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		want string
+	}{
+		{"missing column", "reference,amount\npay_1,10\n", apperrors.ErrInvalidSettlementFile.Error()},
+		{"empty", "", apperrors.ErrInvalidSettlementFile.Error()},
+		{"bad amount", "reference,amount,currency\npay_1,10,IDR\npay_2,1.234,IDR\n", "line 3:"},
+		{"no reference", "reference,amount,currency\n,10,IDR\n", "line 2: reference is empty"},
+		{"fee over amount", "reference,amount,currency,fee\npay_1,10,IDR,11\n", "line 2: fee IDR 11.00 exceeds amount IDR 10.00"},
+		{"bad booking", "reference,amount,currency,booking_id\npay_1,10,IDR,x\n", `line 2: invalid booking_id "x"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settlements, err := Read(strings.NewReader(tt.file))
+			if err == nil || !strings.Contains(err.Error(), tt.want) || settlements != nil {
+				t.Errorf("got %v, %v; want error containing %q", settlements, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Read(strings.NewReader("reference\n")); !errors.Is(err, apperrors.ErrInvalidSettlementFile) {
+		t.Errorf("got %v, want ErrInvalidSettlementFile", err)
+	}
+}
@@ -5,25 +5,81 @@ import (
 	"errors"
 	"time"
 
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
 	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/policy"
 	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
 	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
 )
 
 type bookingUsecase struct {
-	bookingRepo repository.BookingRepository
+	bookingRepo   repository.BookingRepository
+	outboxRepo    repository.OutboxRepository
+	historyRepo   repository.BookingHistoryRepository
+	idemRepo      repository.IdempotencyRepository
+	promotionRepo repository.PromotionRepository
+	routeRepo     repository.RouteRepository
+	transactor    repository.Transactor
+	tenants       service.TenantService
+	cancelPolicy  *policy.CancellationPolicy
 }
 
-func NewBookingUsecase(bookingRepo repository.BookingRepository) service.BookingService {
+func NewBookingUsecase(
+	bookingRepo repository.BookingRepository,
+	outboxRepo repository.OutboxRepository,
+	historyRepo repository.BookingHistoryRepository,
+	idemRepo repository.IdempotencyRepository,
+	promotionRepo repository.PromotionRepository,
+	routeRepo repository.RouteRepository,
+	transactor repository.Transactor,
+	tenants service.TenantService,
+	cancelPolicy *policy.CancellationPolicy,
+) service.BookingService {
 	return &bookingUsecase{
-		bookingRepo: bookingRepo,
+		bookingRepo:   bookingRepo,
+		outboxRepo:    outboxRepo,
+		historyRepo:   historyRepo,
+		idemRepo:      idemRepo,
+		promotionRepo: promotionRepo,
+		routeRepo:     routeRepo,
+		transactor:    transactor,
+		tenants:       tenants,
+		cancelPolicy:  cancelPolicy,
 	}
 }
 
 func (uc *bookingUsecase) CreateBooking(ctx context.Context, booking *entity.Booking) error {
 	// Business logic validation
+	if err := preparePassengers(booking); err != nil {
+		return err
+	}
 	if booking.Qty <= 0 {
-		return errors.New("quantity must be greater than 0")
+		return apperrors.ErrInvalidQuantity
+	}
+	if booking.DepartureAt != nil && booking.DepartureAt.Before(time.Now()) {
+		return apperrors.ErrDepartureInPast
+	}
+	if err := validatePrice(booking); err != nil {
+		return err
+	}
+	// The requested price is before any discount, which is ours to work out
+	booking.PromoCode = entity.NormalizePromoCode(booking.PromoCode)
+	booking.Discount = nil
+	listPrice := booking.PriceTotal
+	booking.TenantID = tenantOf(ctx)
+
+	// A retried request returns the booking created by the first attempt.
+	// Keys of other tenants than the default one, which predates tenants,
+	// are kept apart.
+	key := reqctx.IdempotencyKey(ctx)
+	if key != "" && booking.TenantID != entity.DefaultTenantID {
+		key = booking.TenantID + ":" + key
+	}
+	if key != "" {
+		if replayed, err := uc.replay(ctx, key, booking); replayed || err != nil {
+			return err
+		}
 	}
 
 	// Set default values
@@ -31,7 +87,111 @@ func (uc *bookingUsecase) CreateBooking(ctx context.Context, booking *entity.Boo
 	booking.CreatedAt = time.Now()
 	booking.UpdatedAt = time.Now()
 
-	return uc.bookingRepo.Create(ctx, booking)
+	err := uc.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := uc.routeRepo.Claim(ctx, booking.RouteID, booking.TenantID); err != nil {
+			return err
+		}
+
+		var redemption *entity.PromotionRedemption
+		if booking.PromoCode != "" {
+			var err error
+			if redemption, err = uc.applyPromotion(ctx, booking); err != nil {
+				return err
+			}
+		}
+
+		if err := uc.bookingRepo.Create(ctx, booking); err != nil {
+			return err
+		}
+		if redemption != nil {
+			redemption.BookingID = booking.ID
+			if err := uc.promotionRepo.Redeem(ctx, redemption); err != nil {
+				return err
+			}
+		}
+		if key != "" {
+			if err := uc.idemRepo.Save(ctx, key, booking.ID); err != nil {
+				return err
+			}
+		}
+		if err := uc.record(ctx, entity.HistoryActionCreated, nil, booking); err != nil {
+			return err
+		}
+		return uc.emit(ctx, booking.ID, entity.EventBookingCreated, booking)
+	})
+
+	// A concurrent attempt with the same key won the race
+	if errors.Is(err, apperrors.ErrIdempotencyKeyExists) {
+		_, err = uc.replay(ctx, key, booking)
+	} else if err != nil {
+		booking.PriceTotal, booking.Discount = listPrice, nil
+	}
+
+	return err
+}
+
+// applyPromotion takes the discount of the booking's promo code off its
+// price. The promotion stays locked until the booking's transaction ends, so
+// concurrent bookings cannot both take its last redemption.
+func (uc *bookingUsecase) applyPromotion(ctx context.Context, booking *entity.Booking) (*entity.PromotionRedemption, error) {
+	promotion, err := uc.promotionRepo.GetByCode(ctx, booking.TenantID, booking.PromoCode, repository.ForUpdate())
+	if errors.Is(err, apperrors.ErrPromotionNotFound) {
+		return nil, apperrors.ErrInvalidPromoCode
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !promotion.AppliesTo(booking, booking.CreatedAt) {
+		return nil, apperrors.ErrInvalidPromoCode
+	}
+
+	if promotion.MaxRedemptions != nil && promotion.Redemptions >= *promotion.MaxRedemptions {
+		return nil, apperrors.ErrPromoCodeExhausted
+	}
+	if promotion.MaxPerUser != nil {
+		n, err := uc.promotionRepo.CountRedemptions(ctx, promotion.ID, booking.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if n >= *promotion.MaxPerUser {
+			return nil, apperrors.ErrPromoCodeExhausted
+		}
+	}
+
+	discount, err := promotion.DiscountFor(booking.PriceTotal)
+	if err != nil {
+		return nil, err
+	}
+	if booking.PriceTotal, err = booking.PriceTotal.Sub(discount); err != nil {
+		return nil, err
+	}
+	booking.Discount = &discount
+
+	return &entity.PromotionRedemption{
+		PromotionID: promotion.ID,
+		UserID:      booking.UserID,
+		Discount:    discount,
+		CreatedAt:   booking.CreatedAt,
+	}, nil
+}
+
+// replay loads the booking previously created under key into booking.
+func (uc *bookingUsecase) replay(ctx context.Context, key string, booking *entity.Booking) (bool, error) {
+	bookingID, err := uc.idemRepo.GetBookingID(ctx, key)
+	if errors.Is(err, apperrors.ErrBookingNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := uc.bookingRepo.GetByID(ctx, bookingID, repository.IncludeDeleted())
+	if err != nil {
+		return false, err
+	}
+
+	*booking = *existing
+	return true, nil
 }
 
 func (uc *bookingUsecase) GetBooking(ctx context.Context, id int64) (*entity.Booking, error) {
@@ -39,38 +199,244 @@ func (uc *bookingUsecase) GetBooking(ctx context.Context, id int64) (*entity.Boo
 }
 
 func (uc *bookingUsecase) UpdateBooking(ctx context.Context, booking *entity.Booking) error {
-	// Business logic validation
-	existingBooking, err := uc.bookingRepo.GetByID(ctx, booking.ID)
+	return uc.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		// Business logic validation
+		existingBooking, err := uc.bookingRepo.GetByID(ctx, booking.ID, repository.ForUpdate())
+		if err != nil {
+			return err
+		}
+		if err := checkStatusChange(existingBooking, booking); err != nil {
+			return err
+		}
+		// Passengers are added at creation and leave by cancellation only
+		if len(existingBooking.Passengers) > 0 && booking.Qty != existingBooking.Qty {
+			return apperrors.ErrPassengerCountLocked
+		}
+		booking.Passengers = existingBooking.Passengers
+
+		if err := validatePrice(booking); err != nil {
+			return err
+		}
+		// Amounts recorded for this booking are all in its original currency
+		if !booking.PriceTotal.SameCurrency(existingBooking.PriceTotal) {
+			return apperrors.ErrCurrencyChanged
+		}
+
+		// Update timestamp
+		booking.UpdatedAt = time.Now()
+		booking.CreatedAt = existingBooking.CreatedAt // Preserve original creation time
+
+		// Cancellation and deletion are managed by their own operations
+		booking.CancelReason = existingBooking.CancelReason
+		booking.CancelledAt = existingBooking.CancelledAt
+		booking.DeletedAt = existingBooking.DeletedAt
+		// The discount stays as redeemed at creation
+		booking.PromoCode = existingBooking.PromoCode
+		booking.Discount = existingBooking.Discount
+		booking.TenantID = existingBooking.TenantID
+		if booking.RouteID != existingBooking.RouteID {
+			if err := uc.routeRepo.Claim(ctx, booking.RouteID, booking.TenantID); err != nil {
+				return err
+			}
+		}
+
+		if err := uc.bookingRepo.Update(ctx, booking); err != nil {
+			return err
+		}
+		if err := uc.record(ctx, entity.HistoryActionUpdated, existingBooking, booking); err != nil {
+			return err
+		}
+		if booking.Status == existingBooking.Status {
+			return nil
+		}
+		return uc.emit(ctx, booking.ID, entity.StatusEvents[booking.Status], &entity.StatusChange{
+			BookingID: booking.ID,
+			UserID:    booking.UserID,
+			RouteID:   booking.RouteID,
+			OldStatus: existingBooking.Status,
+			NewStatus: booking.Status,
+			ChangedAt: booking.UpdatedAt,
+		})
+	})
+}
+
+func (uc *bookingUsecase) CancelBooking(ctx context.Context, id int64, reason entity.CancellationReason) (*entity.Cancellation, error) {
+	if reason == "" {
+		reason = entity.CancelReasonCustomerRequest
+	}
+	if !reason.Valid() {
+		return nil, apperrors.ErrInvalidCancelReason
+	}
+
+	var cancellation *entity.Cancellation
+	err := uc.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		booking, err := uc.bookingRepo.GetByID(ctx, id, repository.ForUpdate())
+		if err != nil {
+			return err
+		}
+
+		cancelPolicy, err := uc.policyFor(ctx, booking)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		decision, err := cancelPolicy.Evaluate(booking, now)
+		if err != nil {
+			return err
+		}
+
+		before := *booking
+		wasPaid := booking.Status != entity.StatusCreated
+		booking.Status = entity.StatusCancelled
+		booking.CancelReason = reason
+		booking.CancelledAt = &now
+		booking.UpdatedAt = now
+
+		if err := uc.bookingRepo.Update(ctx, booking); err != nil {
+			return err
+		}
+		if err := uc.record(ctx, entity.HistoryActionCancelled, &before, booking); err != nil {
+			return err
+		}
+
+		cancellation = &entity.Cancellation{
+			BookingID:     booking.ID,
+			Reason:        reason,
+			RefundPercent: decision.RefundPercent,
+			RefundAmount:  decision.RefundAmount,
+			CancelledAt:   now,
+		}
+		if err := uc.emit(ctx, booking.ID, entity.EventBookingCancelled, cancellation); err != nil {
+			return err
+		}
+
+		// Money only goes back for bookings that were actually paid.
+		if wasPaid && decision.RefundAmount.Amount() > 0 {
+			refund := &entity.RefundRequest{
+				BookingID:   booking.ID,
+				UserID:      booking.UserID,
+				Amount:      decision.RefundAmount,
+				Percent:     decision.RefundPercent,
+				Reason:      reason,
+				RequestedAt: now,
+			}
+			if err := uc.emit(ctx, booking.ID, entity.EventRefundRequested, refund); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Update timestamp
-	booking.UpdatedAt = time.Now()
-	booking.CreatedAt = existingBooking.CreatedAt // Preserve original creation time
+	return cancellation, nil
+}
+
+// checkStatusChange keeps updates from bypassing the status rules. An empty
+// status keeps the current one; cancellation has its own operation.
+func checkStatusChange(existing, booking *entity.Booking) error {
+	if booking.Status == "" || booking.Status == existing.Status {
+		booking.Status = existing.Status
+		return nil
+	}
+	if !booking.Status.Valid() {
+		return apperrors.ErrInvalidStatus
+	}
+	if booking.Status == entity.StatusCancelled || !entity.CanTransition(existing.Status, booking.Status) {
+		return apperrors.ErrInvalidStatusTransition
+	}
+	return nil
+}
+
+func validatePrice(booking *entity.Booking) error {
+	if !booking.PriceTotal.Currency().Valid() || booking.PriceTotal.IsNegative() {
+		return apperrors.ErrInvalidPrice
+	}
+	return nil
+}
+
+// policyFor returns the cancellation policy of the booking's tenant.
+func (uc *bookingUsecase) policyFor(ctx context.Context, booking *entity.Booking) (*policy.CancellationPolicy, error) {
+	tenant, err := uc.tenants.GetTenant(ctx, booking.TenantID)
+	if err != nil {
+		return nil, err
+	}
 
-	return uc.bookingRepo.Update(ctx, booking)
+	settings := tenant.Settings
+	if settings.AllowCancelHours == nil && len(settings.RefundTiers) == 0 {
+		return uc.cancelPolicy, nil
+	}
+	allowCancelHours, tiers := uc.cancelPolicy.AllowCancelHours, uc.cancelPolicy.Tiers
+	if settings.AllowCancelHours != nil {
+		allowCancelHours = *settings.AllowCancelHours
+	}
+	if len(settings.RefundTiers) > 0 {
+		tiers = settings.RefundTiers
+	}
+
+	return policy.NewCancellationPolicy(allowCancelHours, tiers), nil
 }
 
-func (uc *bookingUsecase) CancelBooking(ctx context.Context, id int64) error {
-	booking, err := uc.bookingRepo.GetByID(ctx, id)
+func (uc *bookingUsecase) GetBookingHistory(ctx context.Context, id int64) ([]*entity.BookingHistoryEntry, error) {
+	// History stays readable for soft-deleted bookings
+	if _, err := uc.bookingRepo.GetByID(ctx, id, repository.IncludeDeleted()); err != nil {
+		return nil, err
+	}
+
+	return uc.historyRepo.ListByBookingID(ctx, id)
+}
+
+// record appends an audit entry for a mutation. It must be called with the
+// transaction context of the change so both commit or roll back together.
+func (uc *bookingUsecase) record(ctx context.Context, action string, before, after *entity.Booking) error {
+	changes, err := entity.DiffBookings(before, after)
 	if err != nil {
 		return err
 	}
 
-	// Business logic: can only cancel if not already confirmed
-	if booking.Status == entity.StatusConfirmed {
-		return errors.New("cannot cancel confirmed booking")
+	return uc.recordChanges(ctx, action, before, after, changes)
+}
+
+func (uc *bookingUsecase) recordChanges(ctx context.Context, action string, before, after *entity.Booking, changes map[string]entity.FieldChange) error {
+	entry := &entity.BookingHistoryEntry{
+		BookingID: after.ID,
+		Action:    action,
+		NewStatus: after.Status,
+		Changes:   changes,
+		Actor:     reqctx.Actor(ctx),
+		RequestID: reqctx.RequestID(ctx),
+		CreatedAt: time.Now(),
+	}
+	if before != nil {
+		entry.OldStatus = before.Status
+	}
+
+	return uc.historyRepo.Append(ctx, entry)
+}
+
+func (uc *bookingUsecase) emit(ctx context.Context, bookingID int64, eventType string, payload interface{}) error {
+	event, err := entity.NewBookingEvent(bookingID, eventType, payload)
+	if err != nil {
+		return err
 	}
 
-	booking.Status = entity.StatusExpired
-	booking.UpdatedAt = time.Now()
+	return uc.outboxRepo.Append(ctx, event)
+}
 
-	return uc.bookingRepo.Update(ctx, booking)
+func (uc *bookingUsecase) ListBookings(ctx context.Context, filter entity.BookingFilter, limit, offset int) ([]*entity.Booking, error) {
+	if filter.Status != "" && !filter.Status.Valid() {
+		return nil, apperrors.ErrInvalidStatus
+	}
+
+	limit, offset = clampPage(limit, offset)
+	return uc.bookingRepo.List(ctx, filter, limit, offset)
 }
 
-func (uc *bookingUsecase) ListBookings(ctx context.Context, limit, offset int) ([]*entity.Booking, error) {
-	// Validate pagination parameters
+// clampPage applies the default page size of 10, caps it at 100 and keeps
+// offset non-negative.
+func clampPage(limit, offset int) (int, int) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -80,6 +446,5 @@ func (uc *bookingUsecase) ListBookings(ctx context.Context, limit, offset int) (
 	if offset < 0 {
 		offset = 0
 	}
-
-	return uc.bookingRepo.List(ctx, limit, offset)
+	return limit, offset
 }
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type exportUsecase struct {
+	exportRepo repository.ExportRepository
+}
+
+func NewExportUsecase(exportRepo repository.ExportRepository) service.ExportService {
+	return &exportUsecase{
+		exportRepo: exportRepo,
+	}
+}
+
+func (uc *exportUsecase) ExportBookings(ctx context.Context, filter entity.BookingFilter, fn func(*entity.Booking) error) error {
+	if filter.Status != "" && !filter.Status.Valid() {
+		return apperrors.ErrInvalidStatus
+	}
+
+	return uc.exportRepo.Stream(ctx, filter, fn)
+}
@@ -0,0 +1,205 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/ledger"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+// fakeBookingRepo keeps bookings in memory and hands out copies, so use
+// cases only change what they store.
+type fakeBookingRepo struct {
+	repository.BookingRepository
+
+	mu       sync.Mutex
+	nextID   int64
+	bookings map[int64]*entity.Booking
+}
+
+func newFakeBookingRepo(bookings ...*entity.Booking) *fakeBookingRepo {
+	r := &fakeBookingRepo{bookings: make(map[int64]*entity.Booking)}
+	for _, b := range bookings {
+		if err := r.Create(context.Background(), b); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+func (r *fakeBookingRepo) Create(ctx context.Context, booking *entity.Booking) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextID++
+	booking.ID = r.nextID
+	for i, p := range booking.Passengers {
+		p.ID = int64(i + 1)
+		p.BookingID = booking.ID
+	}
+	r.bookings[booking.ID] = copyBooking(booking)
+	return nil
+}
+
+func (r *fakeBookingRepo) GetByID(ctx context.Context, id int64, opts ...repository.QueryOption) (*entity.Booking, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	booking, ok := r.bookings[id]
+	if !ok {
+		return nil, apperrors.ErrBookingNotFound
+	}
+	return copyBooking(booking), nil
+}
+
+func (r *fakeBookingRepo) Update(ctx context.Context, booking *entity.Booking) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.bookings[booking.ID]
+	if !ok {
+		return apperrors.ErrBookingNotFound
+	}
+	updated := copyBooking(booking)
+	updated.Passengers = stored.Passengers
+	r.bookings[booking.ID] = updated
+	return nil
+}
+
+func (r *fakeBookingRepo) CancelPassenger(ctx context.Context, passenger *entity.Passenger) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	booking, ok := r.bookings[passenger.BookingID]
+	if !ok {
+		return apperrors.ErrPassengerNotFound
+	}
+	for i, p := range booking.Passengers {
+		if p.ID == passenger.ID && p.Status == entity.PassengerActive {
+			c := *passenger
+			booking.Passengers[i] = &c
+			return nil
+		}
+	}
+	return apperrors.ErrPassengerNotFound
+}
+
+func copyBooking(booking *entity.Booking) *entity.Booking {
+	c := *booking
+	c.Passengers = make([]*entity.Passenger, len(booking.Passengers))
+	for i, p := range booking.Passengers {
+		pc := *p
+		c.Passengers[i] = &pc
+	}
+	return &c
+}
+
+// fakeOutbox records the events appended to it.
+type fakeOutbox struct {
+	repository.OutboxRepository
+
+	events []*entity.OutboxEvent
+}
+
+func (o *fakeOutbox) Append(ctx context.Context, event *entity.OutboxEvent) error {
+	o.events = append(o.events, event)
+	return nil
+}
+
+func (o *fakeOutbox) types() []string {
+	types := make([]string, len(o.events))
+	for i, event := range o.events {
+		types[i] = event.EventType
+	}
+	return types
+}
+
+type fakeHistory struct {
+	repository.BookingHistoryRepository
+
+	entries []*entity.BookingHistoryEntry
+}
+
+func (h *fakeHistory) Append(ctx context.Context, entry *entity.BookingHistoryEntry) error {
+	h.entries = append(h.entries, entry)
+	return nil
+}
+
+// fakeTransactor runs fn directly; the fakes have nothing to roll back.
+type fakeTransactor struct{}
+
+func (fakeTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (fakeTransactor) WithinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakeTenants knows every tenant, with default settings.
+type fakeTenants struct {
+	service.TenantService
+}
+
+func (fakeTenants) GetTenant(ctx context.Context, id string) (*entity.Tenant, error) {
+	return &entity.Tenant{ID: id, Name: id}, nil
+}
+
+// fakeLedger keeps posted entries in memory and balances them like
+// ledger.Store.
+type fakeLedger struct {
+	repository.LedgerRepository
+
+	entries []*ledger.Entry
+}
+
+func (l *fakeLedger) Open(ctx context.Context, accounts ...ledger.Account) error {
+	return nil
+}
+
+func (l *fakeLedger) Post(ctx context.Context, entry *ledger.Entry) error {
+	if err := entry.Validate(); err != nil {
+		return err
+	}
+	for _, e := range l.entries {
+		if e.Key == entry.Key {
+			return ledger.ErrDuplicateEntry
+		}
+	}
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+// Balances handles liability accounts only, which is all the use cases ask
+// about.
+func (l *fakeLedger) Balances(ctx context.Context, filter ledger.Filter) ([]*ledger.Balance, error) {
+	byCurrency := map[money.Currency]*ledger.Balance{}
+	for _, e := range l.entries {
+		if filter.BookingID != 0 && e.BookingID != filter.BookingID {
+			continue
+		}
+		for _, p := range e.Postings {
+			if p.Account != filter.Account {
+				continue
+			}
+			b, ok := byCurrency[p.Amount.Currency()]
+			if !ok {
+				b = &ledger.Balance{Account: p.Account, Type: ledger.Liability, Balance: money.Zero(p.Amount.Currency())}
+				byCurrency[p.Amount.Currency()] = b
+			}
+			credit, _ := p.Amount.Negate()
+			b.Balance, _ = b.Balance.Add(credit)
+		}
+	}
+
+	balances := []*ledger.Balance{}
+	for _, b := range byCurrency {
+		balances = append(balances, b)
+	}
+	return balances, nil
+}
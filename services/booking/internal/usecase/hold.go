@@ -0,0 +1,196 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type holdUsecase struct {
+	bookingService service.BookingService
+	bookingRepo    repository.BookingRepository
+	holdStore      repository.HoldStore
+	tenants        service.TenantService
+	ttl            time.Duration
+	extension      time.Duration
+}
+
+func NewHoldUsecase(
+	bookingService service.BookingService,
+	bookingRepo repository.BookingRepository,
+	holdStore repository.HoldStore,
+	tenants service.TenantService,
+	ttl, extension time.Duration,
+) service.HoldService {
+	return &holdUsecase{
+		bookingService: bookingService,
+		bookingRepo:    bookingRepo,
+		holdStore:      holdStore,
+		tenants:        tenants,
+		ttl:            ttl,
+		extension:      extension,
+	}
+}
+
+func (uc *holdUsecase) CreateHold(ctx context.Context, hold *entity.Hold) error {
+	if err := validateSeats(hold); err != nil {
+		return err
+	}
+	// Hold the same terms CreateBooking will check on conversion
+	booking := hold.Booking()
+	if booking.Qty <= 0 {
+		return apperrors.ErrInvalidQuantity
+	}
+	if booking.DepartureAt != nil && booking.DepartureAt.Before(time.Now()) {
+		return apperrors.ErrDepartureInPast
+	}
+	if err := validatePrice(booking); err != nil {
+		return err
+	}
+
+	tenant, err := uc.tenants.GetTenant(ctx, tenantOf(ctx))
+	if err != nil {
+		return err
+	}
+
+	// Booked seats are not held; a booking racing this hold still loses on
+	// the booked seats constraint when the hold converts.
+	if len(hold.Seats) > 0 {
+		booked, err := uc.bookingRepo.BookedSeats(ctx, hold.RouteID, hold.DepartureAt, hold.Seats)
+		if err != nil {
+			return err
+		}
+		if len(booked) > 0 {
+			return apperrors.ErrSeatUnavailable
+		}
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	hold.ID = hex.EncodeToString(id)
+	hold.Extended = false
+	hold.CreatedAt = time.Now()
+	hold.ExpiresAt = hold.CreatedAt.Add(tenant.Settings.HoldTTL(uc.ttl))
+
+	return uc.holdStore.Create(ctx, hold)
+}
+
+// validateSeats trims the seat labels and checks they are unique. Without
+// explicit qty the seat count is used; with both they have to agree.
+func validateSeats(hold *entity.Hold) error {
+	seen := make(map[string]bool, len(hold.Seats))
+	for i, seat := range hold.Seats {
+		seat = strings.TrimSpace(seat)
+		if seat == "" || seen[seat] {
+			return apperrors.ErrInvalidSeats
+		}
+		seen[seat] = true
+		hold.Seats[i] = seat
+	}
+
+	if len(hold.Seats) > 0 {
+		if hold.Qty == 0 {
+			hold.Qty = len(hold.Seats)
+		}
+		if hold.Qty != len(hold.Seats) {
+			return apperrors.ErrInvalidSeats
+		}
+	}
+
+	return nil
+}
+
+func (uc *holdUsecase) GetHold(ctx context.Context, id string) (*entity.Hold, error) {
+	return uc.holdStore.Get(ctx, id)
+}
+
+func (uc *holdUsecase) ExtendHold(ctx context.Context, id string) (*entity.Hold, error) {
+	hold, err := uc.holdStore.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if hold.Extended {
+		return nil, apperrors.ErrHoldAlreadyExtended
+	}
+
+	return uc.holdStore.Extend(ctx, id, hold.ExpiresAt.Add(uc.extension))
+}
+
+func (uc *holdUsecase) ConvertHold(ctx context.Context, id string, passengers []*entity.Passenger) (*entity.Booking, error) {
+	hold, err := uc.holdStore.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := seatPassengers(hold, passengers); err != nil {
+		return nil, err
+	}
+
+	// Keyed by hold, so a retry after a failed release replays the booking
+	// instead of creating a second one.
+	booking := hold.Booking()
+	booking.Passengers = passengers
+	if err := uc.bookingService.CreateBooking(reqctx.WithIdempotencyKey(ctx, "hold:"+hold.ID), booking); err != nil {
+		return nil, err
+	}
+
+	// The booking stands either way; an expired hold has released itself
+	if err := uc.holdStore.Release(ctx, hold.ID); err != nil && !errors.Is(err, apperrors.ErrHoldNotFound) {
+		return booking, err
+	}
+
+	return booking, nil
+}
+
+// seatPassengers checks passengers only pick seats the hold reserved and
+// gives the remaining held seats, in order, to passengers without one.
+func seatPassengers(hold *entity.Hold, passengers []*entity.Passenger) error {
+	if len(hold.Seats) == 0 {
+		return nil
+	}
+
+	free := make(map[string]bool, len(hold.Seats))
+	for _, seat := range hold.Seats {
+		free[seat] = true
+	}
+	for _, p := range passengers {
+		if p == nil || p.Seat == "" {
+			continue
+		}
+		if !free[p.Seat] {
+			return apperrors.ErrInvalidSeats
+		}
+		free[p.Seat] = false
+	}
+
+	next := 0
+	for _, p := range passengers {
+		if p == nil || p.Seat != "" {
+			continue
+		}
+		for next < len(hold.Seats) && !free[hold.Seats[next]] {
+			next++
+		}
+		if next == len(hold.Seats) {
+			break
+		}
+		p.Seat = hold.Seats[next]
+		free[p.Seat] = false
+	}
+
+	return nil
+}
+
+func (uc *holdUsecase) ReleaseHold(ctx context.Context, id string) error {
+	return uc.holdStore.Release(ctx, id)
+}
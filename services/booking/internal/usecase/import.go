@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+// errImportFailed rolls back an atomic import after a row failed.
+var errImportFailed = errors.New("import row failed")
+
+type importUsecase struct {
+	bookingService service.BookingService
+	transactor     repository.Transactor
+	maxRows        int
+}
+
+// NewImportUsecase creates bookings through bookingService, so imported rows
+// get the same validation, history and events as API calls. Imports hold at
+// most maxRows bookings.
+func NewImportUsecase(bookingService service.BookingService, transactor repository.Transactor, maxRows int) service.ImportService {
+	return &importUsecase{
+		bookingService: bookingService,
+		transactor:     transactor,
+		maxRows:        maxRows,
+	}
+}
+
+func (uc *importUsecase) ImportBookings(ctx context.Context, rows []*entity.ImportRow, mode entity.ImportMode) (*entity.ImportResult, error) {
+	if !mode.Valid() {
+		return nil, apperrors.ErrInvalidImportMode
+	}
+	if len(rows) == 0 {
+		return nil, apperrors.ErrInvalidImport
+	}
+	if len(rows) > uc.maxRows {
+		return nil, apperrors.ErrImportTooLarge
+	}
+
+	result := &entity.ImportResult{Mode: mode, Total: len(rows)}
+	if mode == entity.ImportBestEffort {
+		for i, row := range rows {
+			result.Rows = append(result.Rows, uc.importRow(ctx, i, row, uc.bookingService.CreateBooking))
+		}
+		return tally(result), nil
+	}
+
+	// Each row gets a savepoint so one failure does not hide the outcome of
+	// the rows after it
+	createInSavepoint := func(ctx context.Context, booking *entity.Booking) error {
+		return uc.transactor.WithinSavepoint(ctx, func(ctx context.Context) error {
+			return uc.bookingService.CreateBooking(ctx, booking)
+		})
+	}
+	err := uc.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		failed := false
+		for i, row := range rows {
+			rowResult := uc.importRow(ctx, i, row, createInSavepoint)
+			failed = failed || rowResult.Status == entity.ImportRowFailed
+			result.Rows = append(result.Rows, rowResult)
+		}
+		if failed {
+			return errImportFailed
+		}
+		return nil
+	})
+	if errors.Is(err, errImportFailed) {
+		for _, rowResult := range result.Rows {
+			if rowResult.Status == entity.ImportRowCreated {
+				rowResult.Status = entity.ImportRowRolledBack
+				rowResult.BookingID = nil
+			}
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return tally(result), nil
+}
+
+// importRow creates the booking of row i. A client idempotency key is
+// narrowed to the row, so a retried import replays the rows that succeeded.
+func (uc *importUsecase) importRow(ctx context.Context, i int, row *entity.ImportRow, create func(context.Context, *entity.Booking) error) *entity.ImportRowResult {
+	rowResult := &entity.ImportRowResult{Row: i + 1, Ref: row.Ref, Lines: row.Lines}
+
+	err := row.Err
+	if err == nil {
+		if key := reqctx.IdempotencyKey(ctx); key != "" {
+			ctx = reqctx.WithIdempotencyKey(ctx, key+"#"+strconv.Itoa(i+1))
+		}
+		err = create(ctx, row.Booking)
+	}
+	if err != nil {
+		rowResult.Status = entity.ImportRowFailed
+		rowResult.Error = err.Error()
+		return rowResult
+	}
+
+	rowResult.Status = entity.ImportRowCreated
+	rowResult.BookingID = &row.Booking.ID
+	return rowResult
+}
+
+func tally(result *entity.ImportResult) *entity.ImportResult {
+	for _, rowResult := range result.Rows {
+		switch rowResult.Status {
+		case entity.ImportRowCreated:
+			result.Created++
+		case entity.ImportRowFailed:
+			result.Failed++
+		}
+	}
+	result.Committed = result.Created > 0
+	return result
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/ledger"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+// Chart of accounts. Each payment provider has its own clearing account
+// holding what it collected and has not paid out yet.
+var (
+	accountBank           = ledger.Account{Code: "assets:bank", Type: ledger.Asset, Name: "Bank"}
+	accountTicketRevenue  = ledger.Account{Code: "revenue:tickets", Type: ledger.Revenue, Name: "Ticket sales"}
+	accountRefundsPayable = ledger.Account{Code: "liabilities:refunds_payable", Type: ledger.Liability, Name: "Refunds owed to customers"}
+	accountProviderFees   = ledger.Account{Code: "expenses:provider_fees", Type: ledger.Expense, Name: "Payment provider fees"}
+
+	// Contra-revenue for refunds that providers paid without a cancellation
+	// owing them, such as disputes or refunds made from their dashboards
+	accountRefunds = ledger.Account{Code: "revenue:refunds", Type: ledger.Revenue, Name: "Refunds not owed by a cancellation"}
+)
+
+func providerAccount(provider string) ledger.Account {
+	return ledger.Account{Code: "assets:provider:" + provider, Type: ledger.Asset, Name: "Held by " + provider}
+}
+
+// Entry kinds
+const (
+	ledgerPayment    = "payment"
+	ledgerRefundOwed = "refund_owed"
+	ledgerRefundPaid = "refund_paid"
+	ledgerSettlement = "settlement"
+)
+
+type ledgerUsecase struct {
+	ledgerRepo repository.LedgerRepository
+}
+
+func NewLedgerUsecase(ledgerRepo repository.LedgerRepository) service.LedgerService {
+	return &ledgerUsecase{
+		ledgerRepo: ledgerRepo,
+	}
+}
+
+// Publish books the refund a cancellation owes. Paid bookings cancelled as
+// a whole or by passenger hand back part of their revenue.
+func (uc *ledgerUsecase) Publish(ctx context.Context, event *entity.OutboxEvent) error {
+	if event.EventType != entity.EventRefundRequested {
+		return nil
+	}
+
+	var refund entity.RefundRequest
+	if err := json.Unmarshal(event.Payload, &refund); err != nil {
+		return err
+	}
+
+	return uc.post(ctx, &ledger.Entry{
+		Key:         fmt.Sprintf("outbox:%d", event.ID),
+		Kind:        ledgerRefundOwed,
+		BookingID:   refund.BookingID,
+		Description: fmt.Sprintf("%d%% refund, %s", refund.Percent, refund.Reason),
+		OccurredAt:  refund.RequestedAt,
+		Postings: []ledger.Posting{
+			ledger.Debit(accountTicketRevenue.Code, refund.Amount),
+			ledger.Credit(accountRefundsPayable.Code, refund.Amount),
+		},
+	}, accountTicketRevenue, accountRefundsPayable)
+}
+
+func (uc *ledgerUsecase) RecordPayment(ctx context.Context, event *entity.PaymentEvent) error {
+	provider := providerAccount(event.Provider)
+	return uc.post(ctx, &ledger.Entry{
+		Key:         paymentKey(event),
+		Kind:        ledgerPayment,
+		BookingID:   event.BookingID,
+		Description: "payment " + event.EventID,
+		OccurredAt:  event.OccurredAt,
+		Postings: []ledger.Posting{
+			ledger.Debit(provider.Code, event.Amount),
+			ledger.Credit(accountTicketRevenue.Code, event.Amount),
+		},
+	}, provider, accountTicketRevenue)
+}
+
+// RecordRefund settles what the booking's cancellations owe and books any
+// refund beyond that against revenue.
+func (uc *ledgerUsecase) RecordRefund(ctx context.Context, event *entity.PaymentEvent) error {
+	owed, err := uc.refundsOwed(ctx, event.BookingID, event.Amount.Currency())
+	if err != nil {
+		return err
+	}
+	if cmp, err := owed.Cmp(event.Amount); err != nil {
+		return err
+	} else if cmp > 0 {
+		owed = event.Amount
+	}
+	unowed, err := event.Amount.Sub(owed)
+	if err != nil {
+		return err
+	}
+
+	provider := providerAccount(event.Provider)
+	postings := []ledger.Posting{ledger.Credit(provider.Code, event.Amount)}
+	if !owed.IsZero() {
+		postings = append(postings, ledger.Debit(accountRefundsPayable.Code, owed))
+	}
+	if !unowed.IsZero() {
+		postings = append(postings, ledger.Debit(accountRefunds.Code, unowed))
+	}
+
+	return uc.post(ctx, &ledger.Entry{
+		Key:         paymentKey(event),
+		Kind:        ledgerRefundPaid,
+		BookingID:   event.BookingID,
+		Description: "refund " + event.EventID,
+		OccurredAt:  event.OccurredAt,
+		Postings:    postings,
+	}, provider, accountRefundsPayable, accountRefunds)
+}
+
+// refundsOwed returns what the booking's cancellations owe its customer and
+// no refund has paid yet.
+func (uc *ledgerUsecase) refundsOwed(ctx context.Context, bookingID int64, currency money.Currency) (money.Money, error) {
+	balances, err := uc.ledgerRepo.Balances(ctx, ledger.Filter{BookingID: bookingID, Account: accountRefundsPayable.Code})
+	if err != nil {
+		return money.Money{}, err
+	}
+	for _, b := range balances {
+		if b.Balance.Currency() == currency && !b.Balance.IsNegative() {
+			return b.Balance, nil
+		}
+	}
+	return money.Zero(currency), nil
+}
+
+func (uc *ledgerUsecase) RecordSettlement(ctx context.Context, settlement *entity.Settlement) error {
+	provider := providerAccount(settlement.Provider)
+	net, err := settlement.Amount.Sub(settlement.Fee)
+	if err != nil {
+		return err
+	}
+
+	// A payout entirely eaten by fees moves nothing to the bank
+	postings := []ledger.Posting{ledger.Credit(provider.Code, settlement.Amount)}
+	if !net.IsZero() {
+		postings = append(postings, ledger.Debit(accountBank.Code, net))
+	}
+	if !settlement.Fee.IsZero() {
+		postings = append(postings, ledger.Debit(accountProviderFees.Code, settlement.Fee))
+	}
+
+	entry := &ledger.Entry{
+		Key:         fmt.Sprintf("settlement:%d", settlement.ID),
+		Kind:        ledgerSettlement,
+		Description: "settlement " + settlement.Reference,
+		Postings:    postings,
+	}
+	if settlement.BookingID != nil {
+		entry.BookingID = *settlement.BookingID
+	}
+	if settlement.SettledAt != nil {
+		entry.OccurredAt = *settlement.SettledAt
+	} else {
+		entry.OccurredAt = time.Now()
+	}
+
+	return uc.post(ctx, entry, provider, accountBank, accountProviderFees)
+}
+
+func (uc *ledgerUsecase) ListEntries(ctx context.Context, filter ledger.Filter, limit, offset int) ([]*ledger.Entry, error) {
+	limit, offset = clampPage(limit, offset)
+	return uc.ledgerRepo.Entries(ctx, filter, limit, offset)
+}
+
+func (uc *ledgerUsecase) Balances(ctx context.Context, filter ledger.Filter) ([]*ledger.Balance, error) {
+	return uc.ledgerRepo.Balances(ctx, filter)
+}
+
+// post opens the accounts entry uses and posts it. An entry posted before is
+// taken as recorded.
+func (uc *ledgerUsecase) post(ctx context.Context, entry *ledger.Entry, accounts ...ledger.Account) error {
+	if err := uc.ledgerRepo.Open(ctx, accounts...); err != nil {
+		return err
+	}
+
+	err := uc.ledgerRepo.Post(ctx, entry)
+	if errors.Is(err, ledger.ErrDuplicateEntry) {
+		return nil
+	}
+	return err
+}
+
+// paymentKey identifies the entry of a payment event, which providers
+// identify by their own event IDs.
+func paymentKey(event *entity.PaymentEvent) string {
+	return "payment:" + event.Provider + ":" + event.EventID
+}
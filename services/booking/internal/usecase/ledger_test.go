@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/ledger"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+)
+
+func TestRecordRefund(t *testing.T) {
+	idr := func(amount int64) money.Money { return money.MustNew(amount, "IDR") }
+
+	tests := []struct {
+		name string
+		owed []money.Money
+		paid money.Money
+		want []ledger.Posting
+	}{
+		{
+			name: "owed by a cancellation",
+			owed: []money.Money{idr(100000)},
+			paid: idr(100000),
+			want: []ledger.Posting{
+				ledger.Credit("assets:provider:sandbox", idr(100000)),
+				ledger.Debit("liabilities:refunds_payable", idr(100000)),
+			},
+		},
+		{
+			name: "no cancellation",
+			paid: idr(100000),
+			want: []ledger.Posting{
+				ledger.Credit("assets:provider:sandbox", idr(100000)),
+				ledger.Debit("revenue:refunds", idr(100000)),
+			},
+		},
+		{
+			name: "more than owed",
+			owed: []money.Money{idr(30000), idr(20000)},
+			paid: idr(80000),
+			want: []ledger.Posting{
+				ledger.Credit("assets:provider:sandbox", idr(80000)),
+				ledger.Debit("liabilities:refunds_payable", idr(50000)),
+				ledger.Debit("revenue:refunds", idr(30000)),
+			},
+		},
+		{
+			name: "less than owed",
+			owed: []money.Money{idr(100000)},
+			paid: idr(40000),
+			want: []ledger.Posting{
+				ledger.Credit("assets:provider:sandbox", idr(40000)),
+				ledger.Debit("liabilities:refunds_payable", idr(40000)),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			books := &fakeLedger{}
+			uc := NewLedgerUsecase(books)
+
+			for i, amount := range tt.owed {
+				event, err := entity.NewBookingEvent(1, entity.EventRefundRequested, &entity.RefundRequest{
+					BookingID: 1, Amount: amount, Percent: 100, RequestedAt: time.Now(),
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				event.ID = int64(i + 1)
+				if err := uc.Publish(ctx, event); err != nil {
+					t.Fatalf("refund owed: %v", err)
+				}
+			}
+
+			refund := &entity.PaymentEvent{Provider: "sandbox", EventID: "evt_1", Type: entity.PaymentRefunded, BookingID: 1, Amount: tt.paid}
+			if err := uc.RecordRefund(ctx, refund); err != nil {
+				t.Fatalf("record refund: %v", err)
+			}
+			entry := books.entries[len(books.entries)-1]
+			if entry.Kind != ledgerRefundPaid || !reflect.DeepEqual(entry.Postings, tt.want) {
+				t.Errorf("got %s entry %+v, want postings %+v", entry.Kind, entry.Postings, tt.want)
+			}
+
+			// Redelivered webhooks are booked once
+			if err := uc.RecordRefund(ctx, refund); err != nil || len(books.entries) != len(tt.owed)+1 {
+				t.Errorf("redelivery: %v, %d entries", err, len(books.entries))
+			}
+		})
+	}
+}
+
+func TestRecordRefundSettlesOwedOnce(t *testing.T) {
+	ctx := context.Background()
+	books := &fakeLedger{}
+	uc := NewLedgerUsecase(books)
+
+	event, err := entity.NewBookingEvent(1, entity.EventRefundRequested, &entity.RefundRequest{
+		BookingID: 1, Amount: money.MustNew(100000, "IDR"), Percent: 100, RequestedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.Publish(ctx, event); err != nil {
+		t.Fatalf("refund owed: %v", err)
+	}
+
+	// Two refunds of the same cancellation only settle it once
+	for _, id := range []string{"evt_1", "evt_2"} {
+		refund := &entity.PaymentEvent{Provider: "sandbox", EventID: id, Type: entity.PaymentRefunded, BookingID: 1, Amount: money.MustNew(100000, "IDR")}
+		if err := uc.RecordRefund(ctx, refund); err != nil {
+			t.Fatalf("record refund %s: %v", id, err)
+		}
+	}
+
+	balances, _ := books.Balances(ctx, ledger.Filter{BookingID: 1, Account: accountRefundsPayable.Code})
+	if len(balances) != 1 || !balances[0].Balance.IsZero() {
+		t.Errorf("refunds payable: %+v", balances)
+	}
+	last := books.entries[len(books.entries)-1]
+	if want := ledger.Debit(accountRefunds.Code, money.MustNew(100000, "IDR")); last.Postings[1] != want {
+		t.Errorf("second refund postings: %+v", last.Postings)
+	}
+}
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type manifestUsecase struct {
+	manifestRepo repository.ManifestRepository
+}
+
+func NewManifestUsecase(manifestRepo repository.ManifestRepository) service.ManifestService {
+	return &manifestUsecase{
+		manifestRepo: manifestRepo,
+	}
+}
+
+func (uc *manifestUsecase) StreamManifest(ctx context.Context, filter entity.ManifestFilter, fn func(*entity.ManifestEntry) error) error {
+	if filter.RouteID <= 0 || filter.DepartureAt.IsZero() {
+		return apperrors.ErrInvalidManifestFilter
+	}
+
+	return uc.manifestRepo.Stream(ctx, filter, fn)
+}
@@ -0,0 +1,169 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+// preparePassengers validates the passengers of a new booking and marks them
+// active. Passengers are optional, but when given there must be one per seat
+// booked; qty defaults to their number.
+func preparePassengers(booking *entity.Booking) error {
+	if len(booking.Passengers) == 0 {
+		return nil
+	}
+	if booking.Qty == 0 {
+		booking.Qty = len(booking.Passengers)
+	}
+	if booking.Qty != len(booking.Passengers) {
+		return apperrors.ErrInvalidPassengers
+	}
+
+	now := time.Now()
+	seats := make(map[string]bool)
+	for _, p := range booking.Passengers {
+		if p == nil {
+			return apperrors.ErrInvalidPassengers
+		}
+		p.FullName = strings.TrimSpace(p.FullName)
+		p.DocumentNumber = strings.TrimSpace(p.DocumentNumber)
+		p.Seat = strings.TrimSpace(p.Seat)
+		if p.FullName == "" || !p.DocumentType.Valid() || p.DocumentNumber == "" ||
+			p.DateOfBirth.IsZero() || p.DateOfBirth.After(now) {
+			return apperrors.ErrInvalidPassengers
+		}
+		if p.Seat != "" {
+			if seats[p.Seat] {
+				return apperrors.ErrInvalidSeats
+			}
+			seats[p.Seat] = true
+		}
+
+		p.ID = 0
+		p.Status = entity.PassengerActive
+		p.CancelReason = ""
+		p.CancelledAt = nil
+		p.CreatedAt = now
+	}
+
+	return nil
+}
+
+// CancelPassenger takes one passenger off a booking, and their share off its
+// price. Only unpaid bookings lose passengers one by one: what a paid one
+// collected is given back by cancelling it as a whole, which refunds through
+// the outbox and the ledger. Cancelling the last active passenger cancels the
+// booking either way.
+func (uc *bookingUsecase) CancelPassenger(ctx context.Context, bookingID, passengerID int64, reason entity.CancellationReason) (*entity.Cancellation, error) {
+	if reason == "" {
+		reason = entity.CancelReasonCustomerRequest
+	}
+	if !reason.Valid() {
+		return nil, apperrors.ErrInvalidCancelReason
+	}
+
+	var cancellation *entity.Cancellation
+	err := uc.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		booking, err := uc.bookingRepo.GetByID(ctx, bookingID, repository.ForUpdate())
+		if err != nil {
+			return err
+		}
+
+		var passenger *entity.Passenger
+		for _, p := range booking.Passengers {
+			if p.ID == passengerID {
+				passenger = p
+			}
+		}
+		if passenger == nil {
+			return apperrors.ErrPassengerNotFound
+		}
+		if passenger.Status == entity.PassengerCancelled {
+			return apperrors.ErrPassengerAlreadyCancelled
+		}
+		if booking.Status != entity.StatusCreated && len(booking.ActivePassengers()) > 1 {
+			return apperrors.ErrPassengerOfPaidBooking
+		}
+
+		now := time.Now()
+		passenger.Status = entity.PassengerCancelled
+		passenger.CancelReason = reason
+		passenger.CancelledAt = &now
+
+		// The last passenger takes the booking with them
+		active := booking.ActivePassengers()
+		if len(active) == 0 {
+			cancellation, err = uc.CancelBooking(ctx, bookingID, reason)
+			if err != nil {
+				return err
+			}
+			cancellation.PassengerID = &passenger.ID
+			return uc.bookingRepo.CancelPassenger(ctx, passenger)
+		}
+
+		// Each passenger carries an equal share of the remaining price
+		ratios := make([]int, len(active)+1)
+		for i := range ratios {
+			ratios[i] = 1
+		}
+		shares, err := booking.PriceTotal.Allocate(ratios...)
+		if err != nil {
+			return err
+		}
+		share := shares[len(shares)-1]
+
+		cancelPolicy, err := uc.policyFor(ctx, booking)
+		if err != nil {
+			return err
+		}
+		portion := *booking
+		portion.PriceTotal = share
+		decision, err := cancelPolicy.Evaluate(&portion, now)
+		if err != nil {
+			return err
+		}
+
+		before := *booking
+		booking.Qty--
+		if booking.PriceTotal, err = booking.PriceTotal.Sub(share); err != nil {
+			return err
+		}
+		booking.UpdatedAt = now
+
+		if err := uc.bookingRepo.CancelPassenger(ctx, passenger); err != nil {
+			return err
+		}
+		if err := uc.bookingRepo.Update(ctx, booking); err != nil {
+			return err
+		}
+
+		changes, err := entity.DiffBookings(&before, booking)
+		if err != nil {
+			return err
+		}
+		changes["passenger_id"] = entity.FieldChange{New: passenger.ID}
+		if err := uc.recordChanges(ctx, entity.HistoryActionPassengerCancelled, &before, booking, changes); err != nil {
+			return err
+		}
+
+		cancellation = &entity.Cancellation{
+			BookingID:     booking.ID,
+			PassengerID:   &passenger.ID,
+			Reason:        reason,
+			RefundPercent: decision.RefundPercent,
+			RefundAmount:  decision.RefundAmount,
+			CancelledAt:   now,
+		}
+		return uc.emit(ctx, booking.ID, entity.EventPassengerCancelled, cancellation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cancellation, nil
+}
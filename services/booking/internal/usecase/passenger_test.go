@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/policy"
+)
+
+func testPassenger(name, seat string) *entity.Passenger {
+	return &entity.Passenger{
+		FullName:       name,
+		DocumentType:   entity.DocumentNationalID,
+		DocumentNumber: "3174012345670001",
+		DateOfBirth:    entity.NewDate(1990, time.April, 12),
+		Seat:           seat,
+	}
+}
+
+func TestPreparePassengers(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1)
+
+	tests := []struct {
+		name   string
+		qty    int
+		modify func(p *entity.Passenger)
+		second *entity.Passenger
+		want   error
+	}{
+		{name: "valid", qty: 2},
+		{name: "qty from passengers"},
+		{name: "qty mismatch", qty: 3, want: apperrors.ErrInvalidPassengers},
+		{name: "blank name", qty: 2, modify: func(p *entity.Passenger) { p.FullName = "  " }, want: apperrors.ErrInvalidPassengers},
+		{name: "unknown document type", qty: 2, modify: func(p *entity.Passenger) { p.DocumentType = "VISA" }, want: apperrors.ErrInvalidPassengers},
+		{name: "blank document number", qty: 2, modify: func(p *entity.Passenger) { p.DocumentNumber = " " }, want: apperrors.ErrInvalidPassengers},
+		{name: "no date of birth", qty: 2, modify: func(p *entity.Passenger) { p.DateOfBirth = entity.Date{} }, want: apperrors.ErrInvalidPassengers},
+		{name: "born tomorrow", qty: 2, modify: func(p *entity.Passenger) {
+			p.DateOfBirth = entity.NewDate(tomorrow.Year(), tomorrow.Month(), tomorrow.Day())
+		}, want: apperrors.ErrInvalidPassengers},
+		{name: "duplicate seat", qty: 2, second: testPassenger("Budi Santoso", " 3A "), want: apperrors.ErrInvalidSeats},
+		{name: "no seats", qty: 2, modify: func(p *entity.Passenger) { p.Seat = "" }, second: testPassenger("Budi Santoso", "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			first := testPassenger(" Siti Rahma ", "3A")
+			first.ID, first.Status = 99, entity.PassengerCancelled
+			if tt.modify != nil {
+				tt.modify(first)
+			}
+			second := tt.second
+			if second == nil {
+				second = testPassenger("Budi Santoso", "3B")
+			}
+			booking := &entity.Booking{Qty: tt.qty, Passengers: []*entity.Passenger{first, second}}
+
+			err := preparePassengers(booking)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if err != nil {
+				return
+			}
+			if booking.Qty != 2 || first.FullName != "Siti Rahma" || first.ID != 0 || first.Status != entity.PassengerActive {
+				t.Errorf("prepared %+v with qty %d", first, booking.Qty)
+			}
+		})
+	}
+
+	if err := preparePassengers(&entity.Booking{Qty: 2}); err != nil {
+		t.Errorf("booking without passengers: %v", err)
+	}
+	if err := preparePassengers(&entity.Booking{Passengers: []*entity.Passenger{nil}}); !errors.Is(err, apperrors.ErrInvalidPassengers) {
+		t.Errorf("nil passenger: got %v, want ErrInvalidPassengers", err)
+	}
+}
+
+func newPassengerTestUsecase(status entity.BookingStatus) (*bookingUsecase, *fakeBookingRepo, *fakeOutbox) {
+	departure := time.Now().Add(100 * time.Hour)
+	booking := &entity.Booking{
+		TenantID:    entity.DefaultTenantID,
+		UserID:      9,
+		RouteID:     7,
+		Qty:         3,
+		Status:      status,
+		PriceTotal:  money.MustNew(300000, "IDR"),
+		DepartureAt: &departure,
+		Passengers: []*entity.Passenger{
+			testPassenger("Siti Rahma", "3A"),
+			testPassenger("Budi Santoso", "3B"),
+			testPassenger("Dewi Lestari", "3C"),
+		},
+	}
+	for _, p := range booking.Passengers {
+		p.Status = entity.PassengerActive
+	}
+
+	bookings := newFakeBookingRepo(booking)
+	outbox := &fakeOutbox{}
+	uc := &bookingUsecase{
+		bookingRepo:  bookings,
+		outboxRepo:   outbox,
+		historyRepo:  &fakeHistory{},
+		transactor:   fakeTransactor{},
+		tenants:      fakeTenants{},
+		cancelPolicy: policy.NewCancellationPolicy(2, nil),
+	}
+	return uc, bookings, outbox
+}
+
+func TestCancelPassenger(t *testing.T) {
+	ctx := context.Background()
+	uc, bookings, outbox := newPassengerTestUsecase(entity.StatusCreated)
+
+	cancellation, err := uc.CancelPassenger(ctx, 1, 2, "")
+	if err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if cancellation.PassengerID == nil || *cancellation.PassengerID != 2 || cancellation.Reason != entity.CancelReasonCustomerRequest ||
+		!cancellation.RefundAmount.IsZero() {
+		t.Errorf("cancellation: %+v", cancellation)
+	}
+
+	booking, _ := bookings.GetByID(ctx, 1)
+	if booking.Status != entity.StatusCreated || booking.Qty != 2 || booking.PriceTotal != money.MustNew(200000, "IDR") {
+		t.Errorf("booking after cancel: %+v", booking)
+	}
+	if p := booking.Passengers[1]; p.Status != entity.PassengerCancelled || p.CancelledAt == nil {
+		t.Errorf("passenger after cancel: %+v", p)
+	}
+	if want := []string{entity.EventPassengerCancelled}; !reflect.DeepEqual(outbox.types(), want) {
+		t.Errorf("events: got %v, want %v", outbox.types(), want)
+	}
+
+	if _, err := uc.CancelPassenger(ctx, 1, 2, ""); !errors.Is(err, apperrors.ErrPassengerAlreadyCancelled) {
+		t.Errorf("second cancel: got %v, want ErrPassengerAlreadyCancelled", err)
+	}
+	if _, err := uc.CancelPassenger(ctx, 1, 42, ""); !errors.Is(err, apperrors.ErrPassengerNotFound) {
+		t.Errorf("unknown passenger: got %v, want ErrPassengerNotFound", err)
+	}
+	if _, err := uc.CancelPassenger(ctx, 1, 1, "BORED"); !errors.Is(err, apperrors.ErrInvalidCancelReason) {
+		t.Errorf("unknown reason: got %v, want ErrInvalidCancelReason", err)
+	}
+	if _, err := uc.CancelPassenger(ctx, 2, 1, ""); !errors.Is(err, apperrors.ErrBookingNotFound) {
+		t.Errorf("unknown booking: got %v, want ErrBookingNotFound", err)
+	}
+}
+
+func TestCancelPassengerOfPaidBooking(t *testing.T) {
+	ctx := context.Background()
+	uc, bookings, outbox := newPassengerTestUsecase(entity.StatusPaid)
+
+	// Taking one passenger off would lower the price without a refund
+	if _, err := uc.CancelPassenger(ctx, 1, 2, ""); !errors.Is(err, apperrors.ErrPassengerOfPaidBooking) {
+		t.Fatalf("got %v, want ErrPassengerOfPaidBooking", err)
+	}
+	booking, _ := bookings.GetByID(ctx, 1)
+	if booking.Qty != 3 || booking.PriceTotal != money.MustNew(300000, "IDR") || len(booking.ActivePassengers()) != 3 {
+		t.Errorf("booking after refused cancel: %+v", booking)
+	}
+	if len(outbox.types()) != 0 {
+		t.Errorf("events: got %v, want none", outbox.types())
+	}
+
+	// The last one cancels the booking, which refunds what was paid
+	stored := bookings.bookings[1]
+	stored.Passengers[0].Status = entity.PassengerCancelled
+	stored.Passengers[1].Status = entity.PassengerCancelled
+	cancellation, err := uc.CancelPassenger(ctx, 1, 3, "")
+	if err != nil {
+		t.Fatalf("cancel last passenger: %v", err)
+	}
+	if cancellation.PassengerID == nil || *cancellation.PassengerID != 3 || cancellation.RefundAmount != money.MustNew(300000, "IDR") {
+		t.Errorf("cancellation: %+v", cancellation)
+	}
+	if want := []string{entity.EventBookingCancelled, entity.EventRefundRequested}; !reflect.DeepEqual(outbox.types(), want) {
+		t.Errorf("events: got %v, want %v", outbox.types(), want)
+	}
+}
+
+func TestCancelLastPassengerCancelsBooking(t *testing.T) {
+	ctx := context.Background()
+	uc, bookings, outbox := newPassengerTestUsecase(entity.StatusCreated)
+
+	for _, id := range []int64{1, 2} {
+		if _, err := uc.CancelPassenger(ctx, 1, id, ""); err != nil {
+			t.Fatalf("cancel passenger %d: %v", id, err)
+		}
+	}
+	cancellation, err := uc.CancelPassenger(ctx, 1, 3, "")
+	if err != nil {
+		t.Fatalf("cancel last passenger: %v", err)
+	}
+	if cancellation.PassengerID == nil || *cancellation.PassengerID != 3 {
+		t.Errorf("cancellation: %+v", cancellation)
+	}
+
+	booking, _ := bookings.GetByID(ctx, 1)
+	if booking.Status != entity.StatusCancelled || len(booking.ActivePassengers()) != 0 {
+		t.Errorf("booking after last cancel: %+v", booking)
+	}
+
+	// An unpaid booking owes no refunds
+	want := []string{entity.EventPassengerCancelled, entity.EventPassengerCancelled, entity.EventBookingCancelled}
+	if !reflect.DeepEqual(outbox.types(), want) {
+		t.Errorf("events: got %v, want %v", outbox.types(), want)
+	}
+}
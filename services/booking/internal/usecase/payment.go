@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type paymentUsecase struct {
+	bookingService service.BookingService
+	bookingRepo    repository.BookingRepository
+	paymentRepo    repository.PaymentEventRepository
+	ledgerService  service.LedgerService
+	transactor     repository.Transactor
+}
+
+func NewPaymentUsecase(
+	bookingService service.BookingService,
+	bookingRepo repository.BookingRepository,
+	paymentRepo repository.PaymentEventRepository,
+	ledgerService service.LedgerService,
+	transactor repository.Transactor,
+) service.PaymentService {
+	return &paymentUsecase{
+		bookingService: bookingService,
+		bookingRepo:    bookingRepo,
+		paymentRepo:    paymentRepo,
+		ledgerService:  ledgerService,
+		transactor:     transactor,
+	}
+}
+
+func (uc *paymentUsecase) HandlePaymentEvent(ctx context.Context, event *entity.PaymentEvent) (*entity.PaymentEvent, error) {
+	if event.Provider == "" || event.EventID == "" || event.BookingID <= 0 ||
+		!event.Type.Valid() || !event.Amount.Currency().Valid() {
+		return nil, apperrors.ErrInvalidPaymentEvent
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now()
+	}
+
+	// Providers redeliver until they see a 2xx, so most duplicates end here
+	if recorded, err := uc.paymentRepo.Get(ctx, event.Provider, event.EventID); err == nil {
+		return recorded, apperrors.ErrDuplicatePaymentEvent
+	} else if !errors.Is(err, apperrors.ErrPaymentEventNotFound) {
+		return nil, err
+	}
+
+	err := uc.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		outcome, err := uc.apply(ctx, event)
+		if err != nil {
+			return err
+		}
+		event.Outcome = outcome
+
+		// A concurrent delivery of the same event fails here and rolls back
+		return uc.paymentRepo.Save(ctx, event)
+	})
+	if errors.Is(err, apperrors.ErrDuplicatePaymentEvent) {
+		recorded, getErr := uc.paymentRepo.Get(ctx, event.Provider, event.EventID)
+		if getErr != nil {
+			return nil, getErr
+		}
+		return recorded, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// apply moves the booking as the event demands and books the money in the
+// ledger. Events that cannot apply are acknowledged rather than failed: the
+// provider cannot fix them by retrying. Payments are booked only when they
+// pay the booking; refunds whenever the booking exists, as partial refunds
+// leave its status alone.
+func (uc *paymentUsecase) apply(ctx context.Context, event *entity.PaymentEvent) (entity.PaymentOutcome, error) {
+	status, ok := entity.PaymentTransitions[event.Type]
+	if !ok {
+		return entity.PaymentOutcomeIgnored, nil
+	}
+
+	booking, err := uc.bookingRepo.GetByID(ctx, event.BookingID, repository.ForUpdate())
+	if errors.Is(err, apperrors.ErrBookingNotFound) || errors.Is(err, apperrors.ErrBookingArchived) {
+		return entity.PaymentOutcomeIgnored, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if event.Type == entity.PaymentRefunded {
+		if err := uc.ledgerService.RecordRefund(ctx, event); err != nil {
+			return "", err
+		}
+	}
+	if booking.Status == status {
+		return entity.PaymentOutcomeIgnored, nil
+	}
+	if event.Type == entity.PaymentSucceeded && !event.Amount.Equal(booking.PriceTotal) {
+		return entity.PaymentOutcomeRejected, nil
+	}
+
+	_, err = uc.bookingService.TransitionStatus(ctx, booking.ID, status)
+	if errors.Is(err, apperrors.ErrInvalidStatusTransition) {
+		return entity.PaymentOutcomeIgnored, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if event.Type == entity.PaymentSucceeded {
+		if err := uc.ledgerService.RecordPayment(ctx, event); err != nil {
+			return "", err
+		}
+	}
+
+	return entity.PaymentOutcomeApplied, nil
+}
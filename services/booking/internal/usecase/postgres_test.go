@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/pkg/testutil"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/policy"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/migrations"
+)
+
+// The Postgres tests run the use cases on the real repositories where the
+// guarantees under test live in the database, and are skipped where Postgres
+// is not installed or runs as root.
+func TestMain(m *testing.M) {
+	os.Exit(testutil.RunWithPostgres(m))
+}
+
+func newPostgresBookingUsecase(db *sqlx.DB) *bookingUsecase {
+	return &bookingUsecase{
+		bookingRepo:   repository.NewPostgresBookingRepository(db),
+		outboxRepo:    repository.NewPostgresOutboxRepository(db),
+		historyRepo:   repository.NewPostgresBookingHistoryRepository(db),
+		idemRepo:      repository.NewPostgresIdempotencyRepository(db),
+		promotionRepo: repository.NewPostgresPromotionRepository(db),
+		routeRepo:     repository.NewPostgresRouteRepository(db),
+		transactor:    database.NewTransactor(db),
+		tenants:       fakeTenants{},
+		cancelPolicy:  policy.NewCancellationPolicy(2, nil),
+	}
+}
+
+func TestPostgresPromotionRedemptionLimitUnderConcurrency(t *testing.T) {
+	db := testutil.NewPostgresSchema(t, migrations.FS)
+	uc := newPostgresBookingUsecase(db)
+	ctx := reqctx.WithTenant(context.Background(), entity.DefaultTenantID)
+
+	limit := 3
+	promotion := &entity.Promotion{
+		TenantID:       entity.DefaultTenantID,
+		Code:           "MUDIK25",
+		Kind:           entity.PromotionPercent,
+		Percent:        25,
+		MaxRedemptions: &limit,
+		StartsAt:       time.Now().Add(-time.Hour),
+	}
+	if err := NewPromotionUsecase(uc.promotionRepo).CreatePromotion(ctx, promotion); err != nil {
+		t.Fatalf("create promotion: %v", err)
+	}
+	// Only the redemptions are meant to race
+	if err := uc.routeRepo.Claim(ctx, 7, entity.DefaultTenantID); err != nil {
+		t.Fatalf("claim route: %v", err)
+	}
+
+	const attempts = 12
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		redeemed  int
+		exhausted int
+	)
+	for i := range attempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			booking := &entity.Booking{
+				UserID:     int64(i + 1),
+				RouteID:    7,
+				Qty:        1,
+				PriceTotal: money.MustNew(100000, "IDR"),
+				PromoCode:  "mudik25",
+			}
+			err := uc.CreateBooking(ctx, booking)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				redeemed++
+				if booking.Discount == nil || *booking.Discount != money.MustNew(25000, "IDR") || booking.PriceTotal != money.MustNew(75000, "IDR") {
+					t.Errorf("discounted booking: %+v", booking)
+				}
+			case errors.Is(err, apperrors.ErrPromoCodeExhausted):
+				exhausted++
+			default:
+				t.Errorf("create booking: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if redeemed != limit || exhausted != attempts-limit {
+		t.Errorf("%d redeemed and %d exhausted, want %d and %d", redeemed, exhausted, limit, attempts-limit)
+	}
+	stored, err := uc.promotionRepo.GetByID(ctx, promotion.ID)
+	if err != nil || stored.Redemptions != limit {
+		t.Errorf("stored redemptions: %+v, %v", stored, err)
+	}
+	var rows int
+	if err := db.Get(&rows, `SELECT count(*) FROM promotion_redemptions WHERE promotion_id = $1`, promotion.ID); err != nil || rows != limit {
+		t.Errorf("%d redemption rows, %v", rows, err)
+	}
+}
+
+func TestPostgresPromotionLimitPerUser(t *testing.T) {
+	db := testutil.NewPostgresSchema(t, migrations.FS)
+	uc := newPostgresBookingUsecase(db)
+	ctx := reqctx.WithTenant(context.Background(), entity.DefaultTenantID)
+
+	perUser := 1
+	amount := money.MustNew(30000, "IDR")
+	promotion := &entity.Promotion{
+		Code:       "HEMAT30",
+		Kind:       entity.PromotionFixed,
+		Amount:     &amount,
+		MinQty:     2,
+		MaxPerUser: &perUser,
+		StartsAt:   time.Now().Add(-time.Hour),
+	}
+	if err := NewPromotionUsecase(uc.promotionRepo).CreatePromotion(ctx, promotion); err != nil {
+		t.Fatalf("create promotion: %v", err)
+	}
+
+	book := func(userID int64, qty int) (*entity.Booking, error) {
+		booking := &entity.Booking{UserID: userID, RouteID: 7, Qty: qty, PriceTotal: money.MustNew(200000, "IDR"), PromoCode: "HEMAT30"}
+		return booking, uc.CreateBooking(ctx, booking)
+	}
+
+	if _, err := book(1, 1); !errors.Is(err, apperrors.ErrInvalidPromoCode) {
+		t.Errorf("below min qty: got %v, want ErrInvalidPromoCode", err)
+	}
+	booking, err := book(1, 2)
+	if err != nil || booking.PriceTotal != money.MustNew(170000, "IDR") {
+		t.Fatalf("first booking: %+v, %v", booking, err)
+	}
+	if _, err := book(1, 2); !errors.Is(err, apperrors.ErrPromoCodeExhausted) {
+		t.Errorf("second booking of the user: got %v, want ErrPromoCodeExhausted", err)
+	}
+	if _, err := book(2, 2); err != nil {
+		t.Errorf("another user: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type promotionUsecase struct {
+	promotionRepo repository.PromotionRepository
+}
+
+func NewPromotionUsecase(promotionRepo repository.PromotionRepository) service.PromotionService {
+	return &promotionUsecase{
+		promotionRepo: promotionRepo,
+	}
+}
+
+// Publish releases the redemption of an expired booking. Paid bookings keep
+// theirs through cancellation, as the discount was part of what they paid.
+func (uc *promotionUsecase) Publish(ctx context.Context, event *entity.OutboxEvent) error {
+	if event.EventType != entity.EventBookingExpired {
+		return nil
+	}
+
+	_, err := uc.promotionRepo.Release(ctx, event.AggregateID)
+	return err
+}
+
+func (uc *promotionUsecase) CreatePromotion(ctx context.Context, promotion *entity.Promotion) error {
+	if err := promotion.Validate(); err != nil {
+		return err
+	}
+
+	promotion.TenantID = tenantOf(ctx)
+	promotion.Redemptions = 0
+	promotion.CreatedAt = time.Now()
+	promotion.UpdatedAt = promotion.CreatedAt
+
+	return uc.promotionRepo.Create(ctx, promotion)
+}
+
+func (uc *promotionUsecase) GetPromotion(ctx context.Context, id int64) (*entity.Promotion, error) {
+	return uc.promotionRepo.GetByID(ctx, id)
+}
+
+func (uc *promotionUsecase) ListPromotions(ctx context.Context, limit, offset int) ([]*entity.Promotion, error) {
+	limit, offset = clampPage(limit, offset)
+	return uc.promotionRepo.List(ctx, limit, offset)
+}
+
+func (uc *promotionUsecase) UpdatePromotion(ctx context.Context, promotion *entity.Promotion) (*entity.Promotion, error) {
+	existing, err := uc.promotionRepo.GetByID(ctx, promotion.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	promotion.TenantID = existing.TenantID
+	promotion.Code = existing.Code
+	if err := promotion.Validate(); err != nil {
+		return nil, err
+	}
+	promotion.UpdatedAt = time.Now()
+
+	if err := uc.promotionRepo.Update(ctx, promotion); err != nil {
+		return nil, err
+	}
+
+	return uc.promotionRepo.GetByID(ctx, promotion.ID)
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/settlement"
+)
+
+type reconciliationUsecase struct {
+	reconciliationRepo repository.ReconciliationRepository
+	ledgerService      service.LedgerService
+	transactor         repository.Transactor
+	files              fs.FS
+	grace              time.Duration
+	lookback           time.Duration
+}
+
+// NewReconciliationUsecase ingests settlement files from files, laid out as
+// <provider>/<name>.csv; with files nil runs only reconcile what was
+// ingested before. Bookings paid within lookback must be settled once grace
+// has passed. Ingested settlements are booked in the ledger as payouts.
+func NewReconciliationUsecase(
+	reconciliationRepo repository.ReconciliationRepository,
+	ledgerService service.LedgerService,
+	transactor repository.Transactor,
+	files fs.FS,
+	grace, lookback time.Duration,
+) service.ReconciliationService {
+	return &reconciliationUsecase{
+		reconciliationRepo: reconciliationRepo,
+		ledgerService:      ledgerService,
+		transactor:         transactor,
+		files:              files,
+		grace:              grace,
+		lookback:           lookback,
+	}
+}
+
+func (uc *reconciliationUsecase) Reconcile(ctx context.Context) (*entity.ReconciliationRun, error) {
+	run := &entity.ReconciliationRun{StartedAt: time.Now()}
+
+	if uc.files != nil {
+		paths, err := fs.Glob(uc.files, "*/*.csv")
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range paths {
+			saved, n, err := uc.ingest(ctx, name)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				run.Errors = append(run.Errors, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			if saved {
+				run.Files++
+				run.Settlements += n
+			}
+		}
+	}
+
+	var err error
+	run.Opened, run.Resolved, err = uc.reconciliationRepo.Reconcile(ctx, run.StartedAt.Add(-uc.lookback), run.StartedAt.Add(-uc.grace))
+	if err != nil {
+		return nil, err
+	}
+
+	run.FinishedAt = time.Now()
+	if err := uc.reconciliationRepo.SaveRun(ctx, run); err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// ingest saves one settlement file unless it was ingested before, returning
+// whether it did and how many settlements the file holds.
+func (uc *reconciliationUsecase) ingest(ctx context.Context, name string) (bool, int, error) {
+	data, err := fs.ReadFile(uc.files, name)
+	if err != nil {
+		return false, 0, err
+	}
+	sum := sha256.Sum256(data)
+
+	file := &entity.SettlementFile{
+		Provider: path.Dir(name),
+		Name:     path.Base(name),
+		Checksum: hex.EncodeToString(sum[:]),
+	}
+	settlements, err := settlement.Read(bytes.NewReader(data))
+	if err != nil {
+		return false, 0, err
+	}
+
+	var saved bool
+	err = uc.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		saved, err = uc.reconciliationRepo.SaveFile(ctx, file, settlements)
+		if err != nil || !saved {
+			return err
+		}
+		for _, s := range settlements {
+			if err := uc.ledgerService.RecordSettlement(ctx, s); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil || !saved {
+		return false, 0, err
+	}
+
+	return true, len(settlements), nil
+}
+
+func (uc *reconciliationUsecase) ReconciliationReport(ctx context.Context, filter entity.DiscrepancyFilter, limit, offset int) (*entity.ReconciliationReport, error) {
+	if filter.Kind != "" && !filter.Kind.Valid() {
+		return nil, apperrors.ErrInvalidDiscrepancyFilter
+	}
+	limit, offset = clampPage(limit, offset)
+
+	lastRun, err := uc.reconciliationRepo.LastRun(ctx)
+	if err != nil {
+		return nil, err
+	}
+	open, err := uc.reconciliationRepo.CountOpen(ctx)
+	if err != nil {
+		return nil, err
+	}
+	discrepancies, err := uc.reconciliationRepo.ListDiscrepancies(ctx, filter, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.ReconciliationReport{
+		LastRun:       lastRun,
+		Open:          open,
+		Discrepancies: discrepancies,
+	}, nil
+}
@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+// defaultReportDays is how far back a report reaches when no range is given.
+const defaultReportDays = 30
+
+type reportUsecase struct {
+	reportRepo repository.ReportRepository
+	location   *time.Location
+	overlap    time.Duration
+}
+
+// NewReportUsecase reports in location unless a query names another zone.
+// Refreshes reach back overlap before the previous one to pick up bookings
+// written by transactions that committed after it started.
+func NewReportUsecase(reportRepo repository.ReportRepository, location *time.Location, overlap time.Duration) service.ReportService {
+	return &reportUsecase{
+		reportRepo: reportRepo,
+		location:   location,
+		overlap:    overlap,
+	}
+}
+
+func (uc *reportUsecase) RevenueReport(ctx context.Context, query *entity.ReportQuery) (*entity.Report, error) {
+	if err := uc.normalize(query); err != nil {
+		return nil, err
+	}
+
+	rows, err := uc.reportRepo.Revenue(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.report(ctx, query, rows)
+}
+
+func (uc *reportUsecase) FunnelReport(ctx context.Context, query *entity.ReportQuery) (*entity.Report, error) {
+	if err := uc.normalize(query); err != nil {
+		return nil, err
+	}
+
+	rows, err := uc.reportRepo.Funnel(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		if row.Created > 0 {
+			row.PaidRate = float64(row.Paid) / float64(row.Created)
+			row.ConfirmedRate = float64(row.Confirmed) / float64(row.Created)
+		}
+	}
+
+	return uc.report(ctx, query, rows)
+}
+
+func (uc *reportUsecase) StatusReport(ctx context.Context, query *entity.ReportQuery) (*entity.Report, error) {
+	if err := uc.normalize(query); err != nil {
+		return nil, err
+	}
+
+	rows, err := uc.reportRepo.Statuses(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.report(ctx, query, rows)
+}
+
+func (uc *reportUsecase) RefreshReports(ctx context.Context, full bool) (int, error) {
+	return uc.reportRepo.Refresh(ctx, full, uc.overlap)
+}
+
+// normalize fills in the defaults of query and validates it.
+func (uc *reportUsecase) normalize(query *entity.ReportQuery) error {
+	if query.Location == nil {
+		query.Location = uc.location
+	}
+	if query.Interval == "" {
+		query.Interval = entity.ReportDaily
+	}
+	if query.To.IsZero() {
+		now := time.Now().In(query.Location)
+		query.To = entity.NewDate(now.Year(), now.Month(), now.Day())
+	}
+	if query.From.IsZero() {
+		query.From = entity.NewDate(query.To.Year(), query.To.Month(), query.To.Day()-defaultReportDays+1)
+	}
+
+	if !query.Interval.Valid() || query.From.After(query.To.Time) || query.RouteID < 0 {
+		return apperrors.ErrInvalidReportQuery
+	}
+
+	// Rollups are hourly, so only whole-hour offsets bucket exactly
+	for _, at := range []time.Time{query.Start(), query.End()} {
+		if _, offset := at.Zone(); offset%3600 != 0 {
+			return apperrors.ErrInvalidReportQuery
+		}
+	}
+
+	return nil
+}
+
+func (uc *reportUsecase) report(ctx context.Context, query *entity.ReportQuery, rows interface{}) (*entity.Report, error) {
+	refreshedAt, err := uc.reportRepo.RefreshedUntil(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Report{
+		From:        query.From,
+		To:          query.To,
+		TimeZone:    query.Location.String(),
+		Interval:    query.Interval,
+		RefreshedAt: refreshedAt,
+		Rows:        rows,
+	}, nil
+}
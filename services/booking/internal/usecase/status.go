@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+)
+
+func (uc *bookingUsecase) TransitionStatus(ctx context.Context, id int64, status entity.BookingStatus) (*entity.Booking, error) {
+	if !status.Valid() {
+		return nil, apperrors.ErrInvalidStatus
+	}
+	// Cancelling must go through the cancellation policy and refund logic
+	if status == entity.StatusCancelled {
+		return nil, apperrors.ErrInvalidStatusTransition
+	}
+
+	var booking *entity.Booking
+	err := uc.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		booking, err = uc.bookingRepo.GetByID(ctx, id, repository.ForUpdate())
+		if err != nil {
+			return err
+		}
+
+		return uc.transition(ctx, booking, status)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return booking, nil
+}
+
+func (uc *bookingUsecase) ExpireBookings(ctx context.Context, createdBefore time.Time, limit int) (int, error) {
+	filter := entity.BookingFilter{Status: entity.StatusCreated, CreatedTo: &createdBefore}
+	candidates, err := uc.bookingRepo.List(ctx, filter, limit, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, candidate := range candidates {
+		err := uc.transactor.WithinTx(ctx, func(ctx context.Context) error {
+			// Re-read under lock: the booking may have been paid meanwhile
+			booking, err := uc.bookingRepo.GetByID(ctx, candidate.ID, repository.ForUpdate())
+			if err != nil {
+				return err
+			}
+			if booking.Status != entity.StatusCreated {
+				return nil
+			}
+
+			expired++
+			return uc.transition(ctx, booking, entity.StatusExpired)
+		})
+		if err != nil && !errors.Is(err, apperrors.ErrBookingNotFound) && !errors.Is(err, apperrors.ErrBookingArchived) {
+			return expired, err
+		}
+	}
+
+	return expired, nil
+}
+
+// transition applies a status change to a booking read under lock in the
+// current transaction.
+func (uc *bookingUsecase) transition(ctx context.Context, booking *entity.Booking, status entity.BookingStatus) error {
+	if !entity.CanTransition(booking.Status, status) {
+		return apperrors.ErrInvalidStatusTransition
+	}
+
+	before := *booking
+	now := time.Now()
+	booking.Status = status
+	booking.UpdatedAt = now
+
+	if err := uc.bookingRepo.Update(ctx, booking); err != nil {
+		return err
+	}
+	if err := uc.record(ctx, entity.HistoryActionStatus, &before, booking); err != nil {
+		return err
+	}
+
+	return uc.emit(ctx, booking.ID, entity.StatusEvents[status], &entity.StatusChange{
+		BookingID: booking.ID,
+		UserID:    booking.UserID,
+		RouteID:   booking.RouteID,
+		OldStatus: before.Status,
+		NewStatus: status,
+		ChangedAt: now,
+	})
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/cache"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type tenantUsecase struct {
+	tenantRepo repository.TenantRepository
+	routeRepo  repository.RouteRepository
+	cache      cache.Cache
+	cacheTTL   time.Duration
+}
+
+// NewTenantUsecase returns the tenant service. Every request resolves its
+// tenant, so tenants are cached for cacheTTL; updates made on other
+// instances show within that time.
+func NewTenantUsecase(
+	tenantRepo repository.TenantRepository,
+	routeRepo repository.RouteRepository,
+	cache cache.Cache,
+	cacheTTL time.Duration,
+) service.TenantService {
+	return &tenantUsecase{
+		tenantRepo: tenantRepo,
+		routeRepo:  routeRepo,
+		cache:      cache,
+		cacheTTL:   cacheTTL,
+	}
+}
+
+// tenantOf returns the tenant in ctx, or the default tenant for callers
+// acting across tenants.
+func tenantOf(ctx context.Context) string {
+	if tenant := reqctx.Tenant(ctx); tenant != "" && tenant != reqctx.AllTenants {
+		return tenant
+	}
+	return entity.DefaultTenantID
+}
+
+func (uc *tenantUsecase) CreateTenant(ctx context.Context, tenant *entity.Tenant) error {
+	if err := tenant.Validate(); err != nil {
+		return err
+	}
+
+	tenant.CreatedAt = time.Now()
+	tenant.UpdatedAt = tenant.CreatedAt
+
+	return uc.tenantRepo.Create(ctx, tenant)
+}
+
+func (uc *tenantUsecase) GetTenant(ctx context.Context, id string) (*entity.Tenant, error) {
+	key := "tenant:" + id
+	if data, err := uc.cache.Get(ctx, key); err == nil {
+		var tenant entity.Tenant
+		if json.Unmarshal(data, &tenant) == nil {
+			return &tenant, nil
+		}
+	}
+
+	tenant, err := uc.tenantRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// A failing cache only costs the next caller a query
+	if data, err := json.Marshal(tenant); err == nil {
+		uc.cache.Set(ctx, key, data, uc.cacheTTL)
+	}
+
+	return tenant, nil
+}
+
+func (uc *tenantUsecase) ListTenants(ctx context.Context, limit, offset int) ([]*entity.Tenant, error) {
+	limit, offset = clampPage(limit, offset)
+	return uc.tenantRepo.List(ctx, limit, offset)
+}
+
+func (uc *tenantUsecase) UpdateTenant(ctx context.Context, tenant *entity.Tenant) (*entity.Tenant, error) {
+	if err := tenant.Validate(); err != nil {
+		return nil, err
+	}
+	tenant.UpdatedAt = time.Now()
+
+	if err := uc.tenantRepo.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+	uc.cache.Delete(ctx, "tenant:"+tenant.ID)
+
+	return uc.tenantRepo.GetByID(ctx, tenant.ID)
+}
+
+func (uc *tenantUsecase) RegisterRoute(ctx context.Context, route *entity.Route) error {
+	route.TenantID = tenantOf(ctx)
+	return uc.routeRepo.Save(ctx, route)
+}
+
+func (uc *tenantUsecase) ListRoutes(ctx context.Context, limit, offset int) ([]*entity.Route, error) {
+	limit, offset = clampPage(limit, offset)
+	return uc.routeRepo.List(ctx, tenantOf(ctx), limit, offset)
+}
@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/pkg/ticket"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type ticketUsecase struct {
+	ticketRepo  repository.TicketRepository
+	bookingRepo repository.BookingRepository
+	signingKey  ed25519.PrivateKey
+	verifyKeys  []ed25519.PublicKey
+	qrSize      int
+}
+
+// NewTicketUsecase signs tickets with signingKey and renders QR codes of
+// qrSize pixels square. Tokens verify against the signing key and any
+// previousKeys still honoured after a rotation.
+func NewTicketUsecase(
+	ticketRepo repository.TicketRepository,
+	bookingRepo repository.BookingRepository,
+	signingKey ed25519.PrivateKey,
+	previousKeys []ed25519.PublicKey,
+	qrSize int,
+) service.TicketService {
+	return &ticketUsecase{
+		ticketRepo:  ticketRepo,
+		bookingRepo: bookingRepo,
+		signingKey:  signingKey,
+		verifyKeys:  append([]ed25519.PublicKey{signingKey.Public().(ed25519.PublicKey)}, previousKeys...),
+		qrSize:      qrSize,
+	}
+}
+
+func (uc *ticketUsecase) Publish(ctx context.Context, event *entity.OutboxEvent) error {
+	switch event.EventType {
+	case entity.EventBookingConfirmed:
+		return uc.issue(ctx, event.AggregateID)
+	case entity.EventBookingCancelled, entity.EventBookingExpired:
+		_, err := uc.ticketRepo.RevokeByBooking(ctx, event.AggregateID, event.CreatedAt)
+		return err
+	case entity.EventPassengerCancelled:
+		// Only the passenger matters here; the payload is an entity.Cancellation
+		var cancellation struct {
+			PassengerID *int64    `json:"passenger_id"`
+			CancelledAt time.Time `json:"cancelled_at"`
+		}
+		if err := json.Unmarshal(event.Payload, &cancellation); err != nil {
+			return err
+		}
+		if cancellation.PassengerID == nil {
+			return nil
+		}
+		_, err := uc.ticketRepo.RevokeByPassenger(ctx, *cancellation.PassengerID, cancellation.CancelledAt)
+		return err
+	}
+
+	return nil
+}
+
+// issue creates a ticket per active passenger, or per seat when the booking
+// has no passenger details. Tickets that already exist are kept, so a
+// replayed event issues nothing new.
+func (uc *ticketUsecase) issue(ctx context.Context, bookingID int64) error {
+	booking, err := uc.bookingRepo.GetByID(ctx, bookingID)
+	if errors.Is(err, apperrors.ErrBookingNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// The booking may have been cancelled since it was confirmed
+	if booking.Status != entity.StatusConfirmed {
+		return nil
+	}
+
+	now := time.Now()
+	var tickets []*entity.Ticket
+	if len(booking.Passengers) > 0 {
+		for i, p := range booking.Passengers {
+			if p.Status != entity.PassengerActive {
+				continue
+			}
+			tickets = append(tickets, &entity.Ticket{
+				Seq:         i + 1,
+				PassengerID: &p.ID,
+				HolderName:  p.FullName,
+				Seat:        p.Seat,
+			})
+		}
+	} else {
+		for seq := 1; seq <= booking.Qty; seq++ {
+			tickets = append(tickets, &entity.Ticket{Seq: seq})
+		}
+	}
+
+	for _, t := range tickets {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return err
+		}
+		t.ID = hex.EncodeToString(id)
+		t.BookingID = booking.ID
+		t.Status = entity.TicketIssued
+		t.IssuedAt = now
+
+		claims := &ticket.Claims{
+			TicketID:  t.ID,
+			BookingID: booking.ID,
+			RouteID:   booking.RouteID,
+			Seat:      t.Seat,
+			IssuedAt:  now.Unix(),
+		}
+		if t.PassengerID != nil {
+			claims.PassengerID = *t.PassengerID
+		}
+		if booking.DepartureAt != nil {
+			claims.DepartureAt = booking.DepartureAt.Unix()
+		}
+		if t.Token, err = ticket.Sign(uc.signingKey, claims); err != nil {
+			return err
+		}
+
+		if _, err := uc.ticketRepo.Create(ctx, t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (uc *ticketUsecase) ListTickets(ctx context.Context, bookingID int64) ([]*entity.Ticket, error) {
+	if _, err := uc.bookingRepo.GetByID(ctx, bookingID); err != nil {
+		return nil, err
+	}
+
+	tickets, err := uc.ticketRepo.ListByBookingID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tickets {
+		if t.Status != entity.TicketIssued {
+			continue
+		}
+		if t.QRCode, err = qrcode.Encode(t.Token, qrcode.Medium, uc.qrSize); err != nil {
+			return nil, err
+		}
+	}
+
+	return tickets, nil
+}
+
+func (uc *ticketUsecase) TicketQRCode(ctx context.Context, bookingID int64, ticketID string) ([]byte, error) {
+	t, err := uc.ticketRepo.GetByID(ctx, ticketID)
+	if err != nil {
+		return nil, err
+	}
+	if t.BookingID != bookingID {
+		return nil, apperrors.ErrTicketNotFound
+	}
+	if t.Status != entity.TicketIssued {
+		return nil, apperrors.ErrTicketRevoked
+	}
+
+	return qrcode.Encode(t.Token, qrcode.Medium, uc.qrSize)
+}
+
+func (uc *ticketUsecase) CheckIn(ctx context.Context, checkIn *entity.CheckIn) (*entity.CheckInResult, error) {
+	if checkIn.RouteID <= 0 || checkIn.DepartureAt.IsZero() {
+		return nil, apperrors.ErrInvalidCheckIn
+	}
+
+	claims, err := ticket.Verify(strings.TrimSpace(checkIn.Token), uc.verifyKeys...)
+	switch {
+	case errors.Is(err, ticket.ErrInvalidSignature):
+		return &entity.CheckInResult{Reason: entity.CheckInInvalidSignature}, nil
+	case err != nil:
+		return &entity.CheckInResult{Reason: entity.CheckInMalformedToken}, nil
+	}
+
+	result := &entity.CheckInResult{TicketID: claims.TicketID, BookingID: claims.BookingID, Seat: claims.Seat}
+	if claims.PassengerID != 0 {
+		result.PassengerID = &claims.PassengerID
+	}
+	// Tickets of bookings without a departure time are good for any
+	// departure of their route
+	if claims.RouteID != checkIn.RouteID ||
+		(claims.DepartureAt != 0 && claims.DepartureAt != checkIn.DepartureAt.Unix()) {
+		result.Reason = entity.CheckInWrongDeparture
+		return result, nil
+	}
+
+	gate := checkIn.Gate
+	if gate == "" {
+		gate = reqctx.Actor(ctx)
+	}
+	used, err := uc.ticketRepo.MarkUsed(ctx, claims.TicketID, time.Now(), gate)
+	if errors.Is(err, apperrors.ErrTicketNotFound) {
+		// Work out why the ticket could not be used
+		used, err = uc.ticketRepo.GetByID(ctx, claims.TicketID)
+		switch {
+		case errors.Is(err, apperrors.ErrTicketNotFound):
+			result.Reason = entity.CheckInUnknownTicket
+			return result, nil
+		case err != nil:
+			return nil, err
+		case used.Status == entity.TicketRevoked:
+			result.Reason = entity.CheckInRevoked
+		case used.UsedAt != nil:
+			result.Reason = entity.CheckInAlreadyUsed
+		default:
+			result.Reason = entity.CheckInNotConfirmed
+		}
+		fillCheckIn(result, used)
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Allowed = true
+	result.Reason = entity.CheckInOK
+	fillCheckIn(result, used)
+	return result, nil
+}
+
+func (uc *ticketUsecase) VerificationKeys() []ed25519.PublicKey {
+	return uc.verifyKeys
+}
+
+func fillCheckIn(result *entity.CheckInResult, t *entity.Ticket) {
+	result.HolderName = t.HolderName
+	result.Seat = t.Seat
+	result.UsedAt = t.UsedAt
+	result.UsedBy = t.UsedBy
+}
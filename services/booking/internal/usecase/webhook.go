@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/webhook"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/booking/internal/domain/service"
+)
+
+type webhookUsecase struct {
+	subscriptionRepo repository.WebhookSubscriptionRepository
+	deliveryRepo     repository.WebhookDeliveryRepository
+	bookingRepo      repository.BookingRepository
+}
+
+func NewWebhookUsecase(
+	subscriptionRepo repository.WebhookSubscriptionRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	bookingRepo repository.BookingRepository,
+) service.WebhookService {
+	return &webhookUsecase{
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		bookingRepo:      bookingRepo,
+	}
+}
+
+func (uc *webhookUsecase) Publish(ctx context.Context, event *entity.OutboxEvent) error {
+	if !entity.ValidWebhookEventType(event.EventType) {
+		return nil
+	}
+
+	// Subscriptions only hear of their own tenant's bookings, so events of a
+	// booking that is gone reach none
+	booking, err := uc.bookingRepo.GetByID(ctx, event.AggregateID, repository.IncludeDeleted())
+	if errors.Is(err, apperrors.ErrBookingNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	subscriptions, err := uc.subscriptionRepo.ListActive(ctx, booking.TenantID, event.EventType)
+	if err != nil || len(subscriptions) == 0 {
+		return err
+	}
+
+	payload, err := json.Marshal(&entity.WebhookEnvelope{
+		ID:        event.ID,
+		Type:      event.EventType,
+		BookingID: event.AggregateID,
+		TenantID:  booking.TenantID,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		if subscription.UserID != nil && *subscription.UserID != booking.UserID {
+			continue
+		}
+
+		err := uc.deliveryRepo.Enqueue(ctx, &entity.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.EventType,
+			BookingID:      event.AggregateID,
+			Payload:        payload,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (uc *webhookUsecase) CreateSubscription(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	subscription.Partner = strings.TrimSpace(subscription.Partner)
+	if subscription.Partner == "" || !webhook.ValidURL(subscription.URL) || len(subscription.EventTypes) == 0 {
+		return apperrors.ErrInvalidWebhookSubscription
+	}
+	for _, eventType := range subscription.EventTypes {
+		if !entity.ValidWebhookEventType(eventType) {
+			return apperrors.ErrInvalidWebhookSubscription
+		}
+	}
+
+	if subscription.Secret == "" {
+		secret := make([]byte, 24)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		subscription.Secret = "whsec_" + hex.EncodeToString(secret)
+	}
+
+	subscription.TenantID = tenantOf(ctx)
+	subscription.Active = true
+	subscription.CreatedAt = time.Now()
+	subscription.UpdatedAt = subscription.CreatedAt
+
+	return uc.subscriptionRepo.Create(ctx, subscription)
+}
+
+func (uc *webhookUsecase) GetSubscription(ctx context.Context, id int64) (*entity.WebhookSubscription, error) {
+	subscription, err := uc.subscriptionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+func (uc *webhookUsecase) ListSubscriptions(ctx context.Context, limit, offset int) ([]*entity.WebhookSubscription, error) {
+	limit, offset = clampPage(limit, offset)
+
+	subscriptions, err := uc.subscriptionRepo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subscription := range subscriptions {
+		subscription.Secret = ""
+	}
+	return subscriptions, nil
+}
+
+func (uc *webhookUsecase) DeleteSubscription(ctx context.Context, id int64) error {
+	return uc.subscriptionRepo.Deactivate(ctx, id)
+}
+
+func (uc *webhookUsecase) ListDeliveries(ctx context.Context, filter entity.WebhookDeliveryFilter, limit, offset int) ([]*entity.WebhookDelivery, error) {
+	limit, offset = clampPage(limit, offset)
+	return uc.deliveryRepo.List(ctx, filter, limit, offset)
+}
+
+func (uc *webhookUsecase) GetDelivery(ctx context.Context, id int64) (*entity.WebhookDelivery, error) {
+	return uc.deliveryRepo.GetByID(ctx, id)
+}
+
+func (uc *webhookUsecase) Redeliver(ctx context.Context, id int64) (*entity.WebhookDelivery, error) {
+	if err := uc.deliveryRepo.Redeliver(ctx, id); err != nil {
+		return nil, err
+	}
+	return uc.deliveryRepo.GetByID(ctx, id)
+}
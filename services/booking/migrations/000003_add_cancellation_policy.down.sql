@@ -0,0 +1,10 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS cancel_reason,
+    DROP COLUMN IF EXISTS departure_at;
+
+UPDATE bookings SET status = 'EXPIRED' WHERE status IN ('CANCELLED','REFUNDED');
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+ALTER TABLE bookings ADD CONSTRAINT bookings_status_check
+    CHECK (status IN ('CREATED','PAID','CONFIRMED','EXPIRED'));
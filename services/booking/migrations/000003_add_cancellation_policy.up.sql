@@ -0,0 +1,8 @@
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_status_check;
+ALTER TABLE bookings ADD CONSTRAINT bookings_status_check
+    CHECK (status IN ('CREATED','PAID','CONFIRMED','EXPIRED','CANCELLED','REFUNDED'));
+
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS departure_at  TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS cancel_reason TEXT,
+    ADD COLUMN IF NOT EXISTS cancelled_at  TIMESTAMPTZ;
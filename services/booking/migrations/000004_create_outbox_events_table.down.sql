@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_outbox_events_unpublished;
+DROP TABLE IF EXISTS outbox_events;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id             BIGSERIAL PRIMARY KEY,
+    aggregate_type TEXT   NOT NULL,
+    aggregate_id   BIGINT NOT NULL,
+    event_type     TEXT   NOT NULL,
+    payload        JSONB  NOT NULL DEFAULT '{}'::jsonb,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    published_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unpublished ON outbox_events(id) WHERE published_at IS NULL;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS bookings_archive;
+DROP INDEX IF EXISTS idx_bookings_live_created_at;
+ALTER TABLE bookings DROP COLUMN IF EXISTS deleted_at;
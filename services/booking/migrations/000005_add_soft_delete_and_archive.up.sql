@@ -0,0 +1,20 @@
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_live_created_at ON bookings(created_at DESC) WHERE deleted_at IS NULL;
+
+-- Archived bookings keep their key columns for lookups plus a full JSON
+-- snapshot of the row, so the archive survives later changes to bookings.
+-- Monthly partitions are created on demand by the archival job.
+CREATE TABLE IF NOT EXISTS bookings_archive (
+    id          BIGINT NOT NULL,
+    user_id     BIGINT NOT NULL,
+    route_id    BIGINT NOT NULL,
+    status      TEXT   NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL,
+    deleted_at  TIMESTAMPTZ,
+    archived_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    data        JSONB  NOT NULL,
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_archive_user_id ON bookings_archive(user_id);
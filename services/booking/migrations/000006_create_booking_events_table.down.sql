@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_booking_events_booking_id;
+DROP TABLE IF EXISTS booking_events;
@@ -0,0 +1,15 @@
+-- Append-only audit trail. There is deliberately no foreign key to bookings so
+-- history outlives archival of the booking row.
+CREATE TABLE IF NOT EXISTS booking_events (
+    id         BIGSERIAL PRIMARY KEY,
+    booking_id BIGINT NOT NULL,
+    action     TEXT   NOT NULL,
+    old_status TEXT,
+    new_status TEXT   NOT NULL,
+    changes    JSONB  NOT NULL DEFAULT '{}'::jsonb,
+    actor      TEXT   NOT NULL,
+    request_id TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_events_booking_id ON booking_events(booking_id, id);
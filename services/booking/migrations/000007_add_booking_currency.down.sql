@@ -0,0 +1,2 @@
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_price_total_check;
+ALTER TABLE bookings DROP COLUMN IF EXISTS currency;
@@ -0,0 +1,9 @@
+-- price_total is stored in minor units of the booking currency (ISO 4217).
+-- Bookings created before multi-currency support were priced in IDR.
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'IDR'
+        CONSTRAINT bookings_currency_check CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE bookings ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE bookings ADD CONSTRAINT bookings_price_total_check CHECK (price_total >= 0);
@@ -0,0 +1,16 @@
+-- Payment provider notifications. The primary key dedupes redeliveries of the
+-- same provider event.
+CREATE TABLE IF NOT EXISTS payment_events (
+    provider    TEXT        NOT NULL,
+    event_id    TEXT        NOT NULL,
+    event_type  TEXT        NOT NULL,
+    booking_id  BIGINT      NOT NULL,
+    amount      TEXT        NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL,
+    outcome     TEXT        NOT NULL CHECK (outcome IN ('APPLIED', 'IGNORED', 'REJECTED')),
+    payload     JSONB       NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (provider, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_events_booking_id ON payment_events(booking_id);
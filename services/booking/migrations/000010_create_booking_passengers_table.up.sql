@@ -0,0 +1,18 @@
+-- Travellers on a booking, kept for ferry/bus manifests. Cancelled passengers
+-- stay on the booking with status CANCELLED. Archival snapshots the passengers
+-- into the archived booking before the cascade removes them.
+CREATE TABLE IF NOT EXISTS booking_passengers (
+    id              BIGSERIAL PRIMARY KEY,
+    booking_id      BIGINT      NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    full_name       TEXT        NOT NULL,
+    document_type   TEXT        NOT NULL,
+    document_number TEXT        NOT NULL,
+    date_of_birth   DATE        NOT NULL,
+    seat            TEXT,
+    status          TEXT        NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'CANCELLED')),
+    cancel_reason   TEXT,
+    cancelled_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_passengers_booking_id ON booking_passengers(booking_id, id);
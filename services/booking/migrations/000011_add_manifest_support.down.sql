@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_bookings_departure;
+ALTER TABLE booking_passengers DROP COLUMN IF EXISTS checked_in_at;
@@ -0,0 +1,6 @@
+-- Manifests list the confirmed bookings of one departure; checked_in_at is set
+-- when the passenger boards.
+ALTER TABLE booking_passengers ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_bookings_departure ON bookings(route_id, departure_at)
+    WHERE status = 'CONFIRMED' AND deleted_at IS NULL;
@@ -0,0 +1,18 @@
+-- One ticket per passenger (or per seat of a booking without passenger
+-- details), issued when the booking is confirmed. seq numbers the tickets of a
+-- booking so reissuing after an outbox replay is a no-op.
+CREATE TABLE IF NOT EXISTS tickets (
+    id           TEXT PRIMARY KEY,
+    booking_id   BIGINT      NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    seq          INT         NOT NULL,
+    passenger_id BIGINT REFERENCES booking_passengers(id) ON DELETE CASCADE,
+    holder_name  TEXT,
+    seat         TEXT,
+    status       TEXT        NOT NULL DEFAULT 'ISSUED' CHECK (status IN ('ISSUED', 'REVOKED')),
+    token        TEXT        NOT NULL,
+    issued_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at   TIMESTAMPTZ,
+    UNIQUE (booking_id, seq)
+);
+
+CREATE INDEX IF NOT EXISTS idx_tickets_passenger_id ON tickets(passenger_id) WHERE passenger_id IS NOT NULL;
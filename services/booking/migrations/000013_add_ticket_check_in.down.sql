@@ -0,0 +1,3 @@
+ALTER TABLE tickets
+    DROP COLUMN IF EXISTS used_by,
+    DROP COLUMN IF EXISTS used_at;
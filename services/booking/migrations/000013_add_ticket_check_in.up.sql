@@ -0,0 +1,5 @@
+-- A ticket is used once: the first accepted scan sets used_at and the gate
+-- that scanned it.
+ALTER TABLE tickets
+    ADD COLUMN IF NOT EXISTS used_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS used_by TEXT;
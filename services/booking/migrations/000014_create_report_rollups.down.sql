@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_outbox_events_refunds;
+DROP INDEX IF EXISTS idx_bookings_updated_at;
+DROP TABLE IF EXISTS report_refresh_state;
+DROP TABLE IF EXISTS report_booking_rollups;
@@ -0,0 +1,29 @@
+-- Hourly rollup of bookings by creation hour (UTC), route, currency and
+-- current status. Reports aggregate it into local days, weeks or months;
+-- hourly buckets keep that exact for every whole-hour UTC offset.
+CREATE TABLE IF NOT EXISTS report_booking_rollups (
+    bucket_start TIMESTAMPTZ NOT NULL,
+    route_id     BIGINT      NOT NULL,
+    currency     CHAR(3)     NOT NULL,
+    status       TEXT        NOT NULL,
+    bookings     INT         NOT NULL,
+    seats        INT         NOT NULL,
+    paid         INT         NOT NULL,
+    confirmed    INT         NOT NULL,
+    revenue      BIGINT      NOT NULL,
+    refunds      BIGINT      NOT NULL,
+    refreshed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (bucket_start, route_id, currency, status)
+);
+
+CREATE INDEX IF NOT EXISTS idx_report_booking_rollups_route ON report_booking_rollups(route_id, bucket_start);
+
+-- Single row holding how far the rollups are up to date
+CREATE TABLE IF NOT EXISTS report_refresh_state (
+    id              BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    refreshed_until TIMESTAMPTZ NOT NULL
+);
+
+-- Finding bookings changed since the last refresh, and their refunds
+CREATE INDEX IF NOT EXISTS idx_bookings_updated_at ON bookings(updated_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_refunds ON outbox_events(aggregate_id) WHERE event_type = 'booking.refund_requested';
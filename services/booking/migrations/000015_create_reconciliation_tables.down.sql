@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_booking_events_paid;
+DROP TABLE IF EXISTS reconciliation_runs;
+DROP TABLE IF EXISTS reconciliation_discrepancies;
+DROP TABLE IF EXISTS settlements;
+DROP TABLE IF EXISTS settlement_files;
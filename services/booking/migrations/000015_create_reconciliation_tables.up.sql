@@ -0,0 +1,60 @@
+-- Provider settlement files, recorded once per content so re-reading the
+-- settlement directory ingests nothing twice.
+CREATE TABLE IF NOT EXISTS settlement_files (
+    id          BIGSERIAL PRIMARY KEY,
+    provider    TEXT        NOT NULL,
+    name        TEXT        NOT NULL,
+    checksum    TEXT        NOT NULL UNIQUE,
+    rows        INT         NOT NULL,
+    ingested_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- One settled payment per line. Amounts use the "<currency> <minor units>"
+-- form of payment_events so the two compare directly.
+CREATE TABLE IF NOT EXISTS settlements (
+    id          BIGSERIAL PRIMARY KEY,
+    file_id     BIGINT      NOT NULL REFERENCES settlement_files(id),
+    line        INT         NOT NULL,
+    provider    TEXT        NOT NULL,
+    reference   TEXT        NOT NULL,
+    booking_id  BIGINT,
+    amount      TEXT        NOT NULL,
+    settled_at  TIMESTAMPTZ,
+    ingested_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_settlements_reference ON settlements(provider, reference);
+CREATE INDEX IF NOT EXISTS idx_settlements_booking_id ON settlements(booking_id);
+CREATE INDEX IF NOT EXISTS idx_settlements_ingested_at ON settlements(ingested_at);
+
+CREATE TABLE IF NOT EXISTS reconciliation_discrepancies (
+    id            BIGSERIAL PRIMARY KEY,
+    kind          TEXT        NOT NULL CHECK (kind IN ('UNSETTLED_PAYMENT', 'UNMATCHED_SETTLEMENT', 'AMOUNT_MISMATCH', 'DUPLICATE_SETTLEMENT')),
+    provider      TEXT,
+    reference     TEXT,
+    booking_id    BIGINT,
+    settlement_id BIGINT REFERENCES settlements(id),
+    expected      TEXT,
+    settled       TEXT,
+    detected_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    resolved_at   TIMESTAMPTZ
+);
+
+-- At most one open discrepancy of a kind per settlement and booking
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reconciliation_discrepancies_open
+    ON reconciliation_discrepancies(kind, COALESCE(settlement_id, 0), COALESCE(booking_id, 0))
+    WHERE resolved_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS reconciliation_runs (
+    id          BIGSERIAL PRIMARY KEY,
+    started_at  TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL,
+    files       INT         NOT NULL,
+    settlements INT         NOT NULL,
+    opened      INT         NOT NULL,
+    resolved    INT         NOT NULL,
+    errors      TEXT[]      NOT NULL DEFAULT '{}'
+);
+
+-- Finding bookings paid within the reconciliation window
+CREATE INDEX IF NOT EXISTS idx_booking_events_paid ON booking_events(created_at) WHERE new_status = 'PAID';
@@ -0,0 +1,74 @@
+-- Double-entry ledger. Amounts are signed minor units: debits positive,
+-- credits negative, so every entry sums to zero per currency.
+CREATE TABLE IF NOT EXISTS ledger_accounts (
+    code       TEXT        PRIMARY KEY,
+    type       TEXT        NOT NULL CHECK (type IN ('ASSET', 'LIABILITY', 'EQUITY', 'REVENUE', 'EXPENSE')),
+    name       TEXT        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id          BIGSERIAL PRIMARY KEY,
+    key         TEXT        NOT NULL UNIQUE,
+    kind        TEXT        NOT NULL,
+    booking_id  BIGINT,
+    description TEXT        NOT NULL DEFAULT '',
+    occurred_at TIMESTAMPTZ NOT NULL,
+    posted_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_booking_id ON ledger_entries(booking_id);
+
+CREATE TABLE IF NOT EXISTS ledger_postings (
+    id       BIGSERIAL PRIMARY KEY,
+    entry_id BIGINT  NOT NULL REFERENCES ledger_entries(id),
+    account  TEXT    NOT NULL REFERENCES ledger_accounts(code),
+    amount   BIGINT  NOT NULL CHECK (amount <> 0),
+    currency CHAR(3) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_entry_id ON ledger_postings(entry_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_postings_account ON ledger_postings(account, currency);
+
+-- Posted entries are never changed; mistakes are corrected by reversal
+CREATE OR REPLACE FUNCTION ledger_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION '% is append-only, post a reversing entry instead', TG_TABLE_NAME;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER ledger_entries_immutable
+    BEFORE UPDATE OR DELETE ON ledger_entries
+    FOR EACH ROW EXECUTE FUNCTION ledger_immutable();
+CREATE TRIGGER ledger_entries_no_truncate
+    BEFORE TRUNCATE ON ledger_entries
+    FOR EACH STATEMENT EXECUTE FUNCTION ledger_immutable();
+CREATE TRIGGER ledger_postings_immutable
+    BEFORE UPDATE OR DELETE ON ledger_postings
+    FOR EACH ROW EXECUTE FUNCTION ledger_immutable();
+CREATE TRIGGER ledger_postings_no_truncate
+    BEFORE TRUNCATE ON ledger_postings
+    FOR EACH STATEMENT EXECUTE FUNCTION ledger_immutable();
+
+-- Checked at commit, once all postings of an entry are in
+CREATE OR REPLACE FUNCTION ledger_check_balanced() RETURNS trigger AS $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM ledger_postings
+        WHERE entry_id = NEW.entry_id
+        GROUP BY currency
+        HAVING sum(amount) <> 0
+    ) THEN
+        RAISE EXCEPTION 'ledger entry % does not balance', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_postings_balanced
+    AFTER INSERT ON ledger_postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION ledger_check_balanced();
+
+-- Processing fee the provider kept from a settled payment
+ALTER TABLE settlements ADD COLUMN IF NOT EXISTS fee TEXT;
@@ -0,0 +1,5 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS discount,
+    DROP COLUMN IF EXISTS promo_code;
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,38 @@
+-- Campaign codes. Codes are stored upper-case; an empty route_ids applies to
+-- every route. redemptions counts bookings holding the code.
+CREATE TABLE IF NOT EXISTS promotions (
+    id              BIGSERIAL PRIMARY KEY,
+    code            TEXT        NOT NULL UNIQUE,
+    description     TEXT        NOT NULL DEFAULT '',
+    kind            TEXT        NOT NULL CHECK (kind IN ('PERCENT', 'FIXED')),
+    percent         INT         CHECK (percent BETWEEN 1 AND 100),
+    amount          BIGINT      CHECK (amount > 0),
+    currency        CHAR(3),
+    route_ids       BIGINT[]    NOT NULL DEFAULT '{}',
+    min_qty         INT         NOT NULL DEFAULT 1,
+    max_redemptions INT,
+    max_per_user    INT,
+    redemptions     INT         NOT NULL DEFAULT 0,
+    starts_at       TIMESTAMPTZ NOT NULL,
+    ends_at         TIMESTAMPTZ,
+    disabled        BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    id           BIGSERIAL PRIMARY KEY,
+    promotion_id BIGINT      NOT NULL REFERENCES promotions(id),
+    booking_id   BIGINT      NOT NULL UNIQUE,
+    user_id      BIGINT      NOT NULL,
+    discount     BIGINT      NOT NULL,
+    currency     CHAR(3)     NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_user ON promotion_redemptions(promotion_id, user_id);
+
+-- price_total is what is due after the discount, in the booking currency
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS promo_code TEXT,
+    ADD COLUMN IF NOT EXISTS discount   BIGINT;
@@ -0,0 +1,16 @@
+DROP POLICY IF EXISTS tenant_isolation ON promotions;
+ALTER TABLE promotions NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE promotions DISABLE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON routes;
+DROP POLICY IF EXISTS tenant_isolation ON bookings;
+ALTER TABLE bookings NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE bookings DISABLE ROW LEVEL SECURITY;
+
+DROP INDEX IF EXISTS idx_promotions_tenant_code;
+ALTER TABLE promotions DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE promotions ADD CONSTRAINT promotions_code_key UNIQUE (code);
+
+DROP INDEX IF EXISTS idx_bookings_tenant;
+ALTER TABLE bookings DROP COLUMN IF EXISTS tenant_id;
+DROP TABLE IF EXISTS routes;
+DROP TABLE IF EXISTS tenants;
@@ -0,0 +1,53 @@
+-- Operators sharing the platform. settings holds per-tenant overrides of the
+-- service configuration.
+CREATE TABLE IF NOT EXISTS tenants (
+    id         TEXT        PRIMARY KEY CHECK (id ~ '^[a-z0-9][a-z0-9-]{1,62}$'),
+    name       TEXT        NOT NULL,
+    settings   JSONB       NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Everything that predates tenants belongs to the default tenant
+INSERT INTO tenants (id, name) VALUES ('default', 'Default') ON CONFLICT (id) DO NOTHING;
+
+-- Routes are operated by one tenant, which registers them or books them first
+CREATE TABLE IF NOT EXISTS routes (
+    id         BIGINT      PRIMARY KEY,
+    tenant_id  TEXT        NOT NULL REFERENCES tenants(id),
+    name       TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_routes_tenant ON routes(tenant_id, id);
+
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+CREATE INDEX IF NOT EXISTS idx_bookings_tenant ON bookings(tenant_id, created_at);
+
+INSERT INTO routes (id, tenant_id)
+SELECT DISTINCT route_id, 'default' FROM bookings
+ON CONFLICT (id) DO NOTHING;
+
+-- Promotion codes are unique per tenant
+ALTER TABLE promotions ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+ALTER TABLE promotions DROP CONSTRAINT IF EXISTS promotions_code_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_promotions_tenant_code ON promotions(tenant_id, code);
+
+-- Backstop for the tenant predicates of the repositories: a transaction that
+-- set app.tenant_id only sees and writes that tenant's rows. Without the
+-- setting, as in background jobs, every row is visible. FORCE applies the
+-- policies to the table owner too; superusers still bypass them.
+ALTER TABLE bookings ENABLE ROW LEVEL SECURITY;
+ALTER TABLE bookings FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON bookings
+    USING (COALESCE(current_setting('app.tenant_id', true), '') IN ('', tenant_id));
+
+ALTER TABLE routes ENABLE ROW LEVEL SECURITY;
+ALTER TABLE routes FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON routes
+    USING (COALESCE(current_setting('app.tenant_id', true), '') IN ('', tenant_id));
+
+ALTER TABLE promotions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE promotions FORCE ROW LEVEL SECURITY;
+CREATE POLICY tenant_isolation ON promotions
+    USING (COALESCE(current_setting('app.tenant_id', true), '') IN ('', tenant_id));
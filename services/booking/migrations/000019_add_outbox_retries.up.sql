@@ -0,0 +1,14 @@
+-- Events are appended in the transaction of the change they describe and
+-- published afterwards by the outbox relay (job.OutboxRelay), which retries
+-- the publishers that failed.
+ALTER TABLE outbox_events
+    -- Publishers that handled the event; a retry only runs the others
+    ADD COLUMN IF NOT EXISTS published_to    TEXT[]      NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS attempts        INT         NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS last_error      TEXT,
+    -- Set once the event failed OUTBOX_MAX_ATTEMPTS times; it waits for a replay
+    ADD COLUMN IF NOT EXISTS dead_at         TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS idx_outbox_events_unpublished;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unpublished ON outbox_events(id) WHERE published_at IS NULL AND dead_at IS NULL;
@@ -0,0 +1,24 @@
+ALTER TABLE reconciliation_discrepancies
+    ALTER COLUMN expected TYPE TEXT USING expected_currency || ' ' || expected,
+    ALTER COLUMN settled  TYPE TEXT USING settled_currency || ' ' || settled;
+ALTER TABLE reconciliation_discrepancies
+    DROP COLUMN IF EXISTS settled_currency,
+    DROP COLUMN IF EXISTS expected_currency;
+
+ALTER TABLE settlements
+    ALTER COLUMN fee DROP NOT NULL,
+    ALTER COLUMN fee DROP DEFAULT,
+    ALTER COLUMN fee TYPE TEXT USING currency || ' ' || fee,
+    ALTER COLUMN amount TYPE TEXT USING currency || ' ' || amount;
+ALTER TABLE settlements DROP COLUMN IF EXISTS currency;
+
+ALTER TABLE payment_events ALTER COLUMN amount TYPE TEXT USING currency || ' ' || amount;
+ALTER TABLE payment_events DROP COLUMN IF EXISTS currency;
+
+UPDATE bookings b SET price_total = price_total / 100
+WHERE currency = 'IDR'
+  AND NOT EXISTS (
+      SELECT 1 FROM booking_events e
+      WHERE e.booking_id = b.id AND e.action = 'CREATED'
+        AND jsonb_typeof(e.changes->'price_total'->'new') = 'object'
+  );
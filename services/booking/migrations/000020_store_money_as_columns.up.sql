@@ -0,0 +1,44 @@
+-- Bookings created before multi-currency support were priced in whole IDR,
+-- which has two minor units, so their prices and archived snapshots are
+-- scaled to sen. Those bookings have no history or a CREATED entry with a
+-- plain number for price_total; later ones recorded an amount and currency.
+-- Outbox and history payloads keep the amounts they had.
+UPDATE bookings b SET price_total = price_total * 100
+WHERE currency = 'IDR'
+  AND NOT EXISTS (
+      SELECT 1 FROM booking_events e
+      WHERE e.booking_id = b.id AND e.action = 'CREATED'
+        AND jsonb_typeof(e.changes->'price_total'->'new') = 'object'
+  );
+
+UPDATE bookings_archive
+SET data = jsonb_set(data, '{price_total}', to_jsonb((data->>'price_total')::BIGINT * 100)) || '{"currency": "IDR"}'
+WHERE NOT data ? 'currency';
+
+-- Amounts were stored as "<currency> <minor units>" text; they become minor
+-- units of a separate currency column, as in bookings.
+ALTER TABLE payment_events ADD COLUMN IF NOT EXISTS currency CHAR(3);
+UPDATE payment_events SET currency = split_part(amount, ' ', 1);
+ALTER TABLE payment_events
+    ALTER COLUMN currency SET NOT NULL,
+    ALTER COLUMN amount TYPE BIGINT USING split_part(amount, ' ', 2)::BIGINT;
+
+ALTER TABLE settlements ADD COLUMN IF NOT EXISTS currency CHAR(3);
+UPDATE settlements SET currency = split_part(amount, ' ', 1);
+ALTER TABLE settlements
+    ALTER COLUMN currency SET NOT NULL,
+    ALTER COLUMN amount TYPE BIGINT USING split_part(amount, ' ', 2)::BIGINT,
+    -- Processing fee the provider kept, in minor units of currency
+    ALTER COLUMN fee TYPE BIGINT USING COALESCE(NULLIF(split_part(fee, ' ', 2), '')::BIGINT, 0),
+    ALTER COLUMN fee SET DEFAULT 0,
+    ALTER COLUMN fee SET NOT NULL;
+
+ALTER TABLE reconciliation_discrepancies
+    ADD COLUMN IF NOT EXISTS expected_currency CHAR(3),
+    ADD COLUMN IF NOT EXISTS settled_currency  CHAR(3);
+UPDATE reconciliation_discrepancies
+SET expected_currency = NULLIF(split_part(expected, ' ', 1), ''),
+    settled_currency  = NULLIF(split_part(settled, ' ', 1), '');
+ALTER TABLE reconciliation_discrepancies
+    ALTER COLUMN expected TYPE BIGINT USING NULLIF(split_part(expected, ' ', 2), '')::BIGINT,
+    ALTER COLUMN settled  TYPE BIGINT USING NULLIF(split_part(settled, ' ', 2), '')::BIGINT;
@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS bookings_booked_seats ON bookings;
+DROP TRIGGER IF EXISTS booking_passengers_booked_seats ON booking_passengers;
+DROP TABLE IF EXISTS booked_seats;
+DROP FUNCTION IF EXISTS sync_booked_seats();
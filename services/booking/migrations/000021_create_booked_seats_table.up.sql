@@ -0,0 +1,57 @@
+-- One claim per seat of an active passenger on a live booking, so no two
+-- bookings share a seat on a departure. Triggers keep the claims in step with
+-- passengers and bookings, whichever path changes them.
+CREATE TABLE IF NOT EXISTS booked_seats (
+    passenger_id BIGINT      PRIMARY KEY REFERENCES booking_passengers(id) ON DELETE CASCADE,
+    route_id     BIGINT      NOT NULL,
+    departure_at TIMESTAMPTZ,
+    seat         TEXT        NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_booked_seats_seat
+    ON booked_seats(route_id, COALESCE(departure_at, '-infinity'), seat);
+
+CREATE OR REPLACE FUNCTION sync_booked_seats() RETURNS trigger AS $$
+DECLARE
+    target BIGINT;
+BEGIN
+    IF TG_TABLE_NAME = 'bookings' THEN
+        target := NEW.id;
+    ELSE
+        target := NEW.booking_id;
+    END IF;
+
+    DELETE FROM booked_seats
+    WHERE passenger_id IN (SELECT id FROM booking_passengers WHERE booking_id = target);
+
+    INSERT INTO booked_seats (passenger_id, route_id, departure_at, seat)
+    SELECT p.id, b.route_id, b.departure_at, p.seat
+    FROM booking_passengers p
+    JOIN bookings b ON b.id = p.booking_id
+    WHERE p.booking_id = target AND p.status = 'ACTIVE' AND p.seat IS NOT NULL
+      AND b.deleted_at IS NULL AND b.status NOT IN ('EXPIRED', 'CANCELLED', 'REFUNDED');
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS booking_passengers_booked_seats ON booking_passengers;
+CREATE TRIGGER booking_passengers_booked_seats
+    AFTER INSERT OR UPDATE OF status, seat ON booking_passengers
+    FOR EACH ROW EXECUTE FUNCTION sync_booked_seats();
+
+DROP TRIGGER IF EXISTS bookings_booked_seats ON bookings;
+CREATE TRIGGER bookings_booked_seats
+    AFTER UPDATE OF status, route_id, departure_at, deleted_at ON bookings
+    FOR EACH ROW EXECUTE FUNCTION sync_booked_seats();
+
+-- Claim the seats of existing bookings. Where two already share a seat the
+-- older passenger keeps it.
+INSERT INTO booked_seats (passenger_id, route_id, departure_at, seat)
+SELECT p.id, b.route_id, b.departure_at, p.seat
+FROM booking_passengers p
+JOIN bookings b ON b.id = p.booking_id
+WHERE p.status = 'ACTIVE' AND p.seat IS NOT NULL
+  AND b.deleted_at IS NULL AND b.status NOT IN ('EXPIRED', 'CANCELLED', 'REFUNDED')
+ORDER BY p.id
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,25 @@
+DROP POLICY IF EXISTS tenant_isolation ON webhook_subscriptions;
+ALTER TABLE webhook_subscriptions NO FORCE ROW LEVEL SECURITY;
+ALTER TABLE webhook_subscriptions DISABLE ROW LEVEL SECURITY;
+
+DROP POLICY IF EXISTS tenant_isolation ON promotions;
+CREATE POLICY tenant_isolation ON promotions
+    USING (COALESCE(current_setting('app.tenant_id', true), '') IN ('', tenant_id));
+
+DROP POLICY IF EXISTS tenant_isolation ON routes;
+CREATE POLICY tenant_isolation ON routes
+    USING (COALESCE(current_setting('app.tenant_id', true), '') IN ('', tenant_id));
+
+DROP POLICY IF EXISTS tenant_isolation ON bookings;
+CREATE POLICY tenant_isolation ON bookings
+    USING (COALESCE(current_setting('app.tenant_id', true), '') IN ('', tenant_id));
+
+DROP INDEX IF EXISTS idx_webhook_subscriptions_tenant;
+ALTER TABLE webhook_subscriptions DROP COLUMN IF EXISTS tenant_id;
+
+DELETE FROM report_booking_rollups;
+DELETE FROM report_refresh_state;
+DROP INDEX IF EXISTS idx_report_booking_rollups_tenant;
+ALTER TABLE report_booking_rollups DROP CONSTRAINT IF EXISTS report_booking_rollups_pkey;
+ALTER TABLE report_booking_rollups DROP COLUMN IF EXISTS tenant_id;
+ALTER TABLE report_booking_rollups ADD PRIMARY KEY (bucket_start, route_id, currency, status);
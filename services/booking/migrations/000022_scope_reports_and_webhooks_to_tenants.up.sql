@@ -0,0 +1,35 @@
+-- Reports are rolled up per tenant. The existing rollups mixed tenants, so
+-- they are dropped together with the refresh state and the next refresh
+-- rebuilds them.
+DELETE FROM report_booking_rollups;
+DELETE FROM report_refresh_state;
+ALTER TABLE report_booking_rollups ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+ALTER TABLE report_booking_rollups DROP CONSTRAINT IF EXISTS report_booking_rollups_pkey;
+ALTER TABLE report_booking_rollups ADD PRIMARY KEY (bucket_start, tenant_id, route_id, currency, status);
+CREATE INDEX IF NOT EXISTS idx_report_booking_rollups_tenant ON report_booking_rollups(tenant_id, bucket_start);
+
+-- Webhook subscriptions belong to the tenant that made them, and receive only
+-- its bookings' events. Existing subscriptions go to the default tenant.
+ALTER TABLE webhook_subscriptions ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default' REFERENCES tenants(id);
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_tenant ON webhook_subscriptions(tenant_id) WHERE active;
+
+-- A connection that set app.tenant_id only sees and writes that tenant's
+-- rows, and platform jobs set it to '*' to see every tenant's. Unset or
+-- empty, it now sees none, where before it saw every row.
+DROP POLICY IF EXISTS tenant_isolation ON bookings;
+CREATE POLICY tenant_isolation ON bookings
+    USING (current_setting('app.tenant_id', true) IN ('*', tenant_id));
+
+DROP POLICY IF EXISTS tenant_isolation ON routes;
+CREATE POLICY tenant_isolation ON routes
+    USING (current_setting('app.tenant_id', true) IN ('*', tenant_id));
+
+DROP POLICY IF EXISTS tenant_isolation ON promotions;
+CREATE POLICY tenant_isolation ON promotions
+    USING (current_setting('app.tenant_id', true) IN ('*', tenant_id));
+
+ALTER TABLE webhook_subscriptions ENABLE ROW LEVEL SECURITY;
+ALTER TABLE webhook_subscriptions FORCE ROW LEVEL SECURITY;
+DROP POLICY IF EXISTS tenant_isolation ON webhook_subscriptions;
+CREATE POLICY tenant_isolation ON webhook_subscriptions
+    USING (current_setting('app.tenant_id', true) IN ('*', tenant_id));
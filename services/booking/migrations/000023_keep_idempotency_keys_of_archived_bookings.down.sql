@@ -0,0 +1,5 @@
+-- Keys of archived bookings have no booking to reference any more
+SELECT set_config('app.tenant_id', '*', false);
+DELETE FROM idempotency_keys k WHERE NOT EXISTS (SELECT 1 FROM bookings b WHERE b.id = k.booking_id);
+ALTER TABLE idempotency_keys
+    ADD CONSTRAINT idempotency_keys_booking_id_fkey FOREIGN KEY (booking_id) REFERENCES bookings(id) ON DELETE CASCADE;
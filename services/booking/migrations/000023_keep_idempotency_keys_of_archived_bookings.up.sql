@@ -0,0 +1,4 @@
+-- Archival moves bookings out of the live table. Their idempotency keys stay,
+-- so a retried request still finds the archived booking instead of creating
+-- another one.
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_booking_id_fkey;
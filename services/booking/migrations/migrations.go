@@ -0,0 +1,10 @@
+// Package migrations embeds the booking service SQL migrations so they can be
+// applied by tooling and tests without relying on the working directory.
+package migrations
+
+import "embed"
+
+// FS holds the golang-migrate style *.up.sql / *.down.sql files.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ibnuzaman/porta-pay/pkg/client/booking"
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	"github.com/ibnuzaman/porta-pay/pkg/logger"
+	"github.com/ibnuzaman/porta-pay/pkg/tracer"
+
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/channel"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/config"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/delivery/http/handler"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/delivery/http/router"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/service"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/job"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/render"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/repository"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/usecase"
+)
+
+func main() {
+	cfg, err := config.LoadNotificationConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	log := logger.New(cfg.AppName, cfg.Env)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	shutdownTracer, err := tracer.SetupTracer(ctx, cfg.AppName, cfg.OTLPEndpoint)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to setup tracer")
+	} else {
+		defer shutdownTracer(context.Background())
+	}
+
+	if cfg.GetDSN() == "" || cfg.GetDSN() == "postgres://::@:0/?sslmode=disable" {
+		log.Fatal().Msg("NOTIFICATION_POSTGRES_DSN is not set")
+	}
+	if cfg.BookingWebhookSecret == "" {
+		log.Fatal().Msg("NOTIFICATION_BOOKING_WEBHOOK_SECRET is not set")
+	}
+
+	location, err := time.LoadLocation(cfg.TimeZone)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Invalid NOTIFICATION_TIME_ZONE")
+	}
+	renderer, err := render.New(render.Templates, "templates", cfg.DefaultLocale, location)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load notification templates")
+	}
+
+	db := database.Open(cfg.GetDSN())
+	defer db.Close()
+
+	// Dependency injection - Clean Architecture wiring
+	var bookings service.BookingReader
+	if cfg.BookingAPIURL != "" {
+		bookings = booking.New(cfg.BookingAPIURL, booking.WithActor(cfg.AppName), booking.WithToken(cfg.BookingAPIToken))
+		if cfg.BookingAPIToken == "" {
+			log.Warn().Msg("NOTIFICATION_BOOKING_API_TOKEN not set, only bookings of the default tenant can be looked up")
+		}
+	} else {
+		log.Warn().Msg("NOTIFICATION_BOOKING_API_URL not set, status notifications omit booking details")
+	}
+
+	notificationRepo := repository.NewPostgresNotificationRepository(db)
+	preferenceRepo := repository.NewPostgresPreferenceRepository(db)
+	notificationUsecase := usecase.NewNotificationUsecase(notificationRepo, preferenceRepo, renderer, bookings)
+	preferenceUsecase := usecase.NewPreferenceUsecase(preferenceRepo, renderer.Locales(), cfg.DefaultLocale)
+
+	// Background jobs run until shutdown
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	if cfg.SendEnabled {
+		// Local sinks are shared so both channels append to one file
+		sinks := map[string]channel.Sender{
+			"file":    channel.NewFileSender(cfg.SinkFilePath),
+			"console": channel.NewConsoleSender(log),
+		}
+		senders := channel.Senders{
+			entity.ChannelEmail: emailSender(cfg, sinks, log),
+			entity.ChannelSMS:   smsSender(cfg, sinks, log),
+		}
+		sender := job.NewSender(
+			notificationRepo,
+			senders,
+			job.RetryPolicy{
+				MaxAttempts: cfg.SendMaxAttempts,
+				BaseDelay:   cfg.SendBackoffBase,
+				MaxDelay:    cfg.SendBackoffMax,
+			},
+			cfg.SendTimeout,
+			cfg.SendInterval,
+			cfg.SendBatchSize,
+			cfg.SendConcurrency,
+			log,
+		)
+		go sender.Run(jobsCtx)
+	}
+
+	r := router.NewNotificationRouter(
+		handler.NewEventHandler(notificationUsecase, cfg.BookingWebhookSecret, cfg.BookingWebhookTolerance),
+		handler.NewNotificationHandler(notificationUsecase),
+		handler.NewPreferenceHandler(preferenceUsecase),
+	)
+
+	server := &http.Server{
+		Addr:         cfg.HTTPAddr,
+		Handler:      r,
+		ReadTimeout:  15 * time.Second,
+		WriteTimeout: 15 * time.Second,
+		IdleTimeout:  60 * time.Second,
+	}
+
+	go func() {
+		log.Printf("Notification service starting on %s", server.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal().Err(err).Msg("Server failed to start")
+		}
+	}()
+
+	// Wait for interrupt signal to gracefully shutdown
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Shutting down server...")
+	stopJobs()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.ShutdownGrace)
+	defer shutdownCancel()
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Fatal().Err(err).Msg("Server forced to shutdown")
+	}
+
+	log.Println("Server exited")
+}
+
+func emailSender(cfg *config.NotificationConfig, sinks map[string]channel.Sender, log zerolog.Logger) channel.Sender {
+	if cfg.EmailSink == "smtp" {
+		sender, err := channel.NewSMTPSender(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.EmailFrom)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Invalid NOTIFICATION_EMAIL_FROM")
+		}
+		return sender
+	}
+	if sink, ok := sinks[cfg.EmailSink]; ok {
+		return sink
+	}
+	log.Fatal().Str("sink", cfg.EmailSink).Msg("Unknown NOTIFICATION_EMAIL_SINK, want smtp, file or console")
+	return nil
+}
+
+func smsSender(cfg *config.NotificationConfig, sinks map[string]channel.Sender, log zerolog.Logger) channel.Sender {
+	if cfg.SMSSink == "http" {
+		if cfg.SMSGatewayURL == "" {
+			log.Fatal().Msg("NOTIFICATION_SMS_GATEWAY_URL is not set")
+		}
+		return channel.NewSMSGatewaySender(cfg.SMSGatewayURL, cfg.SMSGatewayToken, cfg.SMSFrom, &http.Client{Timeout: cfg.SendTimeout})
+	}
+	if sink, ok := sinks[cfg.SMSSink]; ok {
+		return sink
+	}
+	log.Fatal().Str("sink", cfg.SMSSink).Msg("Unknown NOTIFICATION_SMS_SINK, want http, file or console")
+	return nil
+}
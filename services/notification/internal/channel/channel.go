@@ -0,0 +1,19 @@
+// Package channel delivers rendered notifications. SMTP and the SMS gateway
+// are the production senders; the file and console senders stand in for them
+// during local development.
+package channel
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+)
+
+// Sender delivers a notification over one channel. An error means the
+// notification was not accepted and may be retried.
+type Sender interface {
+	Send(ctx context.Context, n *entity.Notification) error
+}
+
+// Senders maps each channel to the sender that delivers it.
+type Senders map[entity.Channel]Sender
@@ -0,0 +1,33 @@
+package channel
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+)
+
+// ConsoleSender logs notifications instead of sending them.
+type ConsoleSender struct {
+	log zerolog.Logger
+}
+
+func NewConsoleSender(log zerolog.Logger) *ConsoleSender {
+	return &ConsoleSender{
+		log: log,
+	}
+}
+
+func (s *ConsoleSender) Send(ctx context.Context, n *entity.Notification) error {
+	s.log.Info().
+		Int64("notification_id", n.ID).
+		Str("event_type", n.EventType).
+		Int64("booking_id", n.BookingID).
+		Str("channel", string(n.Channel)).
+		Str("recipient", n.Recipient).
+		Str("subject", n.Subject).
+		Str("body", n.Body).
+		Msg("Notification")
+	return nil
+}
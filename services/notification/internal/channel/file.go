@@ -0,0 +1,66 @@
+package channel
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+)
+
+// FileSender appends each notification to a file as a JSON line, for local
+// development and tests that inspect what would have been sent.
+type FileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileSender(path string) *FileSender {
+	return &FileSender{
+		path: path,
+	}
+}
+
+type fileRecord struct {
+	SentAt    time.Time      `json:"sent_at"`
+	ID        int64          `json:"id"`
+	EventType string         `json:"event_type"`
+	BookingID int64          `json:"booking_id"`
+	Channel   entity.Channel `json:"channel"`
+	Recipient string         `json:"recipient"`
+	Locale    string         `json:"locale"`
+	Subject   string         `json:"subject,omitempty"`
+	Body      string         `json:"body"`
+}
+
+func (s *FileSender) Send(ctx context.Context, n *entity.Notification) error {
+	line, err := json.Marshal(fileRecord{
+		SentAt:    time.Now(),
+		ID:        n.ID,
+		EventType: n.EventType,
+		BookingID: n.BookingID,
+		Channel:   n.Channel,
+		Recipient: n.Recipient,
+		Locale:    n.Locale,
+		Subject:   n.Subject,
+		Body:      n.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,73 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+)
+
+// SMSGatewaySender posts text messages to an HTTP SMS gateway as
+// {"from", "to", "text", "reference"} JSON with a bearer token. Any 2xx
+// response counts as accepted.
+type SMSGatewaySender struct {
+	url    string
+	token  string
+	from   string
+	client *http.Client
+}
+
+func NewSMSGatewaySender(url, token, from string, client *http.Client) *SMSGatewaySender {
+	return &SMSGatewaySender{
+		url:    url,
+		token:  token,
+		from:   from,
+		client: client,
+	}
+}
+
+type smsRequest struct {
+	From      string `json:"from,omitempty"`
+	To        string `json:"to"`
+	Text      string `json:"text"`
+	Reference string `json:"reference"`
+}
+
+func (s *SMSGatewaySender) Send(ctx context.Context, n *entity.Notification) error {
+	body, err := json.Marshal(smsRequest{
+		From:      s.from,
+		To:        n.Recipient,
+		Text:      n.Body,
+		Reference: fmt.Sprintf("notification-%d", n.ID),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		return nil
+	}
+
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	return fmt.Errorf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+}
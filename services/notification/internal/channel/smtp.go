@@ -0,0 +1,72 @@
+package channel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+)
+
+// SMTPSender sends email through an SMTP relay, authenticating with PLAIN
+// when a username is set.
+type SMTPSender struct {
+	addr string
+	from *mail.Address
+	auth smtp.Auth
+}
+
+// NewSMTPSender sends from, which may carry a display name as in
+// "Porta Pay <no-reply@example.com>".
+func NewSMTPSender(addr, username, password, from string) (*SMTPSender, error) {
+	fromAddr, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &SMTPSender{
+		addr: addr,
+		from: fromAddr,
+	}
+	if username != "" {
+		host, _, _ := net.SplitHostPort(addr)
+		s.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return s, nil
+}
+
+func (s *SMTPSender) Send(ctx context.Context, n *entity.Notification) error {
+	// net/smtp takes no context; give up in the background instead so a
+	// stuck relay does not hold the worker past shutdown
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(s.addr, s.auth, s.from.Address, []string{n.Recipient}, s.message(n))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *SMTPSender) message(n *entity.Notification) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", s.from)
+	fmt.Fprintf(&buf, "To: %s\r\n", n.Recipient)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", n.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <notification-%d@porta-pay>\r\n", n.ID)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	buf.WriteString(n.Body)
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
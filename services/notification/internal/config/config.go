@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+
+	"github.com/ibnuzaman/porta-pay/pkg/config"
+)
+
+// Prefix namespaces the notification service variables, e.g.
+// NOTIFICATION_HTTP_ADDR, so it can share an environment with booking.
+const Prefix = "NOTIFICATION_"
+
+// NotificationConfig extends the base config with notification-specific settings
+type NotificationConfig struct {
+	*config.Config
+
+	// Booking events arrive through a booking service webhook subscription
+	// created with this secret
+	BookingWebhookSecret    string        `env:"BOOKING_WEBHOOK_SECRET"`
+	BookingWebhookTolerance time.Duration `env:"BOOKING_WEBHOOK_TOLERANCE" envDefault:"5m"`
+	// Booking API used to fill in departure and passengers for status
+	// change events; without it those notifications carry less detail
+	BookingAPIURL string `env:"BOOKING_API_URL"`
+	// Admin token of the booking service, which it requires before looking
+	// bookings up in the tenant of the event
+	BookingAPIToken string `env:"BOOKING_API_TOKEN"`
+
+	// Templates
+	DefaultLocale string `env:"DEFAULT_LOCALE" envDefault:"en"`
+	TimeZone      string `env:"TIME_ZONE" envDefault:"Asia/Jakarta"`
+
+	// Sinks: smtp, file or console for email; http, file or console for SMS
+	EmailSink    string `env:"EMAIL_SINK" envDefault:"console"`
+	SMSSink      string `env:"SMS_SINK" envDefault:"console"`
+	SinkFilePath string `env:"SINK_FILE_PATH" envDefault:"notifications.ndjson"`
+
+	SMTPAddr     string `env:"SMTP_ADDR" envDefault:"localhost:1025"`
+	SMTPUsername string `env:"SMTP_USERNAME"`
+	SMTPPassword string `env:"SMTP_PASSWORD"`
+	EmailFrom    string `env:"EMAIL_FROM" envDefault:"no-reply@porta-pay.local"`
+
+	SMSGatewayURL   string `env:"SMS_GATEWAY_URL"`
+	SMSGatewayToken string `env:"SMS_GATEWAY_TOKEN"`
+	SMSFrom         string `env:"SMS_FROM" envDefault:"PortaPay"`
+
+	// Sending with retries
+	SendEnabled     bool          `env:"SEND_ENABLED" envDefault:"true"`
+	SendInterval    time.Duration `env:"SEND_INTERVAL" envDefault:"2s"`
+	SendBatchSize   int           `env:"SEND_BATCH_SIZE" envDefault:"50"`
+	SendConcurrency int           `env:"SEND_CONCURRENCY" envDefault:"4"`
+	SendTimeout     time.Duration `env:"SEND_TIMEOUT" envDefault:"15s"`
+	SendMaxAttempts int           `env:"SEND_MAX_ATTEMPTS" envDefault:"6"`
+	SendBackoffBase time.Duration `env:"SEND_BACKOFF_BASE" envDefault:"30s"`
+	SendBackoffMax  time.Duration `env:"SEND_BACKOFF_MAX" envDefault:"1h"`
+}
+
+// LoadNotificationConfig loads notification service configuration
+func LoadNotificationConfig() (*NotificationConfig, error) {
+	baseConfig, err := config.LoadWithPrefix(Prefix)
+	if err != nil {
+		return nil, err
+	}
+	if os.Getenv(Prefix+"APP_NAME") == "" {
+		baseConfig.AppName = "notification"
+	}
+
+	notificationConfig := &NotificationConfig{
+		Config: &baseConfig,
+	}
+
+	if err := env.ParseWithOptions(notificationConfig, env.Options{Prefix: Prefix}); err != nil {
+		return nil, err
+	}
+
+	return notificationConfig, nil
+}
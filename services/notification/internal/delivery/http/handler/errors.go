@@ -0,0 +1,30 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+)
+
+// writeError maps domain errors to HTTP responses.
+func writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, apperrors.ErrNotificationNotFound),
+		errors.Is(err, apperrors.ErrPreferenceNotFound):
+		response.NotFound(w, err.Error())
+	case errors.Is(err, apperrors.ErrInvalidPreference),
+		errors.Is(err, apperrors.ErrInvalidEvent):
+		response.BadRequest(w, err.Error())
+	default:
+		response.InternalServerError(w, err.Error())
+	}
+}
+
+func pageParams(r *http.Request) (int, int) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	return limit, offset
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/pkg/webhook"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/service"
+)
+
+// maxEventBody bounds booking event payloads; a booking with a full
+// passenger list is a few kilobytes.
+const maxEventBody = 1 << 20
+
+// EventHandler receives booking events pushed by a booking service webhook
+// subscription.
+type EventHandler struct {
+	notificationService service.NotificationService
+	secret              string
+	tolerance           time.Duration
+}
+
+// NewEventHandler accepts events signed with the subscription secret.
+func NewEventHandler(notificationService service.NotificationService, secret string, tolerance time.Duration) *EventHandler {
+	if tolerance <= 0 {
+		tolerance = webhook.DefaultTolerance
+	}
+
+	return &EventHandler{
+		notificationService: notificationService,
+		secret:              secret,
+		tolerance:           tolerance,
+	}
+}
+
+type eventResponse struct {
+	EventID int64 `json:"event_id"`
+	Queued  int   `json:"queued"`
+}
+
+func (h *EventHandler) HandleBookingEvent(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEventBody))
+	if err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// Verify before parsing so unsigned input never reaches the decoder
+	if err := webhook.Verify([]byte(h.secret), r.Header.Get(webhook.SignatureHeader), body, h.tolerance, time.Now()); err != nil {
+		response.Unauthorized(w, err.Error())
+		return
+	}
+
+	var event entity.Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		response.BadRequest(w, "Invalid request body")
+		return
+	}
+
+	// A non-2xx response makes the booking service redeliver the event,
+	// which is safe: notifications are deduplicated per event and channel
+	queued, err := h.notificationService.HandleEvent(r.Context(), &event)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, eventResponse{
+		EventID: event.ID,
+		Queued:  len(queued),
+	})
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/service"
+)
+
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+func (h *NotificationHandler) Health(w http.ResponseWriter, r *http.Request) {
+	response.Success(w, http.StatusOK, map[string]string{
+		"status":  "ok",
+		"service": "notification",
+	})
+}
+
+func (h *NotificationHandler) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := entity.NotificationFilter{
+		Channel: entity.Channel(query.Get("channel")),
+		Status:  entity.NotificationStatus(query.Get("status")),
+	}
+
+	var err error
+	if v := query.Get("user_id"); v != "" {
+		if filter.UserID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			response.BadRequest(w, "invalid user_id")
+			return
+		}
+	}
+	if v := query.Get("booking_id"); v != "" {
+		if filter.BookingID, err = strconv.ParseInt(v, 10, 64); err != nil {
+			response.BadRequest(w, "invalid booking_id")
+			return
+		}
+	}
+
+	limit, offset := pageParams(r)
+	notifications, err := h.notificationService.ListNotifications(r.Context(), filter, limit, offset)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, notifications)
+}
+
+func (h *NotificationHandler) GetNotification(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid ID")
+		return
+	}
+
+	notification, err := h.notificationService.GetNotification(r.Context(), id)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, notification)
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/ibnuzaman/porta-pay/pkg/response"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/service"
+)
+
+type PreferenceHandler struct {
+	preferenceService service.PreferenceService
+}
+
+func NewPreferenceHandler(preferenceService service.PreferenceService) *PreferenceHandler {
+	return &PreferenceHandler{
+		preferenceService: preferenceService,
+	}
+}
+
+type preferenceRequest struct {
+	Locale       string   `json:"locale"`
+	Email        string   `json:"email"`
+	Phone        string   `json:"phone"`
+	EmailEnabled bool     `json:"email_enabled"`
+	SMSEnabled   bool     `json:"sms_enabled"`
+	MutedEvents  []string `json:"muted_events"`
+}
+
+func (h *PreferenceHandler) GetPreference(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	preference, err := h.preferenceService.GetPreference(r.Context(), userID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, preference)
+}
+
+// UpdatePreference replaces the user's preferences as a whole.
+func (h *PreferenceHandler) UpdatePreference(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		response.BadRequest(w, "Invalid user ID")
+		return
+	}
+
+	var req preferenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.BadRequest(w, "Invalid JSON")
+		return
+	}
+
+	preference := &entity.Preference{
+		UserID:       userID,
+		Locale:       req.Locale,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		EmailEnabled: req.EmailEnabled,
+		SMSEnabled:   req.SMSEnabled,
+		MutedEvents:  req.MutedEvents,
+	}
+	if err := h.preferenceService.UpdatePreference(r.Context(), preference); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, preference)
+}
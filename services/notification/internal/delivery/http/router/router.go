@@ -0,0 +1,45 @@
+package router
+
+import (
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+
+	pkgmiddleware "github.com/ibnuzaman/porta-pay/pkg/middleware"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/delivery/http/handler"
+)
+
+func NewNotificationRouter(
+	eventHandler *handler.EventHandler,
+	notificationHandler *handler.NotificationHandler,
+	preferenceHandler *handler.PreferenceHandler,
+) chi.Router {
+	r := chi.NewRouter()
+
+	r.Use(
+		middleware.RequestID,
+		middleware.RealIP,
+		middleware.Logger,
+		middleware.Recoverer,
+		middleware.Timeout(60*time.Second),
+		pkgmiddleware.ContentTypeJSON,
+	)
+
+	// Health check endpoints
+	r.Get("/health", notificationHandler.Health)
+	r.Get("/ping", notificationHandler.Health)
+
+	// Per-user notification preferences
+	r.Get("/api/v1/preferences/{userID}", preferenceHandler.GetPreference)
+	r.Put("/api/v1/preferences/{userID}", preferenceHandler.UpdatePreference)
+
+	// Log of queued and sent notifications
+	r.Get("/api/v1/notifications", notificationHandler.ListNotifications)
+	r.Get("/api/v1/notifications/{id}", notificationHandler.GetNotification)
+
+	// Booking events from the booking service, authenticated by signature
+	r.Post("/webhooks/booking-events", eventHandler.HandleBookingEvent)
+
+	return r
+}
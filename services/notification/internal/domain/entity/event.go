@@ -0,0 +1,45 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Booking events customers are notified about. The names match the booking
+// service webhook event types.
+const (
+	EventBookingCreated   = "booking.created"
+	EventBookingPaid      = "booking.paid"
+	EventBookingConfirmed = "booking.confirmed"
+	EventBookingExpired   = "booking.expired"
+)
+
+// NotifiedEvents are the booking events that produce notifications.
+var NotifiedEvents = []string{
+	EventBookingCreated,
+	EventBookingPaid,
+	EventBookingConfirmed,
+	EventBookingExpired,
+}
+
+// IsNotified reports whether eventType produces notifications.
+func IsNotified(eventType string) bool {
+	for _, t := range NotifiedEvents {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Event is a booking event as delivered by a booking service webhook.
+// Data is the full booking for booking.created and a status change for the
+// others.
+type Event struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	BookingID int64           `json:"booking_id"`
+	TenantID  string          `json:"tenant_id"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
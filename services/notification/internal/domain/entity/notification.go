@@ -0,0 +1,44 @@
+package entity
+
+import "time"
+
+type NotificationStatus string
+
+const (
+	// NotificationPending notifications are waiting for their next attempt.
+	NotificationPending NotificationStatus = "PENDING"
+	// NotificationSent notifications were accepted by the channel.
+	NotificationSent NotificationStatus = "SENT"
+	// NotificationFailed notifications ran out of attempts.
+	NotificationFailed NotificationStatus = "FAILED"
+)
+
+// Notification is one booking event rendered for one channel. Recipient,
+// subject and body are fixed when it is queued so retries send the same
+// message even if preferences change meanwhile.
+type Notification struct {
+	ID            int64              `json:"id" db:"id"`
+	EventID       int64              `json:"event_id" db:"event_id"`
+	EventType     string             `json:"event_type" db:"event_type"`
+	BookingID     int64              `json:"booking_id" db:"booking_id"`
+	UserID        int64              `json:"user_id" db:"user_id"`
+	Channel       Channel            `json:"channel" db:"channel"`
+	Recipient     string             `json:"recipient" db:"recipient"`
+	Locale        string             `json:"locale" db:"locale"`
+	Subject       string             `json:"subject,omitempty" db:"subject"`
+	Body          string             `json:"body" db:"body"`
+	Status        NotificationStatus `json:"status" db:"status"`
+	Attempts      int                `json:"attempts" db:"attempts"`
+	NextAttemptAt time.Time          `json:"next_attempt_at" db:"next_attempt_at"`
+	LastError     string             `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt     time.Time          `json:"created_at" db:"created_at"`
+	SentAt        *time.Time         `json:"sent_at,omitempty" db:"sent_at"`
+}
+
+// NotificationFilter selects notifications. Zero-valued fields are ignored.
+type NotificationFilter struct {
+	UserID    int64
+	BookingID int64
+	Channel   Channel
+	Status    NotificationStatus
+}
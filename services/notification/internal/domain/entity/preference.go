@@ -0,0 +1,54 @@
+package entity
+
+import "time"
+
+type Channel string
+
+const (
+	ChannelEmail Channel = "email"
+	ChannelSMS   Channel = "sms"
+)
+
+// Channels lists every channel in the order notifications are queued.
+var Channels = []Channel{ChannelEmail, ChannelSMS}
+
+// Preference is how a user wants to hear about their bookings. Users
+// without preferences are not notified, as there is nowhere to send to.
+type Preference struct {
+	UserID       int64     `json:"user_id" db:"user_id"`
+	Locale       string    `json:"locale" db:"locale"`
+	Email        string    `json:"email" db:"email"`
+	Phone        string    `json:"phone" db:"phone"`
+	EmailEnabled bool      `json:"email_enabled" db:"email_enabled"`
+	SMSEnabled   bool      `json:"sms_enabled" db:"sms_enabled"`
+	MutedEvents  []string  `json:"muted_events" db:"muted_events"`
+	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Recipient returns the address for channel, empty when there is none.
+func (p *Preference) Recipient(channel Channel) string {
+	switch channel {
+	case ChannelEmail:
+		return p.Email
+	case ChannelSMS:
+		return p.Phone
+	}
+	return ""
+}
+
+// Wants reports whether the user receives eventType over channel.
+func (p *Preference) Wants(eventType string, channel Channel) bool {
+	for _, muted := range p.MutedEvents {
+		if muted == eventType {
+			return false
+		}
+	}
+
+	switch channel {
+	case ChannelEmail:
+		return p.EmailEnabled && p.Email != ""
+	case ChannelSMS:
+		return p.SMSEnabled && p.Phone != ""
+	}
+	return false
+}
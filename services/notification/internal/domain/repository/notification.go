@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+)
+
+type NotificationRepository interface {
+	// Create queues n unless its event was already queued for the same
+	// channel, and reports whether it did.
+	Create(ctx context.Context, n *entity.Notification) (bool, error)
+	GetByID(ctx context.Context, id int64) (*entity.Notification, error)
+	List(ctx context.Context, filter entity.NotificationFilter, limit, offset int) ([]*entity.Notification, error)
+	// ClaimDue returns up to limit pending notifications that are due and
+	// pushes their next attempt out by lease so other workers skip them.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.Notification, error)
+	// RecordAttempt stores the outcome of a send attempt.
+	RecordAttempt(ctx context.Context, n *entity.Notification) error
+}
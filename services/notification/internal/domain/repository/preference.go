@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+)
+
+type PreferenceRepository interface {
+	Get(ctx context.Context, userID int64) (*entity.Preference, error)
+	Upsert(ctx context.Context, p *entity.Preference) error
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/pkg/client/booking"
+)
+
+// BookingReader looks bookings up in the booking service, to fill in what
+// status change events leave out, in the tenant ctx is scoped to.
+// *booking.Client implements it.
+type BookingReader interface {
+	GetBooking(ctx context.Context, id int64) (*booking.Booking, error)
+}
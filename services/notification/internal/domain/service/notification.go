@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+)
+
+type NotificationService interface {
+	// HandleEvent renders and queues the notifications a booking event calls
+	// for. Events already handled queue nothing new.
+	HandleEvent(ctx context.Context, event *entity.Event) ([]*entity.Notification, error)
+	GetNotification(ctx context.Context, id int64) (*entity.Notification, error)
+	ListNotifications(ctx context.Context, filter entity.NotificationFilter, limit, offset int) ([]*entity.Notification, error)
+}
+
+type PreferenceService interface {
+	GetPreference(ctx context.Context, userID int64) (*entity.Preference, error)
+	UpdatePreference(ctx context.Context, p *entity.Preference) error
+}
@@ -0,0 +1,168 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/channel"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/repository"
+)
+
+// RetryPolicy bounds send attempts. Delays grow exponentially from BaseDelay
+// up to MaxDelay, with up to 20% jitter so retries after a relay outage
+// spread out.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// Delay returns how long to wait after the given failed attempt (1-based).
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	return time.Duration(delay * (1 + 0.2*rand.Float64()))
+}
+
+// Sender sends pending notifications over their channel and marks those that
+// fail MaxAttempts times as failed.
+type Sender struct {
+	notificationRepo repository.NotificationRepository
+	senders          channel.Senders
+	retry            RetryPolicy
+	timeout          time.Duration
+	interval         time.Duration
+	batchSize        int
+	concurrency      int
+	log              zerolog.Logger
+}
+
+func NewSender(
+	notificationRepo repository.NotificationRepository,
+	senders channel.Senders,
+	retry RetryPolicy,
+	timeout, interval time.Duration,
+	batchSize, concurrency int,
+	log zerolog.Logger,
+) *Sender {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	return &Sender{
+		notificationRepo: notificationRepo,
+		senders:          senders,
+		retry:            retry,
+		timeout:          timeout,
+		interval:         interval,
+		batchSize:        batchSize,
+		concurrency:      concurrency,
+		log:              log,
+	}
+}
+
+// Run sends on every tick until ctx is cancelled.
+func (s *Sender) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			s.log.Error().Err(err).Msg("Notification sending failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce attempts one batch of due notifications and returns how many were
+// attempted.
+func (s *Sender) RunOnce(ctx context.Context) (int, error) {
+	// The lease outlives the slowest possible attempt so no other worker
+	// claims a notification that is still in flight.
+	lease := s.timeout + time.Minute
+	notifications, err := s.notificationRepo.ClaimDue(ctx, s.batchSize, lease)
+	if err != nil || len(notifications) == 0 {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, s.concurrency)
+	for _, n := range notifications {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(n *entity.Notification) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			s.send(ctx, n)
+		}(n)
+	}
+	wg.Wait()
+
+	return len(notifications), nil
+}
+
+func (s *Sender) send(ctx context.Context, n *entity.Notification) {
+	err := s.attempt(ctx, n)
+
+	// Shutting down mid-send is not the channel's fault; the lease expires
+	// and the attempt is retried without using up the budget.
+	if ctx.Err() != nil {
+		return
+	}
+
+	n.Attempts++
+	n.LastError = ""
+	switch {
+	case err == nil:
+		now := time.Now()
+		n.Status = entity.NotificationSent
+		n.SentAt = &now
+	case n.Attempts >= s.retry.MaxAttempts:
+		n.Status = entity.NotificationFailed
+		n.LastError = err.Error()
+	default:
+		n.LastError = err.Error()
+		n.NextAttemptAt = time.Now().Add(s.retry.Delay(n.Attempts))
+	}
+
+	if err := s.notificationRepo.RecordAttempt(ctx, n); err != nil {
+		s.log.Error().Err(err).Int64("notification_id", n.ID).Msg("Failed to record notification attempt")
+		return
+	}
+
+	if n.Status == entity.NotificationFailed {
+		s.log.Warn().
+			Int64("notification_id", n.ID).
+			Int64("booking_id", n.BookingID).
+			Str("event_type", n.EventType).
+			Str("channel", string(n.Channel)).
+			Int("attempts", n.Attempts).
+			Str("error", n.LastError).
+			Msg("Notification failed")
+	}
+}
+
+func (s *Sender) attempt(ctx context.Context, n *entity.Notification) error {
+	sender, ok := s.senders[n.Channel]
+	if !ok {
+		return fmt.Errorf("no sender for channel %q", n.Channel)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	return sender.Send(ctx, n)
+}
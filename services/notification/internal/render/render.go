@@ -0,0 +1,160 @@
+// Package render turns booking events into notification text using per-locale
+// templates.
+//
+// Each template file is named <locale>/<event type>.tmpl and defines
+// "email.subject", "email.body" and "sms". A locale without a template for an
+// event falls back to its base language ("id" for "id-ID") and then to the
+// default locale.
+package render
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/client/booking"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+)
+
+// Templates holds the built-in templates.
+//
+//go:embed templates
+var Templates embed.FS
+
+// Data is what templates are executed with.
+type Data struct {
+	Event   string
+	Booking *booking.Booking
+}
+
+// Message is a rendered notification. Subject is empty for SMS.
+type Message struct {
+	Locale  string
+	Subject string
+	Body    string
+}
+
+type Renderer struct {
+	templates     map[string]*template.Template
+	locales       []string
+	defaultLocale string
+	location      *time.Location
+}
+
+// New parses every template under dir in fsys. Times are printed in
+// location.
+func New(fsys fs.FS, dir, defaultLocale string, location *time.Location) (*Renderer, error) {
+	r := &Renderer{
+		templates:     make(map[string]*template.Template),
+		defaultLocale: defaultLocale,
+		location:      location,
+	}
+	funcs := template.FuncMap{"datetime": r.datetime}
+
+	files, err := fs.Glob(fsys, path.Join(dir, "*", "*.tmpl"))
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, file := range files {
+		locale := path.Base(path.Dir(file))
+		event := strings.TrimSuffix(path.Base(file), ".tmpl")
+
+		t, err := template.New(path.Base(file)).Funcs(funcs).Option("missingkey=error").ParseFS(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range []string{"email.subject", "email.body", "sms"} {
+			if t.Lookup(name) == nil {
+				return nil, fmt.Errorf("template %s does not define %q", file, name)
+			}
+		}
+		r.templates[locale+"/"+event] = t
+		if !seen[locale] {
+			seen[locale] = true
+			r.locales = append(r.locales, locale)
+		}
+	}
+	sort.Strings(r.locales)
+
+	if !seen[defaultLocale] {
+		return nil, fmt.Errorf("no templates for default locale %q", defaultLocale)
+	}
+	return r, nil
+}
+
+// Locales returns the locales that have templates.
+func (r *Renderer) Locales() []string {
+	return r.locales
+}
+
+// Render renders the notification for event over channel in locale, or in a
+// fallback locale when locale has no template for the event.
+func (r *Renderer) Render(locale string, channel entity.Channel, data *Data) (*Message, error) {
+	t, locale := r.lookup(locale, data.Event)
+	if t == nil {
+		return nil, fmt.Errorf("no template for %s", data.Event)
+	}
+
+	msg := &Message{Locale: locale}
+	var err error
+	switch channel {
+	case entity.ChannelEmail:
+		if msg.Subject, err = execute(t, "email.subject", data); err != nil {
+			return nil, err
+		}
+		msg.Body, err = execute(t, "email.body", data)
+	case entity.ChannelSMS:
+		msg.Body, err = execute(t, "sms", data)
+	default:
+		err = fmt.Errorf("unknown channel %q", channel)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (r *Renderer) lookup(locale, event string) (*template.Template, string) {
+	candidates := []string{locale}
+	if base, _, ok := strings.Cut(locale, "-"); ok {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, r.defaultLocale)
+
+	for _, candidate := range candidates {
+		if t, ok := r.templates[candidate+"/"+event]; ok {
+			return t, candidate
+		}
+	}
+	return nil, ""
+}
+
+func execute(t *template.Template, name string, data *Data) (string, error) {
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// datetime formats a time or a *time.Time in the renderer's location; nil
+// prints nothing.
+func (r *Renderer) datetime(v interface{}) string {
+	switch t := v.(type) {
+	case time.Time:
+		return t.In(r.location).Format("02 Jan 2006 15:04 MST")
+	case *time.Time:
+		if t == nil {
+			return ""
+		}
+		return r.datetime(*t)
+	}
+	return fmt.Sprint(v)
+}
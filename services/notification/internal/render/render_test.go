@@ -0,0 +1,82 @@
+package render
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/client/booking"
+	"github.com/ibnuzaman/porta-pay/pkg/money"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+)
+
+func TestRenderBuiltInTemplates(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*3600)
+	r, err := New(Templates, "templates", "en", jakarta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(r.Locales(), ","); got != "en,id" {
+		t.Errorf("locales: got %s", got)
+	}
+
+	departure := time.Date(2025, 10, 10, 1, 0, 0, 0, time.UTC)
+	b := &booking.Booking{
+		ID: 42, RouteID: 7, Qty: 2, DepartureAt: &departure,
+		PriceTotal: money.MustNew(25000000, money.IDR),
+		Passengers: []booking.Passenger{{FullName: "Siti Rahma", Seat: "3A"}},
+	}
+
+	for _, event := range entity.NotifiedEvents {
+		for _, locale := range r.Locales() {
+			for _, channel := range entity.Channels {
+				msg, err := r.Render(locale, channel, &Data{Event: event, Booking: b})
+				if err != nil {
+					t.Fatalf("%s %s %s: %v", locale, event, channel, err)
+				}
+				if !strings.Contains(msg.Body, "#42") {
+					t.Errorf("%s %s %s: body misses the booking ID: %q", locale, event, channel, msg.Body)
+				}
+				if (channel == entity.ChannelEmail) != (msg.Subject != "") {
+					t.Errorf("%s %s %s: unexpected subject %q", locale, event, channel, msg.Subject)
+				}
+			}
+		}
+	}
+
+	msg, _ := r.Render("id-ID", entity.ChannelEmail, &Data{Event: entity.EventBookingConfirmed, Booking: b})
+	if msg.Locale != "id" || !strings.Contains(msg.Body, "10 Oct 2025 08:00 WIB") || !strings.Contains(msg.Body, "Siti Rahma, kursi 3A") {
+		t.Errorf("id-ID confirmed: locale %s body %q", msg.Locale, msg.Body)
+	}
+
+	// Status change events carry no departure or passengers
+	msg, err = r.Render("en", entity.ChannelSMS, &Data{Event: entity.EventBookingConfirmed, Booking: &booking.Booking{ID: 42}})
+	if err != nil || msg.Body != "Porta Pay: booking #42 is confirmed. Show your ticket at the gate." {
+		t.Errorf("sparse booking: %q, %v", msg.Body, err)
+	}
+}
+
+func TestRenderFallsBackToDefaultLocale(t *testing.T) {
+	fsys := fstest.MapFS{
+		"t/en/booking.paid.tmpl": {Data: []byte(`{{define "email.subject"}}Paid{{end}}{{define "email.body"}}Paid #{{.Booking.ID}}{{end}}{{define "sms"}}paid{{end}}`)},
+		"t/de/booking.paid.tmpl": {Data: []byte(`{{define "email.subject"}}Bezahlt{{end}}{{define "email.body"}}Bezahlt{{end}}{{define "sms"}}bezahlt{{end}}`)},
+	}
+	r, err := New(fsys, "t", "en", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := r.Render("fr", entity.ChannelEmail, &Data{Event: entity.EventBookingPaid, Booking: &booking.Booking{ID: 1}})
+	if err != nil || msg.Locale != "en" || msg.Body != "Paid #1" {
+		t.Errorf("fallback: %+v, %v", msg, err)
+	}
+	if _, err := r.Render("en", entity.ChannelEmail, &Data{Event: entity.EventBookingExpired, Booking: &booking.Booking{}}); err == nil {
+		t.Error("missing event: expected an error")
+	}
+
+	fsys["t/de/booking.expired.tmpl"] = &fstest.MapFile{Data: []byte(`{{define "sms"}}x{{end}}`)}
+	if _, err := New(fsys, "t", "en", time.UTC); err == nil {
+		t.Error("incomplete template: expected an error")
+	}
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/repository"
+)
+
+const notificationColumns = `id, event_id, event_type, booking_id, user_id, channel, recipient, locale, subject,
+	body, status, attempts, next_attempt_at, last_error, created_at, sent_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+type postgresNotificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresNotificationRepository(db *sqlx.DB) repository.NotificationRepository {
+	return &postgresNotificationRepository{
+		db: db,
+	}
+}
+
+func scanNotification(row rowScanner) (*entity.Notification, error) {
+	n := &entity.Notification{}
+	err := row.Scan(
+		&n.ID,
+		&n.EventID,
+		&n.EventType,
+		&n.BookingID,
+		&n.UserID,
+		&n.Channel,
+		&n.Recipient,
+		&n.Locale,
+		&n.Subject,
+		&n.Body,
+		&n.Status,
+		&n.Attempts,
+		&n.NextAttemptAt,
+		&n.LastError,
+		&n.CreatedAt,
+		&n.SentAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (r *postgresNotificationRepository) Create(ctx context.Context, n *entity.Notification) (bool, error) {
+	query := `
+		INSERT INTO notifications (event_id, event_type, booking_id, user_id, channel, recipient, locale,
+			subject, body, status, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (event_id, channel) DO NOTHING
+		RETURNING id, created_at`
+
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query,
+		n.EventID,
+		n.EventType,
+		n.BookingID,
+		n.UserID,
+		n.Channel,
+		n.Recipient,
+		n.Locale,
+		n.Subject,
+		n.Body,
+		n.Status,
+		n.NextAttemptAt,
+	).Scan(&n.ID, &n.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *postgresNotificationRepository) GetByID(ctx context.Context, id int64) (*entity.Notification, error) {
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE id = $1`
+
+	n, err := scanNotification(database.Conn(ctx, r.db).QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrNotificationNotFound
+	}
+	return n, err
+}
+
+func (r *postgresNotificationRepository) List(ctx context.Context, filter entity.NotificationFilter, limit, offset int) ([]*entity.Notification, error) {
+	where, args := filterClause(filter, []interface{}{limit, offset})
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE ` + where + `
+		ORDER BY id DESC
+		LIMIT $1 OFFSET $2`
+
+	return r.query(ctx, query, args...)
+}
+
+func (r *postgresNotificationRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]*entity.Notification, error) {
+	query := `
+		UPDATE notifications
+		SET next_attempt_at = now() + $2 * interval '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM notifications
+			WHERE status = 'PENDING' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + notificationColumns
+
+	return r.query(ctx, query, limit, lease.Milliseconds())
+}
+
+func (r *postgresNotificationRepository) RecordAttempt(ctx context.Context, n *entity.Notification) error {
+	query := `
+		UPDATE notifications
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_error = $5, sent_at = $6
+		WHERE id = $1`
+
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		n.ID,
+		n.Status,
+		n.Attempts,
+		n.NextAttemptAt,
+		n.LastError,
+		n.SentAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperrors.ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (r *postgresNotificationRepository) query(ctx context.Context, query string, args ...interface{}) ([]*entity.Notification, error) {
+	rows, err := database.Conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*entity.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+func filterClause(filter entity.NotificationFilter, args []interface{}) (string, []interface{}) {
+	var conds []string
+	add := func(cond string, value interface{}) {
+		args = append(args, value)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter.UserID != 0 {
+		add("user_id = $%d", filter.UserID)
+	}
+	if filter.BookingID != 0 {
+		add("booking_id = $%d", filter.BookingID)
+	}
+	if filter.Channel != "" {
+		add("channel = $%d", filter.Channel)
+	}
+	if filter.Status != "" {
+		add("status = $%d", filter.Status)
+	}
+
+	if len(conds) == 0 {
+		return "TRUE", args
+	}
+	return strings.Join(conds, " AND "), args
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"github.com/ibnuzaman/porta-pay/pkg/database"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/repository"
+)
+
+type postgresPreferenceRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresPreferenceRepository(db *sqlx.DB) repository.PreferenceRepository {
+	return &postgresPreferenceRepository{
+		db: db,
+	}
+}
+
+func (r *postgresPreferenceRepository) Get(ctx context.Context, userID int64) (*entity.Preference, error) {
+	query := `
+		SELECT user_id, locale, email, phone, email_enabled, sms_enabled, muted_events, updated_at
+		FROM notification_preferences
+		WHERE user_id = $1`
+
+	p := &entity.Preference{}
+	err := database.Conn(ctx, r.db).QueryRowContext(ctx, query, userID).Scan(
+		&p.UserID,
+		&p.Locale,
+		&p.Email,
+		&p.Phone,
+		&p.EmailEnabled,
+		&p.SMSEnabled,
+		pq.Array(&p.MutedEvents),
+		&p.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperrors.ErrPreferenceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *postgresPreferenceRepository) Upsert(ctx context.Context, p *entity.Preference) error {
+	query := `
+		INSERT INTO notification_preferences (user_id, locale, email, phone, email_enabled, sms_enabled, muted_events, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE
+		SET locale = EXCLUDED.locale,
+			email = EXCLUDED.email,
+			phone = EXCLUDED.phone,
+			email_enabled = EXCLUDED.email_enabled,
+			sms_enabled = EXCLUDED.sms_enabled,
+			muted_events = EXCLUDED.muted_events,
+			updated_at = EXCLUDED.updated_at`
+
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query,
+		p.UserID,
+		p.Locale,
+		p.Email,
+		p.Phone,
+		p.EmailEnabled,
+		p.SMSEnabled,
+		pq.Array(p.MutedEvents),
+		p.UpdatedAt,
+	)
+	return err
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ibnuzaman/porta-pay/pkg/client/booking"
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/pkg/reqctx"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/service"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/render"
+)
+
+type notificationUsecase struct {
+	notificationRepo repository.NotificationRepository
+	preferenceRepo   repository.PreferenceRepository
+	renderer         *render.Renderer
+	bookings         service.BookingReader
+}
+
+// NewNotificationUsecase renders notifications with renderer. When bookings is
+// nil, notifications for status change events are rendered from the event
+// alone, without departure or passenger details.
+func NewNotificationUsecase(
+	notificationRepo repository.NotificationRepository,
+	preferenceRepo repository.PreferenceRepository,
+	renderer *render.Renderer,
+	bookings service.BookingReader,
+) service.NotificationService {
+	return &notificationUsecase{
+		notificationRepo: notificationRepo,
+		preferenceRepo:   preferenceRepo,
+		renderer:         renderer,
+		bookings:         bookings,
+	}
+}
+
+func (uc *notificationUsecase) HandleEvent(ctx context.Context, event *entity.Event) ([]*entity.Notification, error) {
+	if event.ID <= 0 || event.BookingID <= 0 || event.Type == "" {
+		return nil, apperrors.ErrInvalidEvent
+	}
+
+	queued := []*entity.Notification{}
+	if !entity.IsNotified(event.Type) {
+		return queued, nil
+	}
+
+	b, err := uc.booking(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	pref, err := uc.preferenceRepo.Get(ctx, b.UserID)
+	if errors.Is(err, apperrors.ErrPreferenceNotFound) {
+		return queued, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, channel := range entity.Channels {
+		if !pref.Wants(event.Type, channel) {
+			continue
+		}
+
+		msg, err := uc.renderer.Render(pref.Locale, channel, &render.Data{Event: event.Type, Booking: b})
+		if err != nil {
+			return nil, fmt.Errorf("render %s %s: %w", event.Type, channel, err)
+		}
+		n := &entity.Notification{
+			EventID:       event.ID,
+			EventType:     event.Type,
+			BookingID:     event.BookingID,
+			UserID:        b.UserID,
+			Channel:       channel,
+			Recipient:     pref.Recipient(channel),
+			Locale:        msg.Locale,
+			Subject:       msg.Subject,
+			Body:          msg.Body,
+			Status:        entity.NotificationPending,
+			NextAttemptAt: now,
+		}
+		created, err := uc.notificationRepo.Create(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			queued = append(queued, n)
+		}
+	}
+
+	return queued, nil
+}
+
+// booking returns the booking an event is about. booking.created carries the
+// whole booking; status changes only carry its owner and route.
+func (uc *notificationUsecase) booking(ctx context.Context, event *entity.Event) (*booking.Booking, error) {
+	if event.Type == entity.EventBookingCreated {
+		var b booking.Booking
+		if err := json.Unmarshal(event.Data, &b); err != nil || b.UserID <= 0 {
+			return nil, apperrors.ErrInvalidEvent
+		}
+		return &b, nil
+	}
+
+	var change struct {
+		UserID    int64          `json:"user_id"`
+		RouteID   int64          `json:"route_id"`
+		NewStatus booking.Status `json:"new_status"`
+	}
+	if err := json.Unmarshal(event.Data, &change); err != nil || change.UserID <= 0 {
+		return nil, apperrors.ErrInvalidEvent
+	}
+
+	if uc.bookings != nil {
+		// The booking is only found in its own tenant
+		b, err := uc.bookings.GetBooking(reqctx.WithTenant(ctx, event.TenantID), event.BookingID)
+		if err != nil {
+			return nil, fmt.Errorf("look up booking %d: %w", event.BookingID, err)
+		}
+		return b, nil
+	}
+
+	return &booking.Booking{
+		ID:      event.BookingID,
+		UserID:  change.UserID,
+		RouteID: change.RouteID,
+		Status:  change.NewStatus,
+	}, nil
+}
+
+func (uc *notificationUsecase) GetNotification(ctx context.Context, id int64) (*entity.Notification, error) {
+	return uc.notificationRepo.GetByID(ctx, id)
+}
+
+func (uc *notificationUsecase) ListNotifications(ctx context.Context, filter entity.NotificationFilter, limit, offset int) ([]*entity.Notification, error) {
+	limit, offset = clampPage(limit, offset)
+	return uc.notificationRepo.List(ctx, filter, limit, offset)
+}
+
+func clampPage(limit, offset int) (int, int) {
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
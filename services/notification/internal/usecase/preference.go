@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	apperrors "github.com/ibnuzaman/porta-pay/pkg/errors"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/entity"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/repository"
+	"github.com/ibnuzaman/porta-pay/services/notification/internal/domain/service"
+)
+
+// e164 matches phone numbers in international format, e.g. +6281234567890.
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+type preferenceUsecase struct {
+	preferenceRepo repository.PreferenceRepository
+	locales        []string
+	defaultLocale  string
+}
+
+// NewPreferenceUsecase accepts preferences in any of locales, using
+// defaultLocale when none is given.
+func NewPreferenceUsecase(preferenceRepo repository.PreferenceRepository, locales []string, defaultLocale string) service.PreferenceService {
+	return &preferenceUsecase{
+		preferenceRepo: preferenceRepo,
+		locales:        locales,
+		defaultLocale:  defaultLocale,
+	}
+}
+
+func (uc *preferenceUsecase) GetPreference(ctx context.Context, userID int64) (*entity.Preference, error) {
+	return uc.preferenceRepo.Get(ctx, userID)
+}
+
+func (uc *preferenceUsecase) UpdatePreference(ctx context.Context, p *entity.Preference) error {
+	if err := uc.normalize(p); err != nil {
+		return err
+	}
+	p.UpdatedAt = time.Now()
+	return uc.preferenceRepo.Upsert(ctx, p)
+}
+
+func (uc *preferenceUsecase) normalize(p *entity.Preference) error {
+	if p.UserID <= 0 {
+		return apperrors.ErrInvalidPreference
+	}
+
+	p.Locale = strings.TrimSpace(p.Locale)
+	if p.Locale == "" {
+		p.Locale = uc.defaultLocale
+	}
+	base, _, _ := strings.Cut(p.Locale, "-")
+	if !contains(uc.locales, p.Locale) && !contains(uc.locales, base) {
+		return apperrors.ErrInvalidPreference
+	}
+
+	p.Email = strings.TrimSpace(p.Email)
+	if p.Email != "" {
+		addr, err := mail.ParseAddress(p.Email)
+		if err != nil || addr.Name != "" {
+			return apperrors.ErrInvalidPreference
+		}
+	}
+	p.Phone = strings.ReplaceAll(strings.TrimSpace(p.Phone), " ", "")
+	if p.Phone != "" && !e164.MatchString(p.Phone) {
+		return apperrors.ErrInvalidPreference
+	}
+	if p.EmailEnabled && p.Email == "" {
+		return apperrors.ErrInvalidPreference
+	}
+	if p.SMSEnabled && p.Phone == "" {
+		return apperrors.ErrInvalidPreference
+	}
+
+	if p.MutedEvents == nil {
+		p.MutedEvents = []string{}
+	}
+	for _, event := range p.MutedEvents {
+		if !entity.IsNotified(event) {
+			return apperrors.ErrInvalidPreference
+		}
+	}
+	return nil
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id       BIGINT PRIMARY KEY,
+    locale        TEXT        NOT NULL DEFAULT 'en',
+    email         TEXT        NOT NULL DEFAULT '',
+    phone         TEXT        NOT NULL DEFAULT '',
+    email_enabled BOOLEAN     NOT NULL DEFAULT TRUE,
+    sms_enabled   BOOLEAN     NOT NULL DEFAULT FALSE,
+    muted_events  TEXT[]      NOT NULL DEFAULT '{}',
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- One row per (booking event, channel). A redelivered event finds its row
+-- already there, so customers are never notified twice.
+CREATE TABLE IF NOT EXISTS notifications (
+    id              BIGSERIAL PRIMARY KEY,
+    event_id        BIGINT      NOT NULL,
+    event_type      TEXT        NOT NULL,
+    booking_id      BIGINT      NOT NULL,
+    user_id         BIGINT      NOT NULL,
+    channel         TEXT        NOT NULL CHECK (channel IN ('email', 'sms')),
+    recipient       TEXT        NOT NULL,
+    locale          TEXT        NOT NULL,
+    subject         TEXT        NOT NULL DEFAULT '',
+    body            TEXT        NOT NULL,
+    status          TEXT        NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'SENT', 'FAILED')),
+    attempts        INT         NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_error      TEXT        NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    sent_at         TIMESTAMPTZ,
+    UNIQUE (event_id, channel)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_due ON notifications(next_attempt_at) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications(user_id, id);
+CREATE INDEX IF NOT EXISTS idx_notifications_booking_id ON notifications(booking_id);